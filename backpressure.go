@@ -0,0 +1,112 @@
+package sentry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/telemetry"
+)
+
+const (
+	// backpressureCheckInterval is how often the health of the telemetry
+	// buffers is checked, at most.
+	backpressureCheckInterval = 10 * time.Second
+	// backpressureUtilization is the buffer utilization from which a buffer
+	// is considered unhealthy.
+	backpressureUtilization = 0.8
+	// maxDownsampleFactor caps how often the sample rate is halved.
+	maxDownsampleFactor = 10
+)
+
+// backpressureBuffers maps the categories that are downsampled under
+// backpressure to the buffers whose health they depend on. Transactions also
+// depend on the buffer of streamed spans, which they produce.
+var backpressureBuffers = map[ratelimit.Category][]ratelimit.Category{
+	ratelimit.CategoryTransaction: {ratelimit.CategoryTransaction, ratelimit.CategorySpan},
+	ratelimit.CategoryLog:         {ratelimit.CategoryLog},
+}
+
+// backpressureMonitor lowers the sample rate of transactions and logs while
+// their telemetry buffers fill up faster than they are sent. Each check that
+// finds a buffer unhealthy halves the sample rate of its category again, and
+// the first check that finds it healthy restores the configured rate.
+//
+// A buffer is unhealthy if its utilization reaches backpressureUtilization,
+// or if it dropped items since the previous check. Checks happen lazily, when
+// a sampling decision is made, at most every interval.
+type backpressureMonitor struct {
+	bufferMetrics func() map[ratelimit.Category]telemetry.BufferMetrics
+	interval      time.Duration
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	dropped   map[ratelimit.Category]int64
+	factors   map[ratelimit.Category]int
+}
+
+func newBackpressureMonitor(bufferMetrics func() map[ratelimit.Category]telemetry.BufferMetrics) *backpressureMonitor {
+	return &backpressureMonitor{
+		bufferMetrics: bufferMetrics,
+		interval:      backpressureCheckInterval,
+		dropped:       make(map[ratelimit.Category]int64),
+		factors:       make(map[ratelimit.Category]int),
+	}
+}
+
+// downsampleFactor returns how often the sample rate of category is halved.
+// It is safe to call on a nil monitor, which never downsamples.
+func (m *backpressureMonitor) downsampleFactor(category ratelimit.Category) int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now := time.Now(); now.Sub(m.lastCheck) >= m.interval {
+		m.lastCheck = now
+		m.check()
+	}
+	return m.factors[category]
+}
+
+// sampleRate returns rate lowered by the downsample factor of category.
+func (m *backpressureMonitor) sampleRate(category ratelimit.Category, rate float64) float64 {
+	factor := m.downsampleFactor(category)
+	if factor == 0 {
+		return rate
+	}
+	return rate / float64(int(1)<<factor)
+}
+
+// check updates the downsample factors from the health of the buffers.
+func (m *backpressureMonitor) check() {
+	metrics := m.bufferMetrics()
+
+	unhealthy := make(map[ratelimit.Category]bool, len(metrics))
+	for category, metric := range metrics {
+		dropped := metric.DroppedCount - m.dropped[category]
+		m.dropped[category] = metric.DroppedCount
+		unhealthy[category] = metric.Utilization >= backpressureUtilization || dropped > 0
+	}
+
+	for category, buffers := range backpressureBuffers {
+		healthy := true
+		for _, buffer := range buffers {
+			if unhealthy[buffer] {
+				healthy = false
+			}
+		}
+
+		factor := m.factors[category]
+		switch {
+		case !healthy && factor < maxDownsampleFactor:
+			factor++
+			debuglog.Printf("Telemetry buffers under backpressure: downsampling %s to 1/%d of the sample rate", category, 1<<factor)
+		case healthy && factor > 0:
+			factor = 0
+			debuglog.Printf("Telemetry buffers recovered: sampling %s at the configured rate", category)
+		}
+		m.factors[category] = factor
+	}
+}
@@ -0,0 +1,134 @@
+package sentry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/telemetry"
+	"github.com/getsentry/sentry-go/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBufferMetrics struct {
+	metrics map[ratelimit.Category]telemetry.BufferMetrics
+}
+
+func (f *fakeBufferMetrics) get() map[ratelimit.Category]telemetry.BufferMetrics {
+	return f.metrics
+}
+
+func (f *fakeBufferMetrics) set(category ratelimit.Category, utilization float64, dropped int64) {
+	f.metrics[category] = telemetry.BufferMetrics{Category: category, Utilization: utilization, DroppedCount: dropped}
+}
+
+func newTestBackpressureMonitor() (*backpressureMonitor, *fakeBufferMetrics) {
+	metrics := &fakeBufferMetrics{metrics: make(map[ratelimit.Category]telemetry.BufferMetrics)}
+	monitor := newBackpressureMonitor(metrics.get)
+	monitor.interval = 0
+	return monitor, metrics
+}
+
+func TestBackpressureMonitor(t *testing.T) {
+	monitor, metrics := newTestBackpressureMonitor()
+	metrics.set(ratelimit.CategoryTransaction, 0.1, 0)
+	metrics.set(ratelimit.CategoryLog, 0.1, 0)
+	assert.Equal(t, 0.5, monitor.sampleRate(ratelimit.CategoryTransaction, 0.5))
+
+	// Every unhealthy check halves the sample rate again.
+	metrics.set(ratelimit.CategoryTransaction, 0.9, 0)
+	assert.Equal(t, 0.25, monitor.sampleRate(ratelimit.CategoryTransaction, 0.5))
+	assert.Equal(t, 0.125, monitor.sampleRate(ratelimit.CategoryTransaction, 0.5))
+	assert.Equal(t, 0, monitor.downsampleFactor(ratelimit.CategoryLog))
+
+	// The factor is capped.
+	for i := 0; i < 2*maxDownsampleFactor; i++ {
+		monitor.downsampleFactor(ratelimit.CategoryTransaction)
+	}
+	assert.Equal(t, maxDownsampleFactor, monitor.downsampleFactor(ratelimit.CategoryTransaction))
+
+	// A healthy check restores the configured rate.
+	metrics.set(ratelimit.CategoryTransaction, 0.1, 0)
+	assert.Equal(t, 0.5, monitor.sampleRate(ratelimit.CategoryTransaction, 0.5))
+
+	// Drops since the previous check make a buffer unhealthy, whatever its
+	// utilization.
+	metrics.set(ratelimit.CategoryLog, 0.1, 5)
+	assert.Equal(t, 1, monitor.downsampleFactor(ratelimit.CategoryLog))
+	assert.Equal(t, 0, monitor.downsampleFactor(ratelimit.CategoryLog))
+
+	// Streamed spans put transactions under backpressure.
+	metrics.set(ratelimit.CategorySpan, 1.0, 0)
+	assert.Equal(t, 1, monitor.downsampleFactor(ratelimit.CategoryTransaction))
+}
+
+func TestBackpressureMonitor_Nil(t *testing.T) {
+	var monitor *backpressureMonitor
+	assert.Equal(t, 0, monitor.downsampleFactor(ratelimit.CategoryTransaction))
+	assert.Equal(t, 0.3, monitor.sampleRate(ratelimit.CategoryTransaction, 0.3))
+}
+
+func TestBackpressure_Sampling(t *testing.T) {
+	transport := &recordingEnvelopeTransport{}
+	client, err := NewClient(ClientOptions{
+		Dsn:               "https://public@example.com/1",
+		EnvelopeTransport: transport,
+		EnableTracing:     true,
+		TracesSampleRate:  1.0,
+		Integrations: func([]Integration) []Integration {
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	require.NotNil(t, client.backpressure)
+
+	monitor, metrics := newTestBackpressureMonitor()
+	client.backpressure = monitor
+	metrics.set(ratelimit.CategoryTransaction, 1.0, 0)
+	metrics.set(ratelimit.CategoryLog, 1.0, 0)
+	for i := 0; i < maxDownsampleFactor-1; i++ {
+		monitor.downsampleFactor(ratelimit.CategoryTransaction)
+		monitor.downsampleFactor(ratelimit.CategoryLog)
+	}
+
+	ctx := SetHubOnContext(context.Background(), NewHub(client, NewScope()))
+	var sampled, downsampled int
+	for i := 0; i < 100; i++ {
+		transaction := StartTransaction(ctx, "job")
+		if transaction.Sampled.Bool() {
+			sampled++
+			assert.Equal(t, 1.0/(1<<maxDownsampleFactor), transaction.sampleRate)
+			assert.Equal(t, "0.0009765625", DynamicSamplingContextFromTransaction(transaction).Entries["sample_rate"])
+		}
+		if transaction.downsampled {
+			downsampled++
+		}
+		transaction.Finish()
+		NewLogger(ctx).Info().Emit("log")
+	}
+	assert.Equal(t, 100, sampled+downsampled)
+	assert.Less(t, sampled, 10)
+
+	discarded := client.Stats().Discarded
+	assert.Contains(t, discarded, report.DiscardedEvent{Reason: report.ReasonBackpressure, Category: ratelimit.CategoryTransaction, Quantity: int64(downsampled)})
+	var logs int64
+	for _, discard := range discarded {
+		if discard.Reason == report.ReasonBackpressure && discard.Category == ratelimit.CategoryLog {
+			logs = discard.Quantity
+		}
+	}
+	assert.Greater(t, logs, int64(90))
+}
+
+func TestBackpressure_Disabled(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		Dsn:                         "https://public@example.com/1",
+		EnvelopeTransport:           &recordingEnvelopeTransport{},
+		DisableBackpressureHandling: true,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	assert.Nil(t, client.backpressure)
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/getsentry/sentry-go/internal/debug"
 	"github.com/getsentry/sentry-go/internal/debuglog"
 	httpInternal "github.com/getsentry/sentry-go/internal/http"
+	"github.com/getsentry/sentry-go/internal/offline"
 	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/spotlight"
 	"github.com/getsentry/sentry-go/internal/telemetry"
 	"github.com/getsentry/sentry-go/report"
 )
@@ -133,6 +135,18 @@ type ClientOptions struct {
 	// The DSN to use. If the DSN is not set, the client is effectively
 	// disabled.
 	Dsn string
+	// SecondaryDsns are additional DSNs that receive a copy of everything sent
+	// to Dsn, for example to mirror telemetry to a second project during a
+	// migration. Every secondary DSN has its own rate limits, client reports
+	// and offline cache, so a rate limit or outage of one project never
+	// affects delivery to another. Ignored when a custom Transport is set.
+	SecondaryDsns []string
+	// EventRoutes send events to other DSNs than Dsn, based on the package
+	// they originate from or on a tag. The first matching route wins, events
+	// that match no route are sent to Dsn. Routed DSNs are delivered to
+	// independently, like SecondaryDsns. Logs and metrics are not routed.
+	// Ignored when a custom Transport is set.
+	EventRoutes []EventRoute
 	// In debug mode, the debug information is printed to stdout to help you
 	// understand what sentry is doing.
 	Debug bool
@@ -152,8 +166,9 @@ type ClientOptions struct {
 	TracesSampler TracesSampler
 	// Control with URLs trace propagation should be enabled. Does not support regex patterns.
 	TracePropagationTargets []string
-	// PropagateTraceparent is used to control whether the W3C Trace Context HTTP traceparent header
-	// is propagated on outgoing http requests.
+	// PropagateTraceparent is used to control whether the W3C Trace Context traceparent header
+	// is propagated on outgoing HTTP requests and gRPC calls, along with the sentry-trace header.
+	// The tracestate header received with a continued trace is propagated with it.
 	PropagateTraceparent bool
 	// StrictTraceContinuation is used to control trace continuation from 3rd party services that happen to be
 	// instrumented by Sentry.
@@ -196,6 +211,10 @@ type ClientOptions struct {
 	DebugWriter io.Writer
 	// The transport to use. Defaults to HTTPTransport.
 	Transport Transport
+	// EnvelopeTransport delivers envelopes instead of the default transport,
+	// receiving all telemetry exactly as it would be sent to Sentry. It takes
+	// precedence over Transport, SecondaryDsns and EventRoutes.
+	EnvelopeTransport EnvelopeTransport
 	// The server name to be reported.
 	ServerName string
 	// The release to be sent with events.
@@ -234,6 +253,36 @@ type ClientOptions struct {
 	// See https://develop.sentry.dev/sdk/envelopes/#size-limits for size limits
 	// applied during event ingestion. Events that exceed these limits might get dropped.
 	MaxSpans int
+	// StreamSpans sends every child span as soon as it finishes, in batches of
+	// standalone span envelope items, instead of embedding the children in the
+	// transaction of their root span. Finished spans are no longer held in
+	// memory until the transaction finishes, and they are not lost if the
+	// process crashes before. MaxSpans does not apply to streamed spans.
+	//
+	// Streaming requires the telemetry buffer. It is ignored if
+	// DisableTelemetryBuffer is set or a custom Transport is used.
+	StreamSpans bool
+	// ContinuousProfiling profiles the program for the lifetime of the
+	// client. The CPU usage is sampled with runtime/pprof and sent to Sentry
+	// in profile chunks. The samples of the goroutines of a sampled
+	// transaction, and of the goroutines they start, are linked to the
+	// transaction with pprof labels.
+	//
+	// Only one CPU profile can run in a program at a time. Chunks are skipped
+	// while the program is profiled otherwise, for example with the
+	// net/http/pprof handlers.
+	//
+	// Profiling requires the telemetry buffer. It is ignored if
+	// DisableTelemetryBuffer is set or a custom Transport is used.
+	ContinuousProfiling bool
+	// ProfileChunkDuration is the duration of the profile chunks sent with
+	// ContinuousProfiling. Defaults to 60 seconds.
+	ProfileChunkDuration time.Duration
+	// ProfilesSampleRate is the sample rate of transaction profiles, between
+	// 0.0 and 1.0, relative to the sampled transactions. The goroutine stacks
+	// of a profiled transaction, and of the goroutines it starts, are sampled
+	// for as long as it runs, up to 30 seconds, and sent with the transaction.
+	ProfilesSampleRate float64
 	// An optional pointer to http.Client that will be used with a default
 	// HTTPTransport. Using your own client will make HTTPTransport, HTTPProxy,
 	// HTTPSProxy and CaCerts options ignored.
@@ -251,6 +300,31 @@ type ClientOptions struct {
 	HTTPSProxy string
 	// An optional set of SSL certificates to use.
 	CaCerts *x509.CertPool
+	// OfflineCache configures an on-disk store for envelopes that could not be
+	// delivered, which are replayed once Sentry is reachable again. It is
+	// disabled unless OfflineCache.Dir is set. Not supported by
+	// HTTPSyncTransport.
+	OfflineCache OfflineCacheOptions
+	// Compression configures compression of request bodies sent to Sentry.
+	// Bodies are sent uncompressed by default.
+	Compression CompressionOptions
+	// Retry configures retries of envelopes that failed with a transient
+	// error. Retries are disabled by default. Not supported by
+	// HTTPSyncTransport.
+	Retry RetryOptions
+	// Spotlight mirrors every envelope the SDK emits to a local Spotlight
+	// sidecar (https://spotlightjs.com) during development. Envelopes are
+	// mirrored in addition to being sent to the DSN, or on their own if no DSN
+	// is set. Mirroring never blocks or affects delivery to Sentry.
+	Spotlight bool
+	// SpotlightURL is the endpoint of the Spotlight sidecar. Defaults to
+	// http://localhost:8969/stream.
+	SpotlightURL string
+	// SpotlightIgnoreSampling also mirrors errors dropped by SampleRate and
+	// transactions not sampled by TracesSampleRate or TracesSampler to
+	// Spotlight. Event processors and BeforeSend callbacks are not run for
+	// them, since they are not sent to Sentry.
+	SpotlightIgnoreSampling bool
 	// MaxErrorDepth is the maximum number of errors reported in a chain of errors.
 	// This protects the SDK from an arbitrarily long chain of wrapped errors.
 	//
@@ -268,6 +342,12 @@ type ClientOptions struct {
 	DisableMetrics bool
 	// DisableClientReports controls when client reports should be emitted.
 	DisableClientReports bool
+	// OnDiscard is called every time the SDK discards telemetry, with the
+	// reason, the category and the number of discarded items, or bytes for
+	// attachments. It is called synchronously, so it must return quickly and
+	// must not use the Client. It is called even if DisableClientReports is
+	// set. See also Client.Stats.
+	OnDiscard func(report.DiscardedEvent)
 	// TraceIgnoreStatusCodes is a list of HTTP status codes that should not be traced.
 	// Each element can be either:
 	// - A single-element slice [code] for a specific status code
@@ -288,6 +368,17 @@ type ClientOptions struct {
 	TraceIgnoreStatusCodes [][]int
 	// DisableTelemetryBuffer disables the telemetry buffer layer for prioritizing events and uses the old transport layer.
 	DisableTelemetryBuffer bool
+	// DisableBackpressureHandling disables lowering the sample rate of
+	// transactions and logs while the telemetry buffers fill up faster than
+	// they are sent. Under backpressure, the sample rate is halved every 10
+	// seconds, down to 1/1024 of the configured rate, and restored once the
+	// buffers are healthy again. The lowered rate is sent in the dynamic
+	// sampling context, and dropped items are reported with the
+	// "backpressure" reason. Only applies to the telemetry buffer.
+	DisableBackpressureHandling bool
+	// TelemetryBuffer configures the capacity, batching, overflow policy and
+	// scheduling of the telemetry buffer of every category.
+	TelemetryBuffer TelemetryBufferOptions
 }
 
 // Client is the underlying processor that is used by the main API and Hub
@@ -307,8 +398,19 @@ type Client struct {
 	batchLogger        *logBatchProcessor
 	batchMeter         *metricBatchProcessor
 	telemetryProcessor *telemetry.Processor
+	spotlight          *spotlight.Sink
 	reportRecorder     report.ClientReportRecorder
 	reportProvider     report.ClientReportProvider
+	// router is nil unless ClientOptions.EventRoutes has valid routes.
+	router *eventRouter
+	// discards is the reportRecorder, which also counts discards for Stats.
+	discards *report.Aggregator
+	// backpressure is nil unless the telemetry buffer is used and
+	// ClientOptions.DisableBackpressureHandling is not set.
+	backpressure *backpressureMonitor
+	// profiler is nil unless ClientOptions.ContinuousProfiling is set and the
+	// telemetry buffer is used.
+	profiler *profiler
 }
 
 // NewClient creates and returns an instance of Client configured using
@@ -410,19 +512,31 @@ func NewClient(options ClientOptions) (*Client, error) {
 		dsn:            dsn,
 		sdkIdentifier:  sdkIdentifier,
 		sdkVersion:     SDKVersion,
-		reportRecorder: report.NoopRecorder(),
 		reportProvider: report.NoopProvider(),
+		discards:       report.NewAggregator(),
 	}
 
+	// Discards are always recorded for Stats, but only reported to Sentry if
+	// client reports are enabled.
+	client.discards.SetDiscardCallback(options.OnDiscard)
+	client.reportRecorder = client.discards
 	if !options.DisableClientReports {
-		a := report.NewAggregator()
-		client.reportRecorder = a
-		client.reportProvider = a
+		client.reportProvider = client.discards
+	}
+
+	if options.Spotlight {
+		client.spotlight = spotlight.NewSink(options.SpotlightURL)
+	}
+
+	client.router = newEventRouter(options.EventRoutes)
+
+	if options.EnvelopeTransport != nil && options.Transport != nil {
+		debuglog.Println("Ignoring Transport in favor of EnvelopeTransport")
 	}
 
 	// We currently disallow using custom Transport with the new Telemetry Processor, due to the difference in transport signatures.
-	// The option should be enabled when the new Transport interface signature changes.
-	if !options.DisableTelemetryBuffer && client.options.Transport == nil {
+	// A custom EnvelopeTransport shares the signature of the processor's transport.
+	if !options.DisableTelemetryBuffer && (client.options.Transport == nil || client.options.EnvelopeTransport != nil) {
 		client.setupTelemetryProcessor()
 	} else {
 		if client.options.Transport != nil {
@@ -439,6 +553,14 @@ func NewClient(options ClientOptions) (*Client, error) {
 			client.batchMeter.Start()
 		}
 	}
+	if options.ContinuousProfiling {
+		if client.telemetryProcessor != nil {
+			client.profiler = newProfiler(&client)
+			client.profiler.Start()
+		} else {
+			debuglog.Println("Continuous profiling requires the telemetry buffer: profiling disabled")
+		}
+	}
 	client.setupIntegrations()
 	if options.OrgID != 0 && client.dsn != nil {
 		client.dsn.SetOrgID(options.OrgID)
@@ -451,6 +573,16 @@ func (client *Client) setupTransport() {
 	opts := client.options
 	transport := opts.Transport
 
+	if opts.EnvelopeTransport != nil {
+		client.Transport = &internalAsyncTransportAdapter{
+			transport: client.setupEnvelopeTransport(),
+			dsn:       client.dsn,
+			recorder:  client.reportRecorder,
+			provider:  client.reportProvider,
+		}
+		return
+	}
+
 	if transport == nil {
 		if opts.Dsn == "" {
 			transport = new(noopTransport)
@@ -458,7 +590,7 @@ func (client *Client) setupTransport() {
 			httpTransport := NewHTTPTransport()
 			httpTransport.recorder = client.reportRecorder
 			httpTransport.provider = client.reportProvider
-			transport = httpTransport
+			transport = newFanoutTransport(newRoutingTransport(httpTransport, client.router, opts), opts)
 		}
 	} else {
 		// For known transport types, inject the client report interfaces.
@@ -472,6 +604,9 @@ func (client *Client) setupTransport() {
 		case *internalAsyncTransportAdapter:
 			tr.recorder = client.reportRecorder
 			tr.provider = client.reportProvider
+		case *FileTransport:
+			tr.recorder = client.reportRecorder
+			tr.provider = client.reportProvider
 		}
 	}
 
@@ -492,7 +627,7 @@ func (client *Client) sdkInfo() *protocol.SdkInfo {
 }
 
 func (client *Client) setupTelemetryProcessor() {
-	transport := httpInternal.NewAsyncTransport(httpInternal.TransportOptions{
+	transportOptions := httpInternal.TransportOptions{
 		Dsn:           client.options.Dsn,
 		HTTPClient:    client.options.HTTPClient,
 		HTTPTransport: client.options.HTTPTransport,
@@ -502,18 +637,70 @@ func (client *Client) setupTelemetryProcessor() {
 		Recorder:      client.reportRecorder,
 		Provider:      client.reportProvider,
 		SdkInfo:       client.sdkInfo,
-	})
+		OfflineCache:  client.options.OfflineCache.internal(),
+		Compression:   client.options.Compression.internal(),
+		Retry:         client.options.Retry.internal(),
+	}
+	var transport protocol.TelemetryTransport
+	if client.options.EnvelopeTransport != nil {
+		transport = client.setupEnvelopeTransport()
+	} else {
+		transport = httpInternal.NewFanoutTransport(
+			httpInternal.NewRoutingTransport(
+				httpInternal.NewAsyncTransport(transportOptions),
+				client.destinations(transportOptions, client.router.rawDsns()),
+			),
+			client.destinations(transportOptions, client.options.SecondaryDsns),
+		)
+	}
 	client.Transport = &internalAsyncTransportAdapter{transport: transport}
 
-	buffers := map[ratelimit.Category]telemetry.Buffer[protocol.TelemetryItem]{
-		ratelimit.CategoryError:       telemetry.NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryError, 100, telemetry.OverflowPolicyDropOldest, 1, 0, client.reportRecorder),
-		ratelimit.CategoryTransaction: telemetry.NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryTransaction, 1000, telemetry.OverflowPolicyDropOldest, 1, 0, client.reportRecorder),
-		ratelimit.CategoryLog:         telemetry.NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryLog, 10*100, telemetry.OverflowPolicyDropOldest, 100, 5*time.Second, client.reportRecorder),
-		ratelimit.CategoryMonitor:     telemetry.NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryMonitor, 100, telemetry.OverflowPolicyDropOldest, 1, 0, client.reportRecorder),
-		ratelimit.CategoryTraceMetric: telemetry.NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryTraceMetric, 10*100, telemetry.OverflowPolicyDropOldest, 100, 5*time.Second, client.reportRecorder),
+	buffers := client.options.TelemetryBuffer.buffers(client.reportRecorder)
+
+	var processorTransport protocol.TelemetryTransport = transport
+	if client.spotlight != nil {
+		processorTransport = spotlight.Tee(transport, client.spotlight)
+	}
+
+	client.telemetryProcessor = telemetry.NewProcessor(buffers, processorTransport, client.dsn, client.sdkInfo, client.reportRecorder, client.options.TelemetryBuffer.PriorityWeights.internal())
+	if !client.options.DisableBackpressureHandling {
+		client.backpressure = newBackpressureMonitor(client.telemetryProcessor.BufferMetrics)
+	}
+}
+
+// setupEnvelopeTransport configures ClientOptions.EnvelopeTransport and wraps
+// it to attach client reports to the envelopes it receives.
+func (client *Client) setupEnvelopeTransport() protocol.TelemetryTransport {
+	if tr, ok := client.options.EnvelopeTransport.(*FileTransport); ok {
+		tr.recorder = client.reportRecorder
 	}
+	client.options.EnvelopeTransport.Configure(client.options)
+	return &envelopeTransportAdapter{
+		transport: client.options.EnvelopeTransport,
+		provider:  client.reportProvider,
+	}
+}
 
-	client.telemetryProcessor = telemetry.NewProcessor(buffers, transport, client.dsn, client.sdkInfo, client.reportRecorder)
+// destinations returns an AsyncTransport for every valid DSN in rawDsns,
+// each with its own client reports and offline cache directory.
+func (client *Client) destinations(options httpInternal.TransportOptions, rawDsns []string) []httpInternal.Destination {
+	var destinations []httpInternal.Destination
+	for _, rawDsn := range rawDsns {
+		dsn, err := protocol.NewDsn(rawDsn)
+		if err != nil {
+			debuglog.Printf("Ignoring invalid DSN: %v", err)
+			continue
+		}
+		destinationOptions := options
+		destinationOptions.Dsn = rawDsn
+		destinationOptions.Recorder, destinationOptions.Provider = newDestinationReporter(client.options)
+		destinationOptions.OfflineCache.Dir = offline.DirForDsn(options.OfflineCache.Dir, dsn)
+		destinations = append(destinations, httpInternal.Destination{
+			Dsn:       dsn,
+			Transport: httpInternal.NewAsyncTransport(destinationOptions),
+		})
+	}
+	return destinations
 }
 
 func (client *Client) setupIntegrations() {
@@ -635,6 +822,13 @@ func (client *Client) captureLog(log *Log, _ *Scope) bool {
 		}
 	}
 
+	if factor := client.backpressure.downsampleFactor(ratelimit.CategoryLog); factor > 0 && !sample(1/float64(int(1)<<factor)) {
+		debuglog.Println("Log dropped due to backpressure.")
+		client.reportRecorder.RecordOne(report.ReasonBackpressure, ratelimit.CategoryLog)
+		client.reportRecorder.Record(report.ReasonBackpressure, ratelimit.CategoryLogByte, int64(log.ApproximateSize()))
+		return false
+	}
+
 	if client.telemetryProcessor != nil {
 		if !client.telemetryProcessor.Add(log) {
 			debuglog.Print("Dropping log: telemetry buffer full or category missing")
@@ -747,7 +941,7 @@ func (client *Client) RecoverWithContext(
 // the network synchronously, configure it to use the HTTPSyncTransport in the
 // call to Init.
 func (client *Client) Flush(timeout time.Duration) bool {
-	if client.batchLogger != nil || client.batchMeter != nil || client.telemetryProcessor != nil {
+	if client.batchLogger != nil || client.batchMeter != nil || client.telemetryProcessor != nil || client.spotlight != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 		return client.FlushWithContext(ctx)
@@ -774,10 +968,15 @@ func (client *Client) FlushWithContext(ctx context.Context) bool {
 	if client.batchMeter != nil {
 		client.batchMeter.Flush(ctx.Done())
 	}
+	var flushed bool
 	if client.telemetryProcessor != nil {
-		return client.telemetryProcessor.FlushWithContext(ctx)
+		flushed = client.telemetryProcessor.FlushWithContext(ctx)
+	} else {
+		flushed = client.Transport.FlushWithContext(ctx)
 	}
-	return client.Transport.FlushWithContext(ctx)
+	// Spotlight is best effort and does not affect the result.
+	client.spotlight.FlushWithContext(ctx)
+	return flushed
 }
 
 // Close clean up underlying Transport resources.
@@ -785,6 +984,9 @@ func (client *Client) FlushWithContext(ctx context.Context) bool {
 // Close should be called after Flush and before terminating the program
 // otherwise some events may be lost.
 func (client *Client) Close() {
+	if client.profiler != nil {
+		client.profiler.Stop()
+	}
 	if client.telemetryProcessor != nil {
 		client.telemetryProcessor.Close(5 * time.Second)
 	}
@@ -795,6 +997,7 @@ func (client *Client) Close() {
 		client.batchMeter.Shutdown()
 	}
 	client.Transport.Close()
+	client.spotlight.Close()
 }
 
 // EventFromMessage creates an event from the given message string.
@@ -886,6 +1089,9 @@ func (client *Client) processEvent(event *Event, hint *EventHint, scope EventMod
 	if event.Type != transactionType && event.Type != checkInType && !sample(client.options.SampleRate) {
 		debuglog.Println("Event dropped due to SampleRate hit.")
 		client.reportRecorder.RecordOne(report.ReasonSampleRate, event.toCategory())
+		if client.spotlightIgnoresSampling() {
+			client.mirrorSampledOut(event, hint, scope)
+		}
 		return nil
 	}
 
@@ -924,61 +1130,78 @@ func (client *Client) processEvent(event *Event, hint *EventHint, scope EventMod
 		}
 	}
 
+	client.routeEvent(event)
+
 	if client.telemetryProcessor != nil {
 		if !client.telemetryProcessor.Add(event) {
 			debuglog.Println("Event dropped: telemetry buffer full or unavailable")
 		}
 	} else {
-		client.Transport.SendEvent(event)
+		client.sendEvent(event)
 	}
 
 	return &event.EventID
 }
 
-func (client *Client) prepareEvent(event *Event, hint *EventHint, scope EventModifier) *Event {
-	if event.EventID == "" {
-		// TODO set EventID when the event is created, same as in other SDKs. It's necessary for profileTransaction.ID.
-		event.EventID = EventID(uuid())
-	}
-
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
+// routeEvent addresses the event to the DSN of the first matching EventRoute,
+// if any.
+func (client *Client) routeEvent(event *Event) {
+	dsn := client.router.route(event)
+	if dsn == nil {
+		return
 	}
+	event.sdkMetaData.dsn = dsn
+	event.sdkMetaData.dsc.Entries = protocol.ReaddressTrace(event.sdkMetaData.dsc.Entries, client.dsn, dsn)
+}
 
-	if event.Level == "" {
-		event.Level = LevelInfo
-	}
-
-	if event.ServerName == "" {
-		event.ServerName = client.options.ServerName
-
-		if event.ServerName == "" {
-			event.ServerName = hostname
-		}
-	}
+// sendEvent passes an event to the Transport and mirrors it to Spotlight.
+func (client *Client) sendEvent(event *Event) {
+	client.mirrorToSpotlight(event)
+	client.Transport.SendEvent(event)
+}
 
-	if event.Release == "" {
-		event.Release = client.options.Release
-	}
+// mirrorToSpotlight sends a copy of the event to the Spotlight sidecar if it
+// is enabled.
+func (client *Client) mirrorToSpotlight(event *Event) {
+	if client.spotlight == nil {
+		return
+	}
+	header := &protocol.EnvelopeHeader{
+		EventID: string(event.EventID),
+		SentAt:  time.Now(),
+		Dsn:     client.dsn,
+		Trace:   event.GetDynamicSamplingContext(),
+		Sdk:     &protocol.SdkInfo{Name: event.Sdk.Name, Version: event.Sdk.Version},
+	}
+	envelope, err := event.ToEnvelope(header)
+	if err != nil {
+		debuglog.Printf("Failed to convert event to envelope for Spotlight: %v", err)
+		return
+	}
+	client.spotlight.SendEnvelope(envelope)
+}
 
-	if event.Dist == "" {
-		event.Dist = client.options.Dist
-	}
+// spotlightIgnoresSampling reports whether events dropped by sampling are
+// mirrored to Spotlight.
+func (client *Client) spotlightIgnoresSampling() bool {
+	return client.spotlight != nil && client.options.SpotlightIgnoreSampling
+}
 
-	if event.Environment == "" {
-		event.Environment = client.options.Environment
-	}
+// mirrorSampledOut mirrors an event dropped by sampling to Spotlight. The data
+// of the scope is applied, but no event processors or BeforeSend callbacks are
+// run, since the event is never sent to Sentry.
+func (client *Client) mirrorSampledOut(event *Event, hint *EventHint, scope EventModifier) {
+	client.setEventDefaults(event)
+	if scope, ok := scope.(*Scope); ok {
+		scope.mu.RLock()
+		scope.applyDataToEvent(event, hint, client)
+		scope.mu.RUnlock()
+	}
+	client.mirrorToSpotlight(event)
+}
 
-	event.Platform = "go"
-	event.Sdk = SdkInfo{
-		Name:         client.GetSDKIdentifier(),
-		Version:      SDKVersion,
-		Integrations: client.listIntegrations(),
-		Packages: []SdkPackage{{
-			Name:    "sentry-go",
-			Version: SDKVersion,
-		}},
-	}
+func (client *Client) prepareEvent(event *Event, hint *EventHint, scope EventModifier) *Event {
+	client.setEventDefaults(event)
 
 	if scope != nil {
 		event = scope.ApplyToEvent(event, hint, client)
@@ -1032,6 +1255,52 @@ func (client *Client) prepareEvent(event *Event, hint *EventHint, scope EventMod
 	return event
 }
 
+// setEventDefaults fills in the fields of the event that are set by the SDK.
+func (client *Client) setEventDefaults(event *Event) {
+	if event.EventID == "" {
+		event.EventID = EventID(uuid())
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	if event.Level == "" {
+		event.Level = LevelInfo
+	}
+
+	if event.ServerName == "" {
+		event.ServerName = client.options.ServerName
+
+		if event.ServerName == "" {
+			event.ServerName = hostname
+		}
+	}
+
+	if event.Release == "" {
+		event.Release = client.options.Release
+	}
+
+	if event.Dist == "" {
+		event.Dist = client.options.Dist
+	}
+
+	if event.Environment == "" {
+		event.Environment = client.options.Environment
+	}
+
+	event.Platform = "go"
+	event.Sdk = SdkInfo{
+		Name:         client.GetSDKIdentifier(),
+		Version:      SDKVersion,
+		Integrations: client.listIntegrations(),
+		Packages: []SdkPackage{{
+			Name:    "sentry-go",
+			Version: SDKVersion,
+		}},
+	}
+}
+
 func (client *Client) listIntegrations() []string {
 	integrations := make([]string, len(client.integrations))
 	for i, integration := range client.integrations {
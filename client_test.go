@@ -18,7 +18,9 @@ import (
 
 	"github.com/getsentry/sentry-go/internal/debuglog"
 	internalHttp "github.com/getsentry/sentry-go/internal/http"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
 	"github.com/getsentry/sentry-go/internal/testutils"
+	"github.com/getsentry/sentry-go/report"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	pkgErrors "github.com/pkg/errors"
@@ -1148,6 +1150,107 @@ func TestClient_SetupTelemetryBuffer_NoDSN(t *testing.T) {
 	require.IsType(t, &internalHttp.NoopTransport{}, client.Transport.(*internalAsyncTransportAdapter).transport)
 }
 
+func TestClient_Spotlight(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		sentrySrv := newEnvelopeServer(t, "pubkey", "1", nil)
+		spotlightSrv := newEnvelopeServer(t, "pubkey", "1", nil)
+
+		client, err := NewClient(ClientOptions{
+			Dsn:                    sentrySrv.dsn,
+			Spotlight:              true,
+			SpotlightURL:           spotlightSrv.url + "/stream",
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+
+		client.CaptureMessage("mirrored", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		assert.Equal(t, []string{"mirrored"}, sentrySrv.messages())
+		assert.Equal(t, []string{"mirrored"}, spotlightSrv.messages())
+	})
+
+	t.Run("without DSN and ignoring sampling", func(t *testing.T) {
+		spotlightSrv := newEnvelopeServer(t, "pubkey", "1", nil)
+
+		var callbacks int
+		client, err := NewClient(ClientOptions{
+			SampleRate:              0.000000001,
+			EnableTracing:           true,
+			TracesSampleRate:        0,
+			Spotlight:               true,
+			SpotlightURL:            spotlightSrv.url,
+			SpotlightIgnoreSampling: true,
+			BeforeSend: func(event *Event, _ *EventHint) *Event {
+				callbacks++
+				return event
+			},
+			BeforeSendTransaction: func(event *Event, _ *EventHint) *Event {
+				callbacks++
+				return event
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+		client.AddEventProcessor(func(event *Event, _ *EventHint) *Event {
+			callbacks++
+			return event
+		})
+
+		scope := NewScope()
+		scope.SetTag("tag", "value")
+		scope.AddEventProcessor(func(_ *Event, _ *EventHint) *Event {
+			callbacks++
+			return nil
+		})
+		client.CaptureMessage("sampled out", nil, scope)
+
+		hub := NewHub(client, scope)
+		transaction := StartTransaction(SetHubOnContext(context.Background(), hub), "sampled out transaction")
+		require.Equal(t, SampledFalse, transaction.Sampled)
+		transaction.Finish()
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		assert.ElementsMatch(t, []string{"sampled out", "sampled out transaction"}, spotlightSrv.messages())
+		assert.Zero(t, callbacks, "user callbacks ran for sampled out events")
+	})
+
+	t.Run("respecting sampling", func(t *testing.T) {
+		spotlightSrv := newEnvelopeServer(t, "pubkey", "1", nil)
+
+		client, err := NewClient(ClientOptions{
+			SampleRate:   0.000000001,
+			Spotlight:    true,
+			SpotlightURL: spotlightSrv.url,
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+
+		client.CaptureMessage("sampled out", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		assert.Empty(t, spotlightSrv.messages())
+	})
+
+	t.Run("unreachable sidecar", func(t *testing.T) {
+		sentrySrv := newEnvelopeServer(t, "pubkey", "1", nil)
+
+		client, err := NewClient(ClientOptions{
+			Dsn:          sentrySrv.dsn,
+			Spotlight:    true,
+			SpotlightURL: "http://127.0.0.1:1",
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+
+		client.CaptureMessage("delivered", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		assert.Equal(t, []string{"delivered"}, sentrySrv.messages())
+	})
+}
+
 type multiClientEnv struct {
 	client1, client2       *Client
 	transport1, transport2 *MockTransport
@@ -1328,3 +1431,78 @@ func TestClient_MultiClientSetup(t *testing.T) {
 		assert.True(t, gotMetric, "count should arrive at new client")
 	})
 }
+
+func TestClient_SecondaryDsns(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		primary := newEnvelopeServer(t, "pubkey", "1", func(w http.ResponseWriter) {
+			w.Header().Add("X-Sentry-Rate-Limits", "60:error")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		secondary := newEnvelopeServer(t, "pubkey", "2", nil)
+
+		client, err := NewClient(ClientOptions{
+			Dsn:                    primary.dsn,
+			SecondaryDsns:          []string{secondary.dsn, "invalid"},
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+
+		client.CaptureMessage("first", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+		// The primary project is now rate limited, which must not affect
+		// the secondary one.
+		client.CaptureMessage("second", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		dsns := func(s *envelopeServer) []string {
+			var dsns []string
+			for _, event := range s.received() {
+				dsns = append(dsns, event.dsn)
+			}
+			return dsns
+		}
+		assert.Equal(t, []string{"first"}, primary.messages())
+		assert.Equal(t, []string{primary.dsn}, dsns(primary))
+		assert.Equal(t, []string{"first", "second"}, secondary.messages())
+		assert.Equal(t, []string{secondary.dsn, secondary.dsn}, dsns(secondary))
+	})
+}
+
+func TestClient_SecondaryDsnsOnDiscard(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		primary := newEnvelopeServer(t, "pubkey", "1", nil)
+		secondary := newEnvelopeServer(t, "pubkey", "2", func(w http.ResponseWriter) {
+			w.Header().Add("X-Sentry-Rate-Limits", "60:error")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		var mu sync.Mutex
+		var discards []report.DiscardedEvent
+		client, err := NewClient(ClientOptions{
+			Dsn:                    primary.dsn,
+			SecondaryDsns:          []string{secondary.dsn},
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+			DisableClientReports:   true,
+			OnDiscard: func(discard report.DiscardedEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				discards = append(discards, discard)
+			},
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+
+		client.CaptureMessage("first", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+		// The secondary project is now rate limited and drops the second
+		// message.
+		client.CaptureMessage("second", nil, &MockScope{})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		assert.Equal(t, []string{"first", "second"}, primary.messages())
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Contains(t, discards, report.DiscardedEvent{Reason: report.ReasonRateLimitBackoff, Category: ratelimit.CategoryError, Quantity: 1})
+	})
+}
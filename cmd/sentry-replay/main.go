@@ -0,0 +1,205 @@
+// Command sentry-replay uploads envelopes written by sentry.FileTransport to
+// Sentry.
+//
+// Usage:
+//
+//	sentry-replay [flags] <file or directory>...
+//
+// Directories are searched for files written by FileTransport, which are
+// processed in the order they were written. Every file is validated before any
+// of its envelopes is uploaded, so a truncated or corrupted file is reported
+// and skipped as a whole.
+//
+// The flags are:
+//
+//	-dsn string
+//		DSN of the project to upload to. Defaults to the SENTRY_DSN
+//		environment variable.
+//	-dry-run
+//		Only validate files, do not upload anything.
+//	-remove
+//		Remove files once all their envelopes were uploaded.
+//	-timeout duration
+//		Timeout for each upload request. Defaults to 30s.
+//
+// The command exits with a non-zero status if any file could not be read,
+// validated or uploaded. Envelopes of a file that failed part way through are
+// uploaded again when the command is rerun.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	httpinternal "github.com/getsentry/sentry-go/internal/http"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/util"
+)
+
+// fileExtension must match the extension used by sentry.FileTransport.
+const fileExtension = ".envelopes"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+type replayer struct {
+	dsn     *protocol.Dsn
+	client  *http.Client
+	dryRun  bool
+	remove  bool
+	stdout  io.Writer
+	timeout time.Duration
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("sentry-replay", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: sentry-replay [flags] <file or directory>...")
+		flags.PrintDefaults()
+	}
+	dsnFlag := flags.String("dsn", os.Getenv("SENTRY_DSN"), "DSN of the project to upload to")
+	dryRun := flags.Bool("dry-run", false, "only validate files, do not upload anything")
+	remove := flags.Bool("remove", false, "remove files once all their envelopes were uploaded")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout for each upload request")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	r := &replayer{
+		client:  &http.Client{},
+		dryRun:  *dryRun,
+		remove:  *remove,
+		stdout:  stdout,
+		timeout: *timeout,
+	}
+	if !r.dryRun {
+		dsn, err := protocol.NewDsn(*dsnFlag)
+		if err != nil {
+			fmt.Fprintf(stderr, "sentry-replay: invalid DSN: %v\n", err)
+			return 2
+		}
+		r.dsn = dsn
+	}
+
+	files, err := collectFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "sentry-replay: %v\n", err)
+		return 1
+	}
+
+	status := 0
+	for _, file := range files {
+		if err := r.replayFile(file); err != nil {
+			fmt.Fprintf(stderr, "sentry-replay: %s: %v\n", file, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// collectFiles expands directories into the envelope files they contain,
+// sorted by name and therefore by the time they were written.
+func collectFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), fileExtension) {
+				names = append(names, entry.Name())
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			files = append(files, filepath.Join(path, name))
+		}
+	}
+	return files, nil
+}
+
+func (r *replayer) replayFile(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	envelopes, err := protocol.ParseEnvelopes(data)
+	if err != nil {
+		return fmt.Errorf("invalid file: %w", err)
+	}
+	for i, envelope := range envelopes {
+		if len(envelope.Items) == 0 {
+			return fmt.Errorf("invalid file: envelope %d: no items", i+1)
+		}
+	}
+
+	if r.dryRun {
+		fmt.Fprintf(r.stdout, "%s: %d valid envelopes\n", file, len(envelopes))
+		return nil
+	}
+
+	for i, envelope := range envelopes {
+		if err := r.upload(envelope); err != nil {
+			return fmt.Errorf("uploaded %d of %d envelopes: %s: %w", i, len(envelopes), util.EnvelopeIdentifier(envelope), err)
+		}
+	}
+	fmt.Fprintf(r.stdout, "%s: uploaded %d envelopes\n", file, len(envelopes))
+
+	if r.remove {
+		return os.Remove(file)
+	}
+	return nil
+}
+
+func (r *replayer) upload(envelope *protocol.Envelope) error {
+	// The envelope is sent to the configured project, which is not
+	// necessarily the one it was written for.
+	envelope.Header.Dsn = r.dsn
+	envelope.Header.SentAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	request, err := httpinternal.NewEnvelopeRequest(ctx, r.dsn, envelope, httpinternal.Compression{Encoding: httpinternal.EncodingGzip})
+	if err != nil {
+		return err
+	}
+	result, err := util.DoSendRequest(r.client, request, util.EnvelopeIdentifier(envelope))
+	if err != nil {
+		return err
+	}
+	switch {
+	case result.Success:
+		return nil
+	case result.StatusCode == http.StatusTooManyRequests:
+		return errors.New("rate limited by Sentry, try again later")
+	default:
+		return fmt.Errorf("unexpected status %d", result.StatusCode)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/getsentry/sentry-go/internal/protocol"
+)
+
+func writeEvents(t *testing.T, dir string, messages ...string) {
+	t.Helper()
+	transport := sentry.NewFileTransport(dir)
+	transport.MaxFileSize = 1
+	transport.Configure(sentry.ClientOptions{Dsn: "https://key@sentry.io/42"})
+	defer transport.Close()
+	for _, message := range messages {
+		event := sentry.NewEvent()
+		event.Message = message
+		transport.SendEvent(event)
+	}
+}
+
+type testServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	envelopes []*protocol.Envelope
+}
+
+func newTestServer(t *testing.T, status int) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("invalid gzip body: %v", err)
+				return
+			}
+			body = gz
+		}
+		data, _ := io.ReadAll(body)
+		envelope, err := protocol.ParseEnvelope(data)
+		if err != nil {
+			t.Errorf("invalid envelope: %v", err)
+		}
+		s.mu.Lock()
+		s.envelopes = append(s.envelopes, envelope)
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) dsn() string {
+	return fmt.Sprintf("http://key@%s/1", s.Listener.Addr())
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeEvents(t, dir, "first", "second")
+	server := newTestServer(t, http.StatusOK)
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-dsn", server.dsn(), "-remove", dir}, &stdout, &stderr); status != 0 {
+		t.Fatalf("run() = %d, stderr: %s", status, stderr.String())
+	}
+
+	if len(server.envelopes) != 2 {
+		t.Fatalf("expected 2 uploaded envelopes, got %d", len(server.envelopes))
+	}
+	for i, want := range []string{"first", "second"} {
+		envelope := server.envelopes[i]
+		if !strings.Contains(string(envelope.Items[0].Payload), want) {
+			t.Errorf("envelope %d does not contain the %s event", i, want)
+		}
+		if envelope.Header.Dsn == nil || envelope.Header.Dsn.GetProjectID() != "1" {
+			t.Errorf("envelope %d: Dsn = %v, want the upload DSN", i, envelope.Header.Dsn)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected uploaded files to be removed, found %d", len(entries))
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	dir := t.TempDir()
+	writeEvents(t, dir, "first")
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-dry-run", dir}, &stdout, &stderr); status != 0 {
+		t.Fatalf("run() = %d, stderr: %s", status, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1 valid envelopes") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("dry run should keep files, found %d", len(entries))
+	}
+}
+
+func TestRun_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	writeEvents(t, dir, "valid")
+	corrupt := filepath.Join(dir, "sentry-00000000T000000.000000000-000001.envelopes")
+	if err := os.WriteFile(corrupt, []byte("{}\n{\"type\":\"event\",\"length\":100}\n{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, http.StatusOK)
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-dsn", server.dsn(), "-remove", dir}, &stdout, &stderr); status != 1 {
+		t.Fatalf("run() = %d, want 1", status)
+	}
+	if !strings.Contains(stderr.String(), "invalid file") {
+		t.Errorf("expected the corrupt file to be reported, stderr: %s", stderr.String())
+	}
+	if len(server.envelopes) != 1 {
+		t.Errorf("expected the valid file to be uploaded, got %d envelopes", len(server.envelopes))
+	}
+	if _, err := os.Stat(corrupt); err != nil {
+		t.Errorf("corrupt file should be kept: %v", err)
+	}
+}
+
+func TestRun_UploadFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeEvents(t, dir, "first")
+	server := newTestServer(t, http.StatusServiceUnavailable)
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"-dsn", server.dsn(), "-remove", dir}, &stdout, &stderr); status != 1 {
+		t.Fatalf("run() = %d, want 1", status)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("files that failed to upload should be kept, found %d", len(entries))
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if status := run(nil, &stdout, &stderr); status != 2 {
+		t.Errorf("run() without arguments = %d, want 2", status)
+	}
+	if status := run([]string{"-dsn", "invalid", t.TempDir()}, &stdout, &stderr); status != 2 {
+		t.Errorf("run() with invalid DSN = %d, want 2", status)
+	}
+}
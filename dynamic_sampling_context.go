@@ -1,6 +1,7 @@
 package sentry
 
 import (
+	"math"
 	"strconv"
 	"strings"
 
@@ -58,6 +59,7 @@ func DynamicSamplingContextFromTransaction(span *Span) DynamicSamplingContext {
 	if sampleRate := span.sampleRate; sampleRate != 0 {
 		entries["sample_rate"] = strconv.FormatFloat(sampleRate, 'f', -1, 64)
 	}
+	entries["sample_rand"] = formatSampleRand(span.sampleRand)
 
 	if dsn := client.dsn; dsn != nil {
 		if publicKey := dsn.GetPublicKey(); publicKey != "" {
@@ -137,6 +139,7 @@ func DynamicSamplingContextFromScope(scope *Scope, client *Client) DynamicSampli
 	if sampleRate := client.options.TracesSampleRate; sampleRate != 0 {
 		entries["sample_rate"] = strconv.FormatFloat(sampleRate, 'f', -1, 64)
 	}
+	entries["sample_rand"] = formatSampleRand(propagationContext.SampleRand)
 
 	if dsn := client.dsn; dsn != nil {
 		if publicKey := dsn.GetPublicKey(); publicKey != "" {
@@ -158,3 +161,59 @@ func DynamicSamplingContextFromScope(scope *Scope, client *Client) DynamicSampli
 		Frozen:  true,
 	}
 }
+
+// sampleRand returns the valid sample_rand entry of the context, if any.
+func (d DynamicSamplingContext) sampleRand() (float64, bool) {
+	value, ok := d.Entries["sample_rand"]
+	if !ok {
+		return 0, false
+	}
+	sampleRand, err := strconv.ParseFloat(value, 64)
+	if err != nil || sampleRand < 0.0 || sampleRand >= 1.0 {
+		return 0, false
+	}
+	return sampleRand, true
+}
+
+// sampleRate returns the valid sample_rate entry of the context, if any.
+func (d DynamicSamplingContext) sampleRate() (float64, bool) {
+	value, ok := d.Entries["sample_rate"]
+	if !ok {
+		return 0, false
+	}
+	sampleRate, err := strconv.ParseFloat(value, 64)
+	if err != nil || sampleRate < 0.0 || sampleRate > 1.0 {
+		return 0, false
+	}
+	return sampleRate, true
+}
+
+// newSampleRand generates the random value that sampling decisions in a
+// trace are compared to. For a trace continued without a sample_rand, the
+// value is consistent with the sampling decision and sample rate of the
+// parent, if they are known.
+//
+// See https://develop.sentry.dev/sdk/telemetry/traces/#propagated-random-value
+func newSampleRand(parentSampled Sampled, parentSampleRate float64, hasParentSampleRate bool) float64 {
+	r := rng.Float64()
+	if !hasParentSampleRate {
+		return truncateSampleRand(r)
+	}
+	switch parentSampled {
+	case SampledTrue:
+		r *= parentSampleRate
+	case SampledFalse:
+		r = parentSampleRate + r*(1-parentSampleRate)
+	}
+	return truncateSampleRand(r)
+}
+
+// truncateSampleRand truncates a sample_rand to the six decimals it is
+// propagated with, so that it stays below 1.
+func truncateSampleRand(sampleRand float64) float64 {
+	return math.Floor(sampleRand*1e6) / 1e6
+}
+
+func formatSampleRand(sampleRand float64) string {
+	return strconv.FormatFloat(truncateSampleRand(sampleRand), 'f', 6, 64)
+}
@@ -90,12 +90,14 @@ func TestDynamicSamplingContextFromTransaction(t *testing.T) {
 				})
 				txn := StartTransaction(ctx, "name", WithTransactionSource(SourceCustom))
 				txn.TraceID = TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03")
+				txn.sampleRand = 0.25
 				return txn
 			}(),
 			want: DynamicSamplingContext{
 				Frozen: true,
 				Entries: map[string]string{
 					"sample_rate": "1",
+					"sample_rand": "0.250000",
 					"trace_id":    "d49d9bf66f13450b81f65bc51cf49c03",
 					"public_key":  "public",
 					"release":     "1.0.0",
@@ -116,15 +118,17 @@ func TestDynamicSamplingContextFromTransaction(t *testing.T) {
 				})
 				txn := StartTransaction(ctx, "name", WithTransactionSource(SourceURL))
 				txn.TraceID = TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03")
+				txn.sampleRand = 0.9999999
 				return txn
 			}(),
 			want: DynamicSamplingContext{
 				Frozen: true,
 				Entries: map[string]string{
-					"trace_id":   "d49d9bf66f13450b81f65bc51cf49c03",
-					"public_key": "public",
-					"release":    "1.0.0",
-					"sampled":    "false",
+					"trace_id":    "d49d9bf66f13450b81f65bc51cf49c03",
+					"public_key":  "public",
+					"release":     "1.0.0",
+					"sampled":     "false",
+					"sample_rand": "0.999999",
 				},
 			},
 		},
@@ -188,8 +192,9 @@ func TestDynamicSamplingContextFromScope(t *testing.T) {
 		"Valid input": {
 			scope: &Scope{
 				propagationContext: PropagationContext{
-					TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
-					SpanID:  SpanIDFromHex("a9f442f9330b4e09"),
+					TraceID:    TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+					SpanID:     SpanIDFromHex("a9f442f9330b4e09"),
+					SampleRand: 0.5,
 				},
 			},
 			client: func() *Client {
@@ -209,6 +214,7 @@ func TestDynamicSamplingContextFromScope(t *testing.T) {
 					"public_key":  "public",
 					"release":     "1.0.0",
 					"environment": "production",
+					"sample_rand": "0.500000",
 				},
 				Frozen: true,
 			},
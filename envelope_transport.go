@@ -0,0 +1,228 @@
+package sentry
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
+)
+
+// Envelope is the format in which Sentry ingests all data. It consists of a
+// header and a list of items, such as an event and its attachments or a batch
+// of logs.
+//
+// See https://develop.sentry.dev/sdk/data-model/envelopes/.
+type Envelope struct {
+	Header *EnvelopeHeader
+	Items  []*EnvelopeItem
+}
+
+// EnvelopeHeader is the header of an Envelope.
+type EnvelopeHeader struct {
+	// EventID is the ID of the event, if the envelope contains one.
+	EventID string
+	// SentAt is the time at which the envelope was sent by the SDK.
+	SentAt time.Time
+	// Dsn is the DSN the envelope is addressed to.
+	Dsn *Dsn
+	// Sdk describes the SDK that created the envelope.
+	Sdk *SdkInfo
+	// Trace is the dynamic sampling context of the trace the envelope belongs
+	// to.
+	Trace map[string]string
+}
+
+// EnvelopeItem is a single item of an Envelope.
+type EnvelopeItem struct {
+	Header  *EnvelopeItemHeader
+	Payload []byte
+}
+
+// EnvelopeItemHeader is the header of an EnvelopeItem.
+type EnvelopeItemHeader struct {
+	// Type is the type of the payload.
+	Type EnvelopeItemType
+	// Length is the length of the payload in bytes.
+	Length *int
+	// Filename is the name of an attachment.
+	Filename string
+	// ContentType is the MIME type of an attachment.
+	ContentType string
+	// ItemCount is the number of entries in a batch of logs, metrics or spans.
+	ItemCount *int
+	// Platform is the platform of a profile chunk.
+	Platform string
+
+	// spanCount is the number of spans of a transaction, used for client
+	// reports.
+	spanCount int
+}
+
+// EnvelopeItemType is the type of the payload of an EnvelopeItem.
+type EnvelopeItemType string
+
+// Envelope item types sent by the SDK.
+const (
+	EnvelopeItemTypeEvent        EnvelopeItemType = "event"
+	EnvelopeItemTypeTransaction  EnvelopeItemType = "transaction"
+	EnvelopeItemTypeCheckIn      EnvelopeItemType = "check_in"
+	EnvelopeItemTypeAttachment   EnvelopeItemType = "attachment"
+	EnvelopeItemTypeLog          EnvelopeItemType = "log"
+	EnvelopeItemTypeTraceMetric  EnvelopeItemType = "trace_metric"
+	EnvelopeItemTypeSpan         EnvelopeItemType = "span"
+	EnvelopeItemTypeProfile      EnvelopeItemType = "profile"
+	EnvelopeItemTypeProfileChunk EnvelopeItemType = "profile_chunk"
+	EnvelopeItemTypeClientReport EnvelopeItemType = "client_report"
+)
+
+// ParseEnvelope parses a serialized envelope, as written by Envelope.Serialize
+// or Envelope.WriteTo.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	envelope, err := protocol.ParseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	return envelopeFromProtocol(envelope), nil
+}
+
+// Serialize returns the envelope in the format expected by the Sentry
+// envelope endpoint.
+func (e *Envelope) Serialize() ([]byte, error) {
+	return e.toProtocol().Serialize()
+}
+
+// WriteTo writes the serialized envelope to w.
+func (e *Envelope) WriteTo(w io.Writer) (int64, error) {
+	return e.toProtocol().WriteTo(w)
+}
+
+// envelopeFromProtocol converts an envelope built by the SDK to the public
+// Envelope type. Payloads are shared, not copied.
+func envelopeFromProtocol(envelope *protocol.Envelope) *Envelope {
+	e := &Envelope{Items: make([]*EnvelopeItem, 0, len(envelope.Items))}
+	if h := envelope.Header; h != nil {
+		e.Header = &EnvelopeHeader{
+			EventID: h.EventID,
+			SentAt:  h.SentAt,
+			Sdk:     h.Sdk,
+			Trace:   h.Trace,
+		}
+		if h.Dsn != nil {
+			e.Header.Dsn = &Dsn{Dsn: *h.Dsn}
+		}
+	}
+	for _, item := range envelope.Items {
+		i := &EnvelopeItem{Payload: item.Payload}
+		if h := item.Header; h != nil {
+			i.Header = &EnvelopeItemHeader{
+				Type:        EnvelopeItemType(h.Type),
+				Length:      h.Length,
+				Filename:    h.Filename,
+				ContentType: h.ContentType,
+				ItemCount:   h.ItemCount,
+				Platform:    h.Platform,
+				spanCount:   h.SpanCount,
+			}
+		}
+		e.Items = append(e.Items, i)
+	}
+	return e
+}
+
+// toProtocol converts the envelope to the type used by the SDK internally.
+// Payloads are shared, not copied.
+func (e *Envelope) toProtocol() *protocol.Envelope {
+	envelope := &protocol.Envelope{Items: make([]*protocol.EnvelopeItem, 0, len(e.Items))}
+	if h := e.Header; h != nil {
+		envelope.Header = &protocol.EnvelopeHeader{
+			EventID: h.EventID,
+			SentAt:  h.SentAt,
+			Sdk:     h.Sdk,
+			Trace:   h.Trace,
+		}
+		if h.Dsn != nil {
+			envelope.Header.Dsn = &h.Dsn.Dsn
+		}
+	}
+	for _, item := range e.Items {
+		i := &protocol.EnvelopeItem{Payload: item.Payload}
+		if h := item.Header; h != nil {
+			i.Header = &protocol.EnvelopeItemHeader{
+				Type:        protocol.EnvelopeItemType(h.Type),
+				Length:      h.Length,
+				Filename:    h.Filename,
+				ContentType: h.ContentType,
+				ItemCount:   h.ItemCount,
+				Platform:    h.Platform,
+				SpanCount:   h.spanCount,
+			}
+			if h.Type == EnvelopeItemTypeTransaction && h.spanCount == 0 {
+				i.Header.SpanCount = protocol.TransactionSpanCount(item.Payload)
+			}
+		}
+		envelope.Items = append(envelope.Items, i)
+	}
+	return envelope
+}
+
+// EnvelopeTransport is used by the Client to deliver envelopes, for example to
+// a queue, a file or a proxy. Unlike Transport, it receives logs, metrics,
+// check-ins and attachments exactly as they would be sent to Sentry, including
+// client reports.
+//
+// Set ClientOptions.EnvelopeTransport to use it.
+type EnvelopeTransport interface {
+	// Configure is called once with the options of the Client, before any
+	// envelope is sent.
+	Configure(options ClientOptions)
+	// SendEnvelope delivers the envelope or queues it for delivery. It should
+	// not block, since it is called while the SDK sends other telemetry.
+	SendEnvelope(envelope *Envelope) error
+	// Flush waits until queued envelopes are delivered, blocking for at most
+	// timeout. It returns false if the timeout was reached.
+	Flush(timeout time.Duration) bool
+	// FlushWithContext waits until queued envelopes are delivered, blocking
+	// until ctx is done. It returns false if ctx was done first.
+	FlushWithContext(ctx context.Context) bool
+	// Close releases the resources of the transport.
+	Close()
+}
+
+// envelopeTransportAdapter wraps an EnvelopeTransport to implement the
+// internal TelemetryTransport interface.
+type envelopeTransportAdapter struct {
+	transport EnvelopeTransport
+	provider  report.ClientReportProvider
+}
+
+func (a *envelopeTransportAdapter) SendEnvelope(envelope *protocol.Envelope) error {
+	a.provider.AttachToEnvelope(envelope)
+	return a.transport.SendEnvelope(envelopeFromProtocol(envelope))
+}
+
+// HasCapacity always returns true, since back pressure is up to the
+// EnvelopeTransport.
+func (a *envelopeTransportAdapter) HasCapacity() bool {
+	return true
+}
+
+// IsRateLimited always returns false, since an EnvelopeTransport does not
+// report rate limits.
+func (a *envelopeTransportAdapter) IsRateLimited(ratelimit.Category) bool {
+	return false
+}
+
+func (a *envelopeTransportAdapter) Flush(timeout time.Duration) bool {
+	return a.transport.Flush(timeout)
+}
+
+func (a *envelopeTransportAdapter) FlushWithContext(ctx context.Context) bool {
+	return a.transport.FlushWithContext(ctx)
+}
+
+func (a *envelopeTransportAdapter) Close() {
+	a.transport.Close()
+}
@@ -0,0 +1,149 @@
+package sentry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingEnvelopeTransport struct {
+	mu         sync.Mutex
+	options    ClientOptions
+	envelopes  []*Envelope
+	configured int
+	closed     bool
+}
+
+func (t *recordingEnvelopeTransport) Configure(options ClientOptions) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.options = options
+	t.configured++
+}
+
+func (t *recordingEnvelopeTransport) SendEnvelope(envelope *Envelope) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.envelopes = append(t.envelopes, envelope)
+	return nil
+}
+
+func (t *recordingEnvelopeTransport) Flush(time.Duration) bool { return true }
+
+func (t *recordingEnvelopeTransport) FlushWithContext(context.Context) bool { return true }
+
+func (t *recordingEnvelopeTransport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+}
+
+// itemTypes returns the types of all items sent, excluding client reports.
+func (t *recordingEnvelopeTransport) itemTypes() []EnvelopeItemType {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var types []EnvelopeItemType
+	for _, envelope := range t.envelopes {
+		for _, item := range envelope.Items {
+			if item.Header.Type != EnvelopeItemTypeClientReport {
+				types = append(types, item.Header.Type)
+			}
+		}
+	}
+	return types
+}
+
+func TestClient_EnvelopeTransport(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		transport := &recordingEnvelopeTransport{}
+		client, err := NewClient(ClientOptions{
+			Dsn:                    "https://public@example.com/1",
+			Transport:              &MockTransport{},
+			EnvelopeTransport:      transport,
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+			Integrations: func([]Integration) []Integration {
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		hub := NewHub(client, NewScope())
+		ctx := SetHubOnContext(context.Background(), hub)
+
+		scope := NewScope()
+		scope.AddAttachment(&Attachment{Filename: "a.txt", Payload: []byte("a")})
+		client.CaptureMessage("message", nil, scope)
+		client.CaptureCheckIn(&CheckIn{MonitorSlug: "job", Status: CheckInStatusOK}, nil, nil)
+		NewLogger(ctx).Info().Emit("log")
+		client.Flush(time.Second)
+		client.Close()
+
+		assert.ElementsMatch(t, []EnvelopeItemType{
+			EnvelopeItemTypeEvent,
+			EnvelopeItemTypeAttachment,
+			EnvelopeItemTypeCheckIn,
+			EnvelopeItemTypeLog,
+		}, transport.itemTypes())
+		assert.Equal(t, 1, transport.configured)
+		assert.Equal(t, "https://public@example.com/1", transport.options.Dsn)
+		assert.True(t, transport.closed)
+	})
+}
+
+func TestParseEnvelope(t *testing.T) {
+	envelope := &Envelope{
+		Header: &EnvelopeHeader{EventID: "b81c5be4d31e48959103a1f878a1efcb"},
+		Items: []*EnvelopeItem{{
+			Header:  &EnvelopeItemHeader{Type: EnvelopeItemTypeEvent},
+			Payload: []byte(`{"message":"hello"}`),
+		}},
+	}
+	data, err := envelope.Serialize()
+	require.NoError(t, err)
+
+	parsed, err := ParseEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, envelope.Header.EventID, parsed.Header.EventID)
+	require.Len(t, parsed.Items, 1)
+	assert.Equal(t, EnvelopeItemTypeEvent, parsed.Items[0].Header.Type)
+	assert.Equal(t, envelope.Items[0].Payload, parsed.Items[0].Payload)
+}
+
+func TestEnvelope_ProtocolRoundTrip(t *testing.T) {
+	dsn, err := NewDsn("https://public@example.com/1")
+	require.NoError(t, err)
+	itemCount := 2
+	envelope := &Envelope{
+		Header: &EnvelopeHeader{
+			EventID: "b81c5be4d31e48959103a1f878a1efcb",
+			Dsn:     dsn,
+			Trace:   map[string]string{"trace_id": "d6c4f03650bd47699ec65c84352b6208"},
+		},
+		Items: []*EnvelopeItem{
+			{
+				Header:  &EnvelopeItemHeader{Type: EnvelopeItemTypeTransaction},
+				Payload: []byte(`{"spans":[{},{}]}`),
+			},
+			{
+				Header:  &EnvelopeItemHeader{Type: EnvelopeItemTypeLog, ItemCount: &itemCount},
+				Payload: []byte(`{"items":[{},{}]}`),
+			},
+		},
+	}
+
+	internal := envelope.toProtocol()
+	assert.Equal(t, dsn.String(), internal.Header.Dsn.String())
+	assert.Equal(t, 3, internal.Items[0].Header.SpanCount)
+
+	converted := envelopeFromProtocol(internal)
+	assert.Equal(t, dsn.String(), converted.Header.Dsn.String())
+	assert.Equal(t, envelope.Header.Trace, converted.Header.Trace)
+	require.Len(t, converted.Items, 2)
+	assert.Equal(t, EnvelopeItemTypeLog, converted.Items[1].Header.Type)
+	assert.Equal(t, &itemCount, converted.Items[1].Header.ItemCount)
+	assert.Equal(t, envelope.Items[1].Payload, converted.Items[1].Payload)
+	assert.Equal(t, 3, converted.Items[0].Header.spanCount)
+}
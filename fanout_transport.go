@@ -0,0 +1,127 @@
+package sentry
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/offline"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/report"
+)
+
+// fanoutTransport sends every event to a primary transport and to an
+// HTTPTransport for every secondary DSN. Each secondary transport has its own
+// rate limits and client reports.
+type fanoutTransport struct {
+	primary     Transport
+	dsn         *protocol.Dsn
+	secondaries []destinationTransport
+}
+
+// newFanoutTransport wraps primary in a fanoutTransport if options has valid
+// secondary DSNs, or returns primary otherwise.
+func newFanoutTransport(primary Transport, options ClientOptions) Transport {
+	secondaries := newDestinationTransports(options.SecondaryDsns, options)
+	if len(secondaries) == 0 {
+		return primary
+	}
+	return &fanoutTransport{primary: primary, secondaries: secondaries}
+}
+
+// destinationTransport is an HTTPTransport for a DSN other than
+// ClientOptions.Dsn.
+type destinationTransport struct {
+	dsn       *protocol.Dsn
+	rawDsn    string
+	transport *HTTPTransport
+}
+
+// newDestinationTransports returns a destinationTransport for every valid DSN
+// in rawDsns. Invalid DSNs are logged and skipped.
+func newDestinationTransports(rawDsns []string, options ClientOptions) []destinationTransport {
+	var destinations []destinationTransport
+	for _, rawDsn := range rawDsns {
+		dsn, err := protocol.NewDsn(rawDsn)
+		if err != nil {
+			debuglog.Printf("Ignoring invalid DSN: %v", err)
+			continue
+		}
+		transport := NewHTTPTransport()
+		transport.recorder, transport.provider = newDestinationReporter(options)
+		destinations = append(destinations, destinationTransport{dsn: dsn, rawDsn: rawDsn, transport: transport})
+	}
+	return destinations
+}
+
+// newDestinationReporter returns the client report recorder and provider of a
+// destinationTransport, which must not share the client's own. Its discards
+// are passed to ClientOptions.OnDiscard like those of the client.
+func newDestinationReporter(options ClientOptions) (report.ClientReportRecorder, report.ClientReportProvider) {
+	aggregator := report.NewAggregator()
+	aggregator.SetDiscardCallback(options.OnDiscard)
+	if options.DisableClientReports {
+		return aggregator, report.NoopProvider()
+	}
+	return aggregator, aggregator
+}
+
+// configure configures the transport with options addressed to its own DSN
+// and offline cache directory.
+func (d destinationTransport) configure(options ClientOptions) {
+	options.OfflineCache.Dir = offline.DirForDsn(options.OfflineCache.Dir, d.dsn)
+	options.Dsn = d.rawDsn
+	d.transport.Configure(options)
+}
+
+func (t *fanoutTransport) Configure(options ClientOptions) {
+	t.primary.Configure(options)
+	if options.Dsn != "" {
+		t.dsn, _ = protocol.NewDsn(options.Dsn)
+	}
+	for _, secondary := range t.secondaries {
+		secondary.configure(options)
+	}
+}
+
+// SendEvent sends the event to all destinations. Transports serialize events
+// before returning, so the event is never shared with a background goroutine.
+func (t *fanoutTransport) SendEvent(event *Event) {
+	t.primary.SendEvent(event)
+
+	from := t.dsn
+	if event.sdkMetaData.dsn != nil {
+		from = event.sdkMetaData.dsn
+	}
+	for _, secondary := range t.secondaries {
+		secondary.transport.SendEvent(readdressEvent(event, from, secondary.dsn))
+	}
+}
+
+func (t *fanoutTransport) Flush(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.FlushWithContext(ctx)
+}
+
+func (t *fanoutTransport) FlushWithContext(ctx context.Context) bool {
+	ok := t.primary.FlushWithContext(ctx)
+	for _, secondary := range t.secondaries {
+		if !secondary.transport.FlushWithContext(ctx) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// stats returns the stats of the primary transport.
+func (t *fanoutTransport) stats() protocol.TransportStats {
+	return transportStats(t.primary)
+}
+
+func (t *fanoutTransport) Close() {
+	t.primary.Close()
+	for _, secondary := range t.secondaries {
+		secondary.transport.Close()
+	}
+}
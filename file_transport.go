@@ -0,0 +1,287 @@
+package sentry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/report"
+)
+
+const (
+	defaultFileTransportMaxFileSize int64 = 10 << 20
+
+	fileTransportPrefix    = "sentry-"
+	fileTransportExtension = ".envelopes"
+)
+
+// FileTransport is a Transport that writes envelopes to files in a directory
+// instead of sending them to Sentry. It is meant for deployments that cannot
+// reach Sentry directly: the files can be moved elsewhere and uploaded later
+// with the sentry-replay command from cmd/sentry-replay.
+//
+// Envelopes are appended to the current file in the Sentry envelope format.
+// Once a file grows beyond MaxFileSize, a new file is started. Files are named
+// so that sorting them by name yields the order in which they were written.
+//
+// FileTransport can be set as ClientOptions.Transport, in which case it
+// receives events and transactions through the legacy pipeline, or as
+// ClientOptions.EnvelopeTransport, in which case it receives every envelope
+// the Client would send to Sentry, including logs, metrics and spans.
+//
+// Envelopes are written synchronously, so Flush only has to sync the current
+// file to disk.
+type FileTransport struct {
+	// Dir is the directory files are written to. It is created if it does not
+	// exist.
+	Dir string
+	// MaxFileSize is the size in bytes after which a new file is started.
+	// Defaults to 10 MiB.
+	MaxFileSize int64
+	// MaxFiles is the maximum number of files kept in Dir. When exceeded, the
+	// oldest files are removed and their envelopes are reported in client
+	// reports with the "cache_overflow" reason. Zero keeps all files.
+	MaxFiles int
+
+	dsn      *protocol.Dsn
+	recorder report.ClientReportRecorder
+	provider report.ClientReportProvider
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	seq    int
+	closed bool
+}
+
+// NewFileTransport returns a new FileTransport that writes to dir.
+func NewFileTransport(dir string) *FileTransport {
+	return &FileTransport{
+		Dir:         dir,
+		MaxFileSize: defaultFileTransportMaxFileSize,
+	}
+}
+
+// Configure is called by the Client itself, providing its own ClientOptions.
+func (t *FileTransport) Configure(options ClientOptions) {
+	if options.Dsn != "" {
+		dsn, err := protocol.NewDsn(options.Dsn)
+		if err != nil {
+			debuglog.Printf("%v\n", err)
+		} else {
+			t.dsn = dsn
+		}
+	}
+
+	if t.recorder == nil {
+		t.recorder = report.NoopRecorder()
+	}
+	if t.provider == nil {
+		t.provider = report.NoopProvider()
+	}
+	if t.MaxFileSize <= 0 {
+		t.MaxFileSize = defaultFileTransportMaxFileSize
+	}
+}
+
+// SendEvent writes an event to the current file.
+func (t *FileTransport) SendEvent(event *Event) {
+	header := &protocol.EnvelopeHeader{
+		EventID: string(event.EventID),
+		SentAt:  time.Now(),
+		Dsn:     t.dsn,
+		Trace:   event.GetDynamicSamplingContext(),
+		Sdk:     &protocol.SdkInfo{Name: event.Sdk.Name, Version: event.Sdk.Version},
+	}
+	if header.EventID == "" {
+		header.EventID = protocol.GenerateEventID()
+	}
+	envelope, err := event.ToEnvelope(header)
+	if err != nil {
+		debuglog.Printf("Failed to convert event to envelope, skipping delivery. %s: %v", eventDebugContext(event), err)
+		recordForEvent(t.recorder, discardReasonForError(err), event)
+		return
+	}
+	t.provider.AttachToEnvelope(envelope)
+
+	if err := t.writeEnvelope(envelope); err != nil {
+		debuglog.Printf("Failed to write %s to %s: %v", eventDebugContext(event), t.Dir, err)
+		return
+	}
+	debuglog.Printf("Wrote %s to %s", eventDebugContext(event), t.Dir)
+}
+
+// SendEnvelope writes an envelope to the current file. Client reports are
+// expected to be attached by the caller, as the Client does for an
+// EnvelopeTransport.
+func (t *FileTransport) SendEnvelope(envelope *Envelope) error {
+	if err := t.writeEnvelope(envelope.toProtocol()); err != nil {
+		debuglog.Printf("Failed to write envelope to %s: %v", t.Dir, err)
+		return err
+	}
+	return nil
+}
+
+// Flush syncs the current file to disk, blocking for at most timeout. It
+// returns false if the timeout was reached or syncing failed.
+func (t *FileTransport) Flush(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.FlushWithContext(ctx)
+}
+
+// FlushWithContext syncs the current file to disk, blocking until ctx is done.
+// It returns false if ctx was done first or syncing failed.
+func (t *FileTransport) FlushWithContext(ctx context.Context) bool {
+	done := make(chan bool, 1)
+	go func() {
+		done <- t.sync()
+	}()
+
+	select {
+	case ok := <-done:
+		return ok
+	case <-ctx.Done():
+		debuglog.Println("Failed to sync envelopes to disk before the flush deadline")
+		return false
+	}
+}
+
+// Close closes the current file. Events sent after Close are dropped.
+func (t *FileTransport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	t.closeFile()
+}
+
+// writeEnvelope serializes and writes an envelope, recording its items as
+// discarded if that fails.
+func (t *FileTransport) writeEnvelope(envelope *protocol.Envelope) error {
+	data, err := envelope.Serialize()
+	if err == nil {
+		err = t.write(data)
+	}
+	if err != nil {
+		t.recorder.RecordForEnvelope(report.ReasonInternalError, envelope)
+	}
+	return err
+}
+
+func (t *FileTransport) write(data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return fmt.Errorf("transport is closed")
+	}
+	if t.file != nil && t.size > 0 && t.size+int64(len(data)) > t.MaxFileSize {
+		t.closeFile()
+	}
+	if t.file == nil {
+		if err := t.openFile(); err != nil {
+			return err
+		}
+	}
+
+	n, err := t.file.Write(data)
+	t.size += int64(n)
+	return err
+}
+
+// openFile starts a new file and removes the oldest files if there are more
+// than MaxFiles. Must be called with t.mu held.
+func (t *FileTransport) openFile() error {
+	if err := os.MkdirAll(t.Dir, 0o700); err != nil {
+		return err
+	}
+
+	t.seq++
+	name := fmt.Sprintf("%s%s-%06d%s", fileTransportPrefix, time.Now().UTC().Format("20060102T150405.000000000"), t.seq, fileTransportExtension)
+	file, err := os.OpenFile(filepath.Join(t.Dir, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	t.file = file
+	t.size = 0
+
+	t.removeOldFiles()
+	return nil
+}
+
+// closeFile closes the current file. Must be called with t.mu held.
+func (t *FileTransport) closeFile() {
+	if t.file == nil {
+		return
+	}
+	if err := t.file.Close(); err != nil {
+		debuglog.Printf("Failed to close %s: %v", t.file.Name(), err)
+	}
+	t.file = nil
+	t.size = 0
+}
+
+// removeOldFiles removes the oldest files beyond MaxFiles. Must be called with
+// t.mu held.
+func (t *FileTransport) removeOldFiles() {
+	if t.MaxFiles <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(t.Dir)
+	if err != nil {
+		debuglog.Printf("Failed to list %s: %v", t.Dir, err)
+		return
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, fileTransportPrefix) && strings.HasSuffix(name, fileTransportExtension) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for len(names) > t.MaxFiles {
+		path := filepath.Join(t.Dir, names[0])
+		names = names[1:]
+
+		debuglog.Printf("Removing %s, more than %d files in %s", path, t.MaxFiles, t.Dir)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			debuglog.Printf("Failed to read %s: %v", path, err)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			debuglog.Printf("Failed to remove %s: %v", path, err)
+			continue
+		}
+		// Envelopes in a partially written file are still recorded.
+		envelopes, _ := protocol.ParseEnvelopes(data)
+		for _, envelope := range envelopes {
+			t.recorder.RecordForEnvelope(report.ReasonCacheOverflow, envelope)
+		}
+	}
+}
+
+func (t *FileTransport) sync() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.file == nil {
+		return true
+	}
+	if err := t.file.Sync(); err != nil {
+		debuglog.Printf("Failed to sync %s: %v", t.file.Name(), err)
+		return false
+	}
+	return true
+}
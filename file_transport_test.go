@@ -0,0 +1,184 @@
+package sentry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
+)
+
+func readEnvelopeFiles(t *testing.T, dir string) [][]*protocol.Envelope {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	var files [][]*protocol.Envelope
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("ReadFile() failed: %v", err)
+		}
+		envelopes, err := protocol.ParseEnvelopes(data)
+		if err != nil {
+			t.Fatalf("ParseEnvelopes(%s) failed: %v", entry.Name(), err)
+		}
+		files = append(files, envelopes)
+	}
+	return files
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "envelopes")
+	transport := NewFileTransport(dir)
+	transport.Configure(ClientOptions{Dsn: "https://key@sentry.io/42"})
+
+	event := NewEvent()
+	event.EventID = "0123456789abcdef0123456789abcdef"
+	event.Message = "offline"
+	event.Attachments = []*Attachment{{Filename: "log.txt", Payload: []byte("line 1\nline 2")}}
+	transport.SendEvent(event)
+
+	transaction := NewEvent()
+	transaction.Type = transactionType
+	transaction.Spans = []*Span{{}, {}}
+	transport.SendEvent(transaction)
+
+	if !transport.Flush(time.Second) {
+		t.Fatal("Flush failed")
+	}
+	transport.Close()
+
+	files := readEnvelopeFiles(t, dir)
+	if len(files) != 1 || len(files[0]) != 2 {
+		t.Fatalf("expected one file with 2 envelopes, got %v", files)
+	}
+
+	envelope := files[0][0]
+	if got := envelope.Header.EventID; got != string(event.EventID) {
+		t.Errorf("EventID = %q, want %q", got, event.EventID)
+	}
+	if envelope.Header.Dsn == nil || envelope.Header.Dsn.GetProjectID() != "42" {
+		t.Errorf("Dsn = %v, want project 42", envelope.Header.Dsn)
+	}
+	if len(envelope.Items) != 2 || envelope.Items[1].Header.Type != protocol.EnvelopeItemTypeAttachment {
+		t.Fatalf("expected event and attachment items, got %d items", len(envelope.Items))
+	}
+	if got := string(envelope.Items[1].Payload); got != "line 1\nline 2" {
+		t.Errorf("attachment payload = %q", got)
+	}
+
+	if got := files[0][1].Items[0].Header.SpanCount; got != 3 {
+		t.Errorf("transaction SpanCount = %d, want 3", got)
+	}
+
+	// Events sent after Close are dropped.
+	transport.SendEvent(NewEvent())
+	if files := readEnvelopeFiles(t, dir); len(files) != 1 || len(files[0]) != 2 {
+		t.Error("expected no envelopes to be written after Close")
+	}
+}
+
+func TestFileTransport_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	aggregator := report.NewAggregator()
+	transport := NewFileTransport(dir)
+	transport.MaxFileSize = 1
+	transport.MaxFiles = 2
+	transport.recorder = aggregator
+	transport.Configure(ClientOptions{})
+	defer transport.Close()
+
+	for _, message := range []string{"first", "second", "third", "fourth"} {
+		event := NewEvent()
+		event.Message = message
+		transport.SendEvent(event)
+	}
+
+	files := readEnvelopeFiles(t, dir)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+	for i, want := range []string{"third", "fourth"} {
+		if len(files[i]) != 1 || !strings.Contains(string(files[i][0].Items[0].Payload), want) {
+			t.Errorf("file %d does not contain the %s event", i, want)
+		}
+	}
+
+	r := aggregator.TakeReport()
+	if r == nil || len(r.DiscardedEvents) != 1 {
+		t.Fatalf("expected one discarded event outcome, got %v", r)
+	}
+	want := report.DiscardedEvent{Reason: report.ReasonCacheOverflow, Category: ratelimit.CategoryError, Quantity: 2}
+	if got := r.DiscardedEvents[0]; got != want {
+		t.Errorf("discarded = %+v, want %+v", got, want)
+	}
+}
+
+func TestFileTransport_EnvelopeTransport(t *testing.T) {
+	dir := t.TempDir()
+	transport := NewFileTransport(dir)
+	client, err := NewClient(ClientOptions{
+		Dsn:               "https://key@sentry.io/42",
+		EnvelopeTransport: transport,
+		Integrations: func([]Integration) []Integration {
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	ctx := SetHubOnContext(context.Background(), NewHub(client, NewScope()))
+
+	client.CaptureMessage("offline", nil, nil)
+	NewLogger(ctx).Info().Emit("log")
+	client.Flush(time.Second)
+	client.Close()
+
+	var types []protocol.EnvelopeItemType
+	for _, file := range readEnvelopeFiles(t, dir) {
+		for _, envelope := range file {
+			if envelope.Header.Dsn == nil || envelope.Header.Dsn.GetProjectID() != "42" {
+				t.Errorf("Dsn = %v, want project 42", envelope.Header.Dsn)
+			}
+			for _, item := range envelope.Items {
+				if item.Header.Type != protocol.EnvelopeItemTypeClientReport {
+					types = append(types, item.Header.Type)
+				}
+			}
+		}
+	}
+	if len(types) != 2 || !slices.Contains(types, protocol.EnvelopeItemTypeEvent) || !slices.Contains(types, protocol.EnvelopeItemTypeLog) {
+		t.Errorf("item types = %v, want event and log", types)
+	}
+}
+
+func TestFileTransport_FlushDeadline(t *testing.T) {
+	transport := NewFileTransport(t.TempDir())
+	transport.Configure(ClientOptions{})
+	defer transport.Close()
+	transport.SendEvent(NewEvent())
+
+	// A write in progress holds the lock.
+	transport.mu.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if transport.FlushWithContext(ctx) {
+		t.Error("FlushWithContext returned true before the file was synced")
+	}
+	if transport.Flush(10 * time.Millisecond) {
+		t.Error("Flush returned true before the file was synced")
+	}
+	transport.mu.Unlock()
+
+	if !transport.Flush(time.Second) {
+		t.Error("Flush failed")
+	}
+}
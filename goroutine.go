@@ -0,0 +1,223 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// goRepanicFlushTimeout bounds how long a goroutine that is about to repanic
+// waits for its panic to be sent to Sentry.
+const goRepanicFlushTimeout = 2 * time.Second
+
+// GoOption configures goroutines started by Go and Group.
+type GoOption func(*goOptions)
+
+type goOptions struct {
+	repanic     bool
+	spanOptions []SpanOption
+}
+
+// WithRepanic makes a goroutine started by Go or Group panic again after the
+// panic was reported to Sentry and its span was finished. Before panicking
+// again, the goroutine waits for the report to be sent, as the panic usually
+// terminates the program.
+func WithRepanic() GoOption {
+	return func(o *goOptions) {
+		o.repanic = true
+	}
+}
+
+// WithGoSpanOptions sets the options of the span started for each goroutine.
+func WithGoSpanOptions(options ...SpanOption) GoOption {
+	return func(o *goOptions) {
+		o.spanOptions = append(o.spanOptions, options...)
+	}
+}
+
+// PanicError is the error returned for a goroutine started by Group that
+// panicked, unless the goroutine was configured WithRepanic.
+type PanicError struct {
+	// Value is the value the goroutine panicked with.
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("sentry: goroutine panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Go runs fn in a new goroutine. The goroutine gets a clone of the hub of ctx
+// and a child span of the span of ctx with the given operation, both available
+// on the context passed to fn.
+//
+// A panic in fn is reported to Sentry instead of crashing the program, unless
+// WithRepanic is given. The span is finished when fn returns. A returned error
+// sets the status of the span, otherwise it defaults to SpanStatusOK.
+func Go(ctx context.Context, op string, fn func(ctx context.Context) error, options ...GoOption) {
+	opts := newGoOptions(options)
+	go func() {
+		_ = runGoroutine(ctx, op, fn, opts)
+	}()
+}
+
+func newGoOptions(options []GoOption) *goOptions {
+	opts := &goOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// runGoroutine runs fn on its own hub and span, and recovers from and reports
+// a panic in fn.
+func runGoroutine(ctx context.Context, op string, fn func(ctx context.Context) error, opts *goOptions) (err error) {
+	hub := hubFromContext(ctx).Clone()
+	span := StartSpan(SetHubOnContext(ctx, hub), op, opts.spanOptions...)
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		span.Status = SpanStatusInternalError
+		hub.RecoverWithContext(span.Context(), r)
+		span.Finish()
+		if opts.repanic {
+			hub.Flush(goRepanicFlushTimeout)
+			panic(r)
+		}
+		err = &PanicError{Value: r}
+	}()
+
+	err = fn(span.Context())
+	if err != nil || span.Status == SpanStatusUndefined {
+		span.Status = errorSpanStatus(err)
+	}
+	span.Finish()
+	return err
+}
+
+// errorSpanStatus returns the status of a span whose operation returned err.
+func errorSpanStatus(err error) SpanStatus {
+	switch {
+	case err == nil:
+		return SpanStatusOK
+	case errors.Is(err, context.Canceled):
+		return SpanStatusCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return SpanStatusDeadlineExceeded
+	default:
+		return SpanStatusInternalError
+	}
+}
+
+// A Group runs goroutines like Go and waits for them to complete. It mirrors
+// the API of golang.org/x/sync/errgroup.Group, so that it can replace it.
+//
+// The zero value is not usable, create a Group with NewGroup.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	op     string
+	opts   *goOptions
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	errOnce sync.Once
+	err     error
+}
+
+// NewGroup returns a Group whose goroutines start spans with the given
+// operation as children of the span of ctx, and a context derived from ctx
+// that is canceled when a goroutine of the group returns an error or when
+// Wait returns, whichever occurs first.
+func NewGroup(ctx context.Context, op string, options ...GoOption) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+		op:     op,
+		opts:   newGoOptions(options),
+	}, ctx
+}
+
+// SetLimit limits the number of goroutines of the group that run at the same
+// time to n. A negative value means no limit.
+//
+// SetLimit must not be called while goroutines of the group are running.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("sentry: modify limit while %v goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls fn in a new goroutine of the group, blocking until the goroutine
+// can be started without exceeding the limit of the group.
+func (g *Group) Go(fn func() error) {
+	g.GoContext(func(context.Context) error { return fn() })
+}
+
+// GoContext is like Go, but passes fn the context carrying the hub and span
+// of the goroutine.
+func (g *Group) GoContext(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(fn)
+}
+
+// TryGo calls fn in a new goroutine of the group only if this does not exceed
+// the limit of the group. The returned value indicates whether fn was started.
+func (g *Group) TryGo(fn func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(func(context.Context) error { return fn() })
+	return true
+}
+
+func (g *Group) start(fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		if err := runGoroutine(g.ctx, g.op, fn, g.opts); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel(err)
+			})
+		}
+	}()
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// Wait blocks until all goroutines of the group have returned, and returns
+// the first error returned by one of them, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
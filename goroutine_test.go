@@ -0,0 +1,224 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGoroutineTestContext() (context.Context, *MockTransport) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+	return ctx, transport
+}
+
+func transactionOf(t *testing.T, events []*Event) *Event {
+	t.Helper()
+	for _, event := range events {
+		if event.Type == transactionType {
+			return event
+		}
+	}
+	require.FailNow(t, "no transaction sent")
+	return nil
+}
+
+func TestGo(t *testing.T) {
+	ctx, _ := newGoroutineTestContext()
+	transaction := StartTransaction(ctx, "transaction")
+
+	type result struct {
+		hub       *Hub
+		span      *Span
+		scopeSpan *Span
+	}
+	results := make(chan result, 1)
+	Go(transaction.Context(), "worker", func(ctx context.Context) error {
+		hub := GetHubFromContext(ctx)
+		results <- result{hub, SpanFromContext(ctx), hub.Scope().GetSpan()}
+		return nil
+	})
+	got := <-results
+
+	assert.NotSame(t, GetHubFromContext(ctx), got.hub)
+	assert.Same(t, got.span, got.scopeSpan)
+	assert.Equal(t, transaction.TraceID, got.span.TraceID)
+	assert.Equal(t, transaction.SpanID, got.span.ParentSpanID)
+	assert.Equal(t, "worker", got.span.Op)
+}
+
+func TestGo_RecoversPanic(t *testing.T) {
+	ctx, transport := newGoroutineTestContext()
+
+	Go(ctx, "worker", func(context.Context) error {
+		panic("boom")
+	})
+
+	assert.Eventually(t, func() bool {
+		for _, event := range transport.Events() {
+			if event.Message == "boom" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond)
+}
+
+func TestRunGoroutine(t *testing.T) {
+	ctx, transport := newGoroutineTestContext()
+	transaction := StartTransaction(ctx, "transaction")
+
+	errFailed := errors.New("failed")
+	err := runGoroutine(transaction.Context(), "ok", func(context.Context) error {
+		return nil
+	}, newGoOptions(nil))
+	require.NoError(t, err)
+	err = runGoroutine(transaction.Context(), "failed", func(context.Context) error {
+		return errFailed
+	}, newGoOptions(nil))
+	require.ErrorIs(t, err, errFailed)
+	err = runGoroutine(transaction.Context(), "not found", func(ctx context.Context) error {
+		SpanFromContext(ctx).Status = SpanStatusNotFound
+		return nil
+	}, newGoOptions(nil))
+	require.NoError(t, err)
+	err = runGoroutine(transaction.Context(), "panicked", func(context.Context) error {
+		panic("boom")
+	}, newGoOptions(nil))
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	transaction.Finish()
+
+	var exceptions []*Event
+	for _, event := range transport.Events() {
+		if event.Type != transactionType {
+			exceptions = append(exceptions, event)
+		}
+	}
+	require.Len(t, exceptions, 1)
+	assert.Equal(t, "boom", exceptions[0].Message)
+	assert.Equal(t, transaction.TraceID, exceptions[0].Contexts["trace"]["trace_id"])
+
+	event := transactionOf(t, transport.Events())
+	statuses := map[string]SpanStatus{}
+	for _, span := range event.Spans {
+		assert.Equal(t, transaction.SpanID, span.ParentSpanID)
+		assert.False(t, span.EndTime.IsZero())
+		statuses[span.Op] = span.Status
+	}
+	assert.Equal(t, map[string]SpanStatus{
+		"ok":        SpanStatusOK,
+		"failed":    SpanStatusInternalError,
+		"not found": SpanStatusNotFound,
+		"panicked":  SpanStatusInternalError,
+	}, statuses)
+}
+
+func TestRunGoroutine_Repanic(t *testing.T) {
+	ctx, transport := newGoroutineTestContext()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = runGoroutine(ctx, "worker", func(context.Context) error {
+			panic("boom")
+		}, newGoOptions([]GoOption{WithRepanic()}))
+	})
+	require.NotEmpty(t, transport.Events())
+	assert.Equal(t, "boom", transport.Events()[0].Message)
+}
+
+func TestErrorSpanStatus(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want SpanStatus
+	}{
+		"nil":               {nil, SpanStatusOK},
+		"canceled":          {context.Canceled, SpanStatusCanceled},
+		"deadline exceeded": {context.DeadlineExceeded, SpanStatusDeadlineExceeded},
+		"wrapped":           {errors.Join(errors.New("query"), context.DeadlineExceeded), SpanStatusDeadlineExceeded},
+		"other":             {errors.New("failed"), SpanStatusInternalError},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorSpanStatus(tt.err))
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	ctx, transport := newGoroutineTestContext()
+	transaction := StartTransaction(ctx, "transaction")
+
+	errFailed := errors.New("failed")
+	g, gctx := NewGroup(transaction.Context(), "worker")
+	g.Go(func() error { return nil })
+	g.GoContext(func(ctx context.Context) error {
+		SpanFromContext(ctx).SetData("worker", "failing")
+		return errFailed
+	})
+	g.Go(func() error { panic("boom") })
+
+	err := g.Wait()
+	require.Error(t, err)
+	assert.ErrorIs(t, gctx.Err(), context.Canceled)
+	if !errors.Is(err, errFailed) {
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+	}
+
+	transaction.Finish()
+	event := transactionOf(t, transport.Events())
+	require.Len(t, event.Spans, 3)
+	statuses := map[SpanStatus]int{}
+	for _, span := range event.Spans {
+		assert.Equal(t, transaction.SpanID, span.ParentSpanID)
+		statuses[span.Status]++
+		if span.Data["worker"] == "failing" {
+			assert.Equal(t, SpanStatusInternalError, span.Status)
+		}
+	}
+	assert.Equal(t, map[SpanStatus]int{SpanStatusOK: 1, SpanStatusInternalError: 2}, statuses)
+}
+
+func TestGroup_Wait(t *testing.T) {
+	ctx, _ := newGoroutineTestContext()
+
+	g, gctx := NewGroup(ctx, "worker")
+	var n atomic.Int32
+	for range 10 {
+		g.Go(func() error {
+			n.Add(1)
+			return nil
+		})
+	}
+	require.NoError(t, g.Wait())
+	assert.Equal(t, int32(10), n.Load())
+	assert.ErrorIs(t, gctx.Err(), context.Canceled)
+}
+
+func TestGroup_SetLimit(t *testing.T) {
+	ctx, _ := newGoroutineTestContext()
+
+	g, _ := NewGroup(ctx, "worker")
+	g.SetLimit(1)
+	release := make(chan struct{})
+	g.Go(func() error {
+		<-release
+		return nil
+	})
+	assert.False(t, g.TryGo(func() error { return nil }))
+	close(release)
+	require.NoError(t, g.Wait())
+	assert.True(t, g.TryGo(func() error { return nil }))
+	require.NoError(t, g.Wait())
+}
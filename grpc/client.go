@@ -37,6 +37,12 @@ func createOrUpdateMetadata(ctx context.Context, span *sentry.Span) context.Cont
 	md, _ := metadata.FromOutgoingContext(ctx)
 	md = md.Copy()
 	md.Set(sentry.SentryTraceHeader, span.ToSentryTrace())
+	if propagateTraceparent(ctx) {
+		md.Set(sentry.TraceparentHeader, span.ToTraceparent())
+		if tracestate := span.ToTracestate(); tracestate != "" {
+			md.Set(sentry.TracestateHeader, tracestate)
+		}
+	}
 
 	existingBaggage := strings.Join(md.Get(sentry.SentryBaggageHeader), ",")
 	mergedBaggage, err := sentry.MergeBaggage(existingBaggage, span.ToBaggage())
@@ -47,6 +53,16 @@ func createOrUpdateMetadata(ctx context.Context, span *sentry.Span) context.Cont
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
+// propagateTraceparent reports whether the client of the hub on the context
+// propagates the W3C traceparent header.
+func propagateTraceparent(ctx context.Context) bool {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil || hub.Client() == nil {
+		return false
+	}
+	return hub.Client().Options().PropagateTraceparent
+}
+
 func finishSpan(span *sentry.Span, err error) {
 	setRPCStatus(span, err)
 	span.Finish()
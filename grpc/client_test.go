@@ -3,6 +3,7 @@ package sentrygrpc_test
 import (
 	"context"
 	"io"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -116,6 +117,7 @@ func TestUnaryClientInterceptor(t *testing.T) {
 				require.True(t, ok)
 				assert.Contains(t, md, sentry.SentryTraceHeader)
 				assert.Contains(t, md, sentry.SentryBaggageHeader)
+				assert.NotContains(t, md, sentry.TraceparentHeader)
 				assert.Contains(t, md, "existing")
 				return nil
 			},
@@ -146,6 +148,39 @@ func TestUnaryClientInterceptor(t *testing.T) {
 	}
 }
 
+func TestUnaryClientInterceptor_PropagatesTraceparent(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	require.NoError(t, sentry.Init(sentry.ClientOptions{
+		Transport:            transport,
+		EnableTracing:        true,
+		TracesSampleRate:     1.0,
+		PropagateTraceparent: true,
+	}))
+	interceptor := sentrygrpc.UnaryClientInterceptor()
+
+	hub := sentry.CurrentHub().Clone()
+	incoming := http.Header{}
+	incoming.Set(sentry.TraceparentHeader, "00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01")
+	incoming.Set(sentry.TracestateHeader, "vendor=value")
+	transaction := sentry.StartTransaction(
+		sentry.SetHubOnContext(context.Background(), hub),
+		"server",
+		sentry.ContinueTraceFromHeaders(hub, incoming.Get),
+	)
+	defer transaction.Finish()
+
+	err := interceptor(transaction.Context(), "/test.TestService/Method", struct{}{}, struct{}{}, nil, func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		require.True(t, ok)
+		span := sentry.SpanFromContext(ctx)
+		assert.Equal(t, []string{span.ToSentryTrace()}, md.Get(sentry.SentryTraceHeader))
+		assert.Equal(t, []string{"00-bc6d53f15eb88f4320054569b8c553d4-" + span.SpanID.String() + "-01"}, md.Get(sentry.TraceparentHeader))
+		assert.Equal(t, []string{"vendor=value"}, md.Get(sentry.TracestateHeader))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestUnaryClientInterceptor_ReplacesExistingTraceHeaders(t *testing.T) {
 	transport := initMockTransport(t)
 	interceptor := sentrygrpc.UnaryClientInterceptor()
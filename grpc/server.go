@@ -69,14 +69,22 @@ func hubFromServerContext(ctx context.Context) *sentry.Hub {
 	return hub
 }
 
-func traceHeadersFromContext(ctx context.Context) (metadata.MD, string, string) {
-	md, _ := metadata.FromIncomingContext(ctx)
-	return md, getFirstHeader(md, sentry.SentryTraceHeader), getFirstHeader(md, sentry.SentryBaggageHeader)
+// SpanLinkFromMetadata returns a link to the span that sent the given
+// metadata, from its sentry-trace or traceparent entry. Handlers that process
+// messages produced in other traces, such as a batch of queued messages, can
+// use it to link their spans to the producers. The returned value indicates
+// whether a link could be extracted.
+func SpanLinkFromMetadata(md metadata.MD, attributes map[string]any) (sentry.SpanLink, bool) {
+	return sentry.SpanLinkFromHeaders(
+		getFirstHeader(md, sentry.SentryTraceHeader),
+		getFirstHeader(md, sentry.TraceparentHeader),
+		attributes,
+	)
 }
 
 func startServerTransaction(ctx context.Context, fullMethod string) (context.Context, *sentry.Hub, *sentry.Span) {
 	hub := hubFromServerContext(ctx)
-	md, sentryTraceHeader, sentryBaggageHeader := traceHeadersFromContext(ctx)
+	md, _ := metadata.FromIncomingContext(ctx)
 	name, service, method := parseGRPCMethod(fullMethod)
 
 	setScopeMetadata(hub, name, md)
@@ -84,7 +92,9 @@ func startServerTransaction(ctx context.Context, fullMethod string) (context.Con
 	transaction := sentry.StartTransaction(
 		sentry.SetHubOnContext(ctx, hub),
 		name,
-		sentry.ContinueTrace(hub, sentryTraceHeader, sentryBaggageHeader),
+		sentry.ContinueTraceFromHeaders(hub, func(key string) string {
+			return getFirstHeader(md, key)
+		}),
 		sentry.WithOpName(defaultServerOperationName),
 		sentry.WithDescription(name),
 		sentry.WithTransactionSource(sentry.SourceRoute),
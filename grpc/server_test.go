@@ -81,6 +81,28 @@ func TestUnaryServerInterceptor(t *testing.T) {
 	}
 }
 
+func TestUnaryServerInterceptor_ContinuesTraceparent(t *testing.T) {
+	initMockTransport(t)
+	interceptor := sentrygrpc.UnaryServerInterceptor(sentrygrpc.ServerOptions{})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		sentry.TraceparentHeader, "00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01",
+		sentry.TracestateHeader, "vendor=value",
+	))
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{
+		FullMethod: "/test.TestService/Method",
+	}, func(ctx context.Context, _ any) (any, error) {
+		transaction := sentry.TransactionFromContext(ctx)
+		require.NotNil(t, transaction)
+		assert.Equal(t, "bc6d53f15eb88f4320054569b8c553d4", transaction.TraceID.String())
+		assert.Equal(t, "b72fa28504b07285", transaction.ParentSpanID.String())
+		assert.Equal(t, sentry.SampledTrue, transaction.Sampled)
+		assert.Equal(t, "vendor=value", transaction.ToTracestate())
+		return struct{}{}, nil
+	})
+	require.NoError(t, err)
+}
+
 func TestUnaryServerInterceptor_ScrubsSensitiveMetadata(t *testing.T) {
 	transport := initMockTransport(t)
 	interceptor := sentrygrpc.UnaryServerInterceptor(sentrygrpc.ServerOptions{})
@@ -259,3 +281,29 @@ func TestStreamServerInterceptor_Panic(t *testing.T) {
 		})
 	}
 }
+
+func TestSpanLinkFromMetadata(t *testing.T) {
+	attributes := map[string]any{"messaging.message.id": "42"}
+
+	link, ok := sentrygrpc.SpanLinkFromMetadata(metadata.Pairs(
+		sentry.TraceparentHeader, "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01",
+	), attributes)
+	require.True(t, ok)
+	assert.Equal(t, "d49d9bf66f13450b81f65bc51cf49c03", link.TraceID.String())
+	assert.Equal(t, "1cc4b26ab9094ef0", link.SpanID.String())
+	assert.Equal(t, sentry.SampledTrue, link.Sampled)
+	assert.Equal(t, attributes, link.Attributes)
+
+	link, ok = sentrygrpc.SpanLinkFromMetadata(metadata.Pairs(
+		sentry.SentryTraceHeader, "bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-0",
+		sentry.TraceparentHeader, "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01",
+	), nil)
+	require.True(t, ok)
+	assert.Equal(t, "bc6d53f15eb88f4320054569b8c553d4", link.TraceID.String())
+	assert.Equal(t, sentry.SampledFalse, link.Sampled)
+
+	_, ok = sentrygrpc.SpanLinkFromMetadata(metadata.Pairs(sentry.TraceparentHeader, "00-xxx-malformed-01"), nil)
+	assert.False(t, ok)
+	_, ok = sentrygrpc.SpanLinkFromMetadata(nil, nil)
+	assert.False(t, ok)
+}
@@ -1,9 +1,106 @@
 package sentry
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/testutils"
 )
 
 var assertEqual = testutils.AssertEqual
 var assertNotEqual = testutils.AssertNotEqual
 var assertBaggageStringsEqual = testutils.AssertBaggageStringsEqual
+
+// receivedEvent is an event or transaction received by an envelopeServer.
+type receivedEvent struct {
+	// message is the message of an event or the name of a transaction.
+	message   string
+	dsn       string
+	publicKey string
+}
+
+// envelopeServer is a fake Sentry endpoint that records the events and
+// transactions it receives.
+type envelopeServer struct {
+	url string
+	dsn string
+
+	mu     sync.Mutex
+	events []receivedEvent
+}
+
+// newEnvelopeServer starts an envelopeServer whose DSN has the given public
+// key and project ID. If respond is not nil, it writes the response to every
+// request.
+func newEnvelopeServer(t *testing.T, publicKey, projectID string, respond func(w http.ResponseWriter)) *envelopeServer {
+	t.Helper()
+	s := &envelopeServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		envelope, err := protocol.ParseEnvelope(body)
+		if err != nil {
+			t.Errorf("invalid envelope: %v", err)
+			return
+		}
+		for _, item := range envelope.Items {
+			if item.Header.Type != protocol.EnvelopeItemTypeEvent && item.Header.Type != protocol.EnvelopeItemTypeTransaction {
+				continue
+			}
+			var event struct {
+				Message     string `json:"message"`
+				Transaction string `json:"transaction"`
+			}
+			if json.Unmarshal(item.Payload, &event) != nil {
+				continue
+			}
+			received := receivedEvent{message: event.Message + event.Transaction, publicKey: envelope.Header.Trace["public_key"]}
+			if envelope.Header.Dsn != nil {
+				received.dsn = envelope.Header.Dsn.String()
+			}
+			s.mu.Lock()
+			s.events = append(s.events, received)
+			s.mu.Unlock()
+		}
+		if respond != nil {
+			respond(w)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	s.url = srv.URL
+	s.dsn = strings.Replace(srv.URL, "//", "//"+publicKey+"@", 1) + "/" + projectID
+	return s
+}
+
+// received returns the events received so far.
+func (s *envelopeServer) received() []receivedEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedEvent(nil), s.events...)
+}
+
+// messages returns the messages and transaction names received so far.
+func (s *envelopeServer) messages() []string {
+	var messages []string
+	for _, event := range s.received() {
+		messages = append(messages, event.message)
+	}
+	return messages
+}
+
+// forEachPipeline runs test as a subtest with the telemetry buffer enabled and
+// with DisableTelemetryBuffer.
+func forEachPipeline(t *testing.T, test func(t *testing.T, disableTelemetryBuffer bool)) {
+	t.Helper()
+	for _, disableTelemetryBuffer := range []bool{false, true} {
+		t.Run(fmt.Sprintf("DisableTelemetryBuffer=%v", disableTelemetryBuffer), func(t *testing.T) {
+			test(t, disableTelemetryBuffer)
+		})
+	}
+}
@@ -406,3 +406,39 @@ func TestIntegration(t *testing.T) {
 		t.Fatalf("Transaction status codes mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestIntegration_ContinuesTraceparent(t *testing.T) {
+	err := sentry.Init(sentry.ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var transaction *sentry.Span
+	handler := sentryhttp.New(sentryhttp.Options{}).Handle(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		transaction = sentry.TransactionFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Traceparent", "00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01")
+	req.Header.Set("Tracestate", "vendor=value")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if transaction == nil {
+		t.Fatal("no transaction in the request context")
+	}
+	if got, want := transaction.TraceID.String(), "bc6d53f15eb88f4320054569b8c553d4"; got != want {
+		t.Errorf("TraceID = %s, want %s", got, want)
+	}
+	if got, want := transaction.ParentSpanID.String(), "b72fa28504b07285"; got != want {
+		t.Errorf("ParentSpanID = %s, want %s", got, want)
+	}
+	if transaction.Sampled != sentry.SampledTrue {
+		t.Errorf("Sampled = %v, want %v", transaction.Sampled, sentry.SampledTrue)
+	}
+	if got, want := transaction.ToTracestate(), "vendor=value"; got != want {
+		t.Errorf("ToTracestate() = %q, want %q", got, want)
+	}
+}
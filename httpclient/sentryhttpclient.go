@@ -34,6 +34,19 @@ func WithTracePropagationTargets(targets []string) SentryRoundTripTracerOption {
 	}
 }
 
+// SpanLinkFromHeader returns a link to the span that sent the given HTTP
+// headers, from their sentry-trace or traceparent value. Consumers that fetch
+// messages produced in other traces, along with their trace headers, can use
+// it to link their spans to the producers. The returned value indicates
+// whether a link could be extracted.
+func SpanLinkFromHeader(header http.Header, attributes map[string]interface{}) (sentry.SpanLink, bool) {
+	return sentry.SpanLinkFromHeaders(
+		header.Get(sentry.SentryTraceHeader),
+		header.Get(sentry.TraceparentHeader),
+		attributes,
+	)
+}
+
 // NewSentryRoundTripper provides a wrapper to existing http.RoundTripper to have required span data and trace headers for outgoing HTTP requests.
 //
 //   - If `nil` is passed to `originalRoundTripper`, it will use http.DefaultTransport instead.
@@ -105,6 +118,9 @@ func (s *SentryRoundTripper) RoundTrip(request *http.Request) (*http.Response, e
 			request.Header.Add(sentry.SentryTraceHeader, hub.GetTraceparent())
 			if s.propagateTraceparent {
 				request.Header.Add(sentry.TraceparentHeader, hub.GetTraceparentW3C())
+				if tracestate := hub.GetTracestate(); tracestate != "" {
+					request.Header.Add(sentry.TracestateHeader, tracestate)
+				}
 			}
 		}
 
@@ -128,6 +144,9 @@ func (s *SentryRoundTripper) RoundTrip(request *http.Request) (*http.Response, e
 	request.Header.Add(sentry.SentryTraceHeader, span.ToSentryTrace())
 	if s.propagateTraceparent {
 		request.Header.Add(sentry.TraceparentHeader, span.ToTraceparent())
+		if tracestate := span.ToTracestate(); tracestate != "" {
+			request.Header.Add(sentry.TracestateHeader, tracestate)
+		}
 	}
 
 	response, err := s.originalRoundTripper.RoundTrip(request)
@@ -299,8 +299,8 @@ func TestIntegration(t *testing.T) {
 		cmpopts.IgnoreFields(
 			sentry.Span{},
 			"TraceID", "SpanID", "ParentSpanID", "StartTime", "EndTime",
-			"mu", "parent", "sampleRate", "ctx", "dynamicSamplingContext", "recorder", "finishOnce", "contexts",
-			"explicitSampled", "serializedTags", "serializedData", "serializationSafe",
+			"mu", "parent", "sampleRate", "sampleRand", "ctx", "dynamicSamplingContext", "recorder", "streamed", "finishOnce", "contexts",
+			"explicitSampled", "downsampled", "serializedTags", "serializedData", "serializationSafe", "serializedLinks", "measurements", "autoFinish", "profile", "tracestate",
 		),
 	}
 	for i, tt := range tests {
@@ -389,8 +389,8 @@ func TestIntegration_GlobalClientOptions(t *testing.T) {
 		cmpopts.IgnoreFields(
 			sentry.Span{},
 			"TraceID", "SpanID", "ParentSpanID", "StartTime", "EndTime",
-			"mu", "parent", "sampleRate", "ctx", "dynamicSamplingContext", "recorder", "finishOnce", "contexts",
-			"explicitSampled", "serializedTags", "serializedData", "serializationSafe",
+			"mu", "parent", "sampleRate", "sampleRand", "ctx", "dynamicSamplingContext", "recorder", "streamed", "finishOnce", "contexts",
+			"explicitSampled", "downsampled", "serializedTags", "serializedData", "serializationSafe", "serializedLinks", "measurements", "autoFinish", "profile", "tracestate",
 		),
 	}
 
@@ -533,6 +533,62 @@ func TestPropagateTraceparentHeader(t *testing.T) {
 	}
 }
 
+func TestPropagateTracestateHeader(t *testing.T) {
+	err := sentry.Init(sentry.ClientOptions{
+		EnableTracing:        true,
+		TracesSampleRate:     1.0,
+		PropagateTraceparent: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	incoming := http.Header{}
+	incoming.Set("Traceparent", "00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01")
+	incoming.Set("Tracestate", "vendor=value")
+
+	tests := map[string]func(hub *sentry.Hub) context.Context{
+		"with parent span": func(hub *sentry.Hub) context.Context {
+			ctx := sentry.SetHubOnContext(context.Background(), hub)
+			transaction := sentry.StartTransaction(ctx, "server", sentry.ContinueTraceFromHeaders(hub, incoming.Get))
+			t.Cleanup(transaction.Finish)
+			return transaction.Context()
+		},
+		"without parent span": func(hub *sentry.Hub) context.Context {
+			sentry.ContinueTraceFromHeaders(hub, incoming.Get)
+			return sentry.SetHubOnContext(context.Background(), hub)
+		},
+	}
+	for name, newContext := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := newContext(sentry.NewHub(sentry.CurrentHub().Client(), sentry.NewScope()))
+			request, err := http.NewRequestWithContext(ctx, "GET", "https://example.com/foo", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{
+				Transport: sentryhttpclient.NewSentryRoundTripper(&noopRoundTripper{ExpectResponseStatus: 200}),
+			}
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			if got := response.Request.Header.Get("Tracestate"); got != "vendor=value" {
+				t.Errorf(`Unexpected "tracestate" header value, got %q want %q`, got, "vendor=value")
+			}
+			traceparent := response.Request.Header.Get("Traceparent")
+			if want := traceparentFromSentryTraceHeader(t, response.Request.Header.Get("Sentry-Trace")); traceparent != want {
+				t.Errorf(`Unexpected "traceparent" header value, got %q want %q`, traceparent, want)
+			}
+			if !strings.HasPrefix(traceparent, "00-bc6d53f15eb88f4320054569b8c553d4-") || !strings.HasSuffix(traceparent, "-01") {
+				t.Errorf(`Expected "traceparent" header to continue the incoming trace, got %q`, traceparent)
+			}
+		})
+	}
+}
+
 func TestRoundTripDoesNotMutateCallerRequest(t *testing.T) {
 	sentryClient, err := sentry.NewClient(sentry.ClientOptions{
 		EnableTracing:    true,
@@ -620,3 +676,32 @@ func traceparentFromSentryTraceHeader(t *testing.T, sentryTrace string) string {
 
 	return fmt.Sprintf("00-%s-%s-%s", traceParentContext.TraceID.String(), traceParentContext.ParentSpanID.String(), traceFlags)
 }
+
+func TestSpanLinkFromHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set(sentry.TraceparentHeader, "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-00")
+	link, ok := sentryhttpclient.SpanLinkFromHeader(header, map[string]interface{}{"messaging.message.id": "42"})
+	if !ok {
+		t.Fatal("expected a link from the traceparent header")
+	}
+	want := map[string]interface{}{
+		"trace_id":   "d49d9bf66f13450b81f65bc51cf49c03",
+		"span_id":    "1cc4b26ab9094ef0",
+		"sampled":    sentry.SampledFalse,
+		"attributes": map[string]interface{}{"messaging.message.id": "42"},
+	}
+	got := map[string]interface{}{
+		"trace_id":   link.TraceID.String(),
+		"span_id":    link.SpanID.String(),
+		"sampled":    link.Sampled,
+		"attributes": link.Attributes,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Link mismatch (-want +got):\n%s", diff)
+	}
+
+	header.Set(sentry.TraceparentHeader, "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0--")
+	if _, ok := sentryhttpclient.SpanLinkFromHeader(header, nil); ok {
+		t.Error("expected no link from a malformed traceparent header")
+	}
+}
@@ -394,18 +394,41 @@ func (hub *Hub) GetTraceparent() string {
 		return span.ToSentryTrace()
 	}
 	propagationContext := scope.propagationContextSnapshot()
+	switch propagationContext.Sampled {
+	case SampledTrue:
+		return fmt.Sprintf("%s-%s-1", propagationContext.TraceID, propagationContext.SpanID)
+	case SampledFalse:
+		return fmt.Sprintf("%s-%s-0", propagationContext.TraceID, propagationContext.SpanID)
+	}
 	return fmt.Sprintf("%s-%s", propagationContext.TraceID, propagationContext.SpanID)
 }
 
 // GetTraceparentW3C returns the current traceparent string in W3C format.
 // This is intended for propagation to downstream services that expect the W3C header.
+// It identifies the same trace and span as GetTraceparent, and the trace is
+// flagged as sampled if GetTraceparent reports it as sampled.
 func (hub *Hub) GetTraceparentW3C() string {
 	scope := hub.Scope()
 	if span := scope.GetSpan(); span != nil {
 		return span.ToTraceparent()
 	}
 	propagationContext := scope.propagationContextSnapshot()
-	return fmt.Sprintf("00-%s-%s-00", propagationContext.TraceID, propagationContext.SpanID)
+	traceFlags := "00"
+	if propagationContext.Sampled == SampledTrue {
+		traceFlags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", propagationContext.TraceID, propagationContext.SpanID, traceFlags)
+}
+
+// GetTracestate returns the W3C tracestate string received with the current
+// trace, to be propagated along with GetTraceparentW3C. It is empty unless
+// the trace was continued from a tracestate header.
+func (hub *Hub) GetTracestate() string {
+	scope := hub.Scope()
+	if span := scope.GetSpan(); span != nil {
+		return span.ToTracestate()
+	}
+	return scope.propagationContextSnapshot().Tracestate
 }
 
 // GetBaggage returns the current Sentry baggage string, to be used as a HTTP header value
@@ -3,6 +3,7 @@ package sentry
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -157,6 +158,9 @@ type meterOptions struct {
 	unit       string
 	scope      *Scope
 	attributes map[string]attribute.Value
+	traceID    TraceID
+	spanID     SpanID
+	timestamp  time.Time
 }
 
 // WithUnit sets the unit for the metric (e.g., "millisecond", "byte").
@@ -173,6 +177,24 @@ func WithScopeOverride(scope *Scope) MeterOption {
 	}
 }
 
+// WithTrace links the metric to the given trace and span, instead of the
+// trace and span found in the context or in the scope of the meter. It is
+// ignored if traceID is zero.
+func WithTrace(traceID TraceID, spanID SpanID) MeterOption {
+	return func(o *meterOptions) {
+		o.traceID = traceID
+		o.spanID = spanID
+	}
+}
+
+// WithTimestamp sets the time the metric was recorded at, instead of the time
+// of the recording call.
+func WithTimestamp(timestamp time.Time) MeterOption {
+	return func(o *meterOptions) {
+		o.timestamp = timestamp
+	}
+}
+
 // WithAttributes sets attributes for the metric.
 func WithAttributes(attrs ...attribute.Builder) MeterOption {
 	return func(o *meterOptions) {
@@ -338,6 +360,15 @@ type Exception struct {
 // but which shouldn't get send to Sentry.
 type SDKMetaData struct {
 	dsc DynamicSamplingContext
+	// dsn is set for events that ClientOptions.EventRoutes send to a
+	// different project than the client's DSN.
+	dsn *protocol.Dsn
+	// serializedItems are the serialized Logs or Metrics of the event, if
+	// they were already serialized to split them into batches.
+	serializedItems []json.RawMessage
+	// transactionProfile is the profile of a transaction, sent in the
+	// envelope of the transaction.
+	transactionProfile *transactionProfile
 }
 
 // Contains information about how the name of the transaction was determined.
@@ -407,10 +438,11 @@ type Event struct {
 
 	// The fields below are only relevant for transactions.
 
-	Type            string           `json:"type,omitempty"`
-	StartTime       time.Time        `json:"start_timestamp,omitzero"`
-	Spans           []*Span          `json:"spans,omitempty"`
-	TransactionInfo *TransactionInfo `json:"transaction_info,omitempty"`
+	Type            string                 `json:"type,omitempty"`
+	StartTime       time.Time              `json:"start_timestamp,omitzero"`
+	Spans           []*Span                `json:"spans,omitempty"`
+	TransactionInfo *TransactionInfo       `json:"transaction_info,omitempty"`
+	Measurements    map[string]Measurement `json:"measurements,omitempty"`
 
 	// The fields below are only relevant for crons/check ins
 
@@ -467,10 +499,58 @@ func (e *Event) safeMarshal() (b []byte, err error) {
 	return json.Marshal(e)
 }
 
+// marshalWithinLimit marshals the event like safeMarshal. Errors and
+// transactions larger than maxSize are marshaled again with their oldest
+// breadcrumbs removed until they fit. If they do not fit even without
+// breadcrumbs, an error wrapping protocol.ErrTooLarge is returned.
+func (e *Event) marshalWithinLimit(maxSize int) ([]byte, error) {
+	body, err := e.safeMarshal()
+	if err != nil || len(body) <= maxSize {
+		return body, err
+	}
+	if e.Type == logEvent.Type || e.Type == traceMetricEvent.Type {
+		return body, nil
+	}
+
+	// Breadcrumbs are ordered from oldest to newest. Pre-serialized
+	// breadcrumbs have to be trimmed in their serialized form.
+	n := len(e.Breadcrumbs)
+	var serialized []json.RawMessage
+	if e.serializationSafe {
+		_ = json.Unmarshal(e.serializedBreadcrumbs, &serialized)
+		n = len(serialized)
+	}
+
+	trimmed := *e
+	for n > 0 {
+		// Keep the newer half.
+		n /= 2
+		if e.serializationSafe {
+			trimmed.serializedBreadcrumbs = nil
+			if n > 0 {
+				trimmed.serializedBreadcrumbs, _ = json.Marshal(serialized[len(serialized)-n:])
+			}
+		} else {
+			trimmed.Breadcrumbs = e.Breadcrumbs[len(e.Breadcrumbs)-n:]
+		}
+		if body, err = trimmed.safeMarshal(); err != nil {
+			return nil, err
+		}
+		if len(body) <= maxSize {
+			debuglog.Printf("Trimmed breadcrumbs of %s to %d to stay within %d bytes", eventDebugContext(e), n, maxSize)
+			return body, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: event is %d bytes, the limit is %d bytes", protocol.ErrTooLarge, len(body), maxSize)
+}
+
 // ToEnvelopeItem converts the Event to a Sentry envelope item.
 func (e *Event) ToEnvelopeItem() (item *protocol.EnvelopeItem, err error) {
-	eventBody, err := e.safeMarshal()
+	eventBody, err := e.marshalWithinLimit(protocol.DefaultLimits.MaxEventSize)
 	if err != nil {
+		if errors.Is(err, protocol.ErrTooLarge) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("could not encode event as JSON, skipping delivery: %w", err)
 	}
 
@@ -498,6 +578,13 @@ func (e *Event) ToEnvelope(header *protocol.EnvelopeHeader) (*protocol.Envelope,
 	}
 
 	envelope := protocol.NewEnvelope(header, item)
+	if e.sdkMetaData.transactionProfile != nil {
+		payload, err := e.marshalProfile()
+		if err != nil {
+			return nil, err
+		}
+		envelope.AddItem(protocol.NewEnvelopeItem(protocol.EnvelopeItemTypeProfile, payload))
+	}
 	for _, attachment := range e.Attachments {
 		attachmentItem := protocol.NewAttachmentItem(attachment.Filename, attachment.ContentType, attachment.Payload)
 		envelope.AddItem(attachmentItem)
@@ -515,11 +602,36 @@ func (e *Event) GetEventID() string {
 	return string(e.EventID)
 }
 
+// GetDsn returns the DSN the event is routed to, or nil if it is sent to the
+// client's DSN.
+func (e *Event) GetDsn() *protocol.Dsn {
+	return e.sdkMetaData.dsn
+}
+
 // GetSdkInfo returns SDK information for the envelope header.
 func (e *Event) GetSdkInfo() *protocol.SdkInfo {
 	return &e.Sdk
 }
 
+// GetTraceID returns the trace ID of the event, which the telemetry buffer uses
+// to keep the items of a trace together.
+func (e *Event) GetTraceID() (string, bool) {
+	if traceID := e.sdkMetaData.dsc.Entries["trace_id"]; traceID != "" {
+		return traceID, true
+	}
+	switch traceID := e.Contexts["trace"]["trace_id"].(type) {
+	case TraceID:
+		if traceID != zeroTraceID {
+			return traceID.String(), true
+		}
+	case string:
+		if traceID != "" {
+			return traceID, true
+		}
+	}
+	return "", false
+}
+
 // GetDynamicSamplingContext returns trace context for the envelope header.
 func (e *Event) GetDynamicSamplingContext() map[string]string {
 	trace := make(map[string]string)
@@ -581,12 +693,20 @@ func (e *Event) defaultMarshalJSON() ([]byte, error) {
 		return json.Marshal(struct{ *event }{(*event)(e)})
 	}
 	// metrics and logs should be serialized under the same `items` json field.
+	type serializedItemsEvent struct {
+		*event
+		Items []json.RawMessage `json:"items,omitempty"`
+		Type  json.RawMessage   `json:"type,omitempty"`
+	}
 	if e.Type == logEvent.Type {
 		type logEvent struct {
 			*event
 			Items []Log           `json:"items,omitempty"`
 			Type  json.RawMessage `json:"type,omitempty"`
 		}
+		if e.sdkMetaData.serializedItems != nil {
+			return json.Marshal(serializedItemsEvent{event: (*event)(e), Items: e.sdkMetaData.serializedItems})
+		}
 		return json.Marshal(logEvent{event: (*event)(e), Items: e.Logs})
 	}
 
@@ -596,6 +716,9 @@ func (e *Event) defaultMarshalJSON() ([]byte, error) {
 			Items []Metric        `json:"items,omitempty"`
 			Type  json.RawMessage `json:"type,omitempty"`
 		}
+		if e.sdkMetaData.serializedItems != nil {
+			return json.Marshal(serializedItemsEvent{event: (*event)(e), Items: e.sdkMetaData.serializedItems})
+		}
 		return json.Marshal(metricEvent{event: (*event)(e), Items: e.Metrics})
 	}
 
@@ -612,6 +735,7 @@ func (e *Event) defaultMarshalJSON() ([]byte, error) {
 		StartTime       json.RawMessage `json:"start_timestamp,omitempty"`
 		Spans           json.RawMessage `json:"spans,omitempty"`
 		TransactionInfo json.RawMessage `json:"transaction_info,omitempty"`
+		Measurements    json.RawMessage `json:"measurements,omitempty"`
 	}
 
 	x := errorEvent{event: (*event)(e)}
@@ -659,6 +783,7 @@ func (e *Event) preSerializedMarshalJSON() ([]byte, error) {
 		StartTime       json.RawMessage `json:"start_timestamp,omitempty"`
 		Spans           json.RawMessage `json:"spans,omitempty"`
 		TransactionInfo json.RawMessage `json:"transaction_info,omitempty"`
+		Measurements    json.RawMessage `json:"measurements,omitempty"`
 	}
 	return json.Marshal(safeErrorEvent{
 		event:       (*event)(e),
@@ -780,6 +905,11 @@ func (l *Log) GetCategory() ratelimit.Category {
 	return ratelimit.CategoryLog
 }
 
+// GetTraceID returns the trace ID of the log.
+func (l *Log) GetTraceID() (string, bool) {
+	return l.TraceID.String(), l.TraceID != zeroTraceID
+}
+
 type MetricType string
 
 const (
@@ -808,6 +938,11 @@ func (m *Metric) GetCategory() ratelimit.Category {
 	return ratelimit.CategoryTraceMetric
 }
 
+// GetTraceID returns the trace ID of the metric.
+func (m *Metric) GetTraceID() (string, bool) {
+	return m.TraceID.String(), m.TraceID != zeroTraceID
+}
+
 // MetricValue stores metric values with full precision.
 // It supports int64 (for counters) and float64 (for gauges and distributions).
 type MetricValue struct {
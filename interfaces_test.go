@@ -1219,7 +1219,7 @@ func TestProcessor_MutationAfterAdd(t *testing.T) {
 		),
 	}
 
-	proc := telemetry.NewProcessor(buffers, transport, dsn, func() *protocol.SdkInfo { return sdk }, nil)
+	proc := telemetry.NewProcessor(buffers, transport, dsn, func() *protocol.SdkInfo { return sdk }, nil, nil)
 
 	contexts := map[string]Context{
 		"app": {"version": "1.0"},
@@ -1318,3 +1318,77 @@ func TestProcessor_MutationAfterAdd(t *testing.T) {
 		t.Errorf("mutated value leaked into serialized payload: %s", payload)
 	}
 }
+
+func TestEvent_MarshalWithinLimit(t *testing.T) {
+	newEvent := func() *Event {
+		event := NewEvent()
+		event.Message = "message"
+		for i := 0; i < 8; i++ {
+			event.Breadcrumbs = append(event.Breadcrumbs, &Breadcrumb{Message: fmt.Sprintf("crumb-%d-%s", i, strings.Repeat("x", 1000))})
+		}
+		return event
+	}
+	full, err := json.Marshal(newEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		body, err := newEvent().marshalWithinLimit(len(full))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(body) != len(full) {
+			t.Errorf("body is %d bytes, want %d", len(body), len(full))
+		}
+	})
+
+	t.Run("trims oldest breadcrumbs", func(t *testing.T) {
+		event := newEvent()
+		body, err := event.marshalWithinLimit(len(full) - 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Event
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Breadcrumbs) != 4 {
+			t.Fatalf("got %d breadcrumbs, want 4", len(got.Breadcrumbs))
+		}
+		if !strings.HasPrefix(got.Breadcrumbs[3].Message, "crumb-7-") {
+			t.Errorf("newest breadcrumb was not kept: %q", got.Breadcrumbs[3].Message)
+		}
+		if len(event.Breadcrumbs) != 8 {
+			t.Error("event was modified")
+		}
+	})
+
+	t.Run("trims serialized breadcrumbs", func(t *testing.T) {
+		event := newEvent()
+		event.MakeSerializationSafe()
+		body, err := event.marshalWithinLimit(len(full) - 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Event
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Breadcrumbs) != 4 || got.Message != "message" {
+			t.Errorf("got %d breadcrumbs and message %q", len(got.Breadcrumbs), got.Message)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		event := newEvent()
+		event.Message = strings.Repeat("x", len(full))
+		_, err := event.marshalWithinLimit(len(full))
+		if !errors.Is(err, protocol.ErrTooLarge) {
+			t.Errorf("got error %v, want %v", err, protocol.ErrTooLarge)
+		}
+		if discardReasonForError(err) != "too_large" {
+			t.Errorf("got discard reason %q", discardReasonForError(err))
+		}
+	})
+}
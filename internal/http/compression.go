@@ -0,0 +1,74 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+)
+
+// Content encodings understood by Sentry.
+const (
+	EncodingGzip = "gzip"
+	EncodingZstd = "zstd"
+)
+
+// Compression configures how request bodies are compressed before they are
+// sent to Sentry. The zero value disables compression.
+type Compression struct {
+	// Encoding is the Content-Encoding of compressed bodies. Compression is
+	// disabled when Encoding is empty.
+	Encoding string
+	// Threshold is the size in bytes below which bodies are sent uncompressed.
+	Threshold int
+	// Level is the gzip compression level. Zero uses gzip.DefaultCompression.
+	Level int
+	// NewWriter returns an encoder writing to w. It is required for encodings
+	// other than gzip and overrides the built-in gzip encoder when set.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// Compress returns the body to send and the Content-Encoding to set for it.
+// An empty encoding means body is returned unchanged, either because
+// compression is disabled, the body is below the threshold or compressing it
+// failed.
+func (c Compression) Compress(body []byte) ([]byte, string) {
+	if c.Encoding == "" || len(body) < c.Threshold {
+		return body, ""
+	}
+
+	var buf bytes.Buffer
+	if err := c.compress(&buf, body); err != nil {
+		debuglog.Printf("Sending request uncompressed: %v", err)
+		return body, ""
+	}
+	return buf.Bytes(), c.Encoding
+}
+
+func (c Compression) compress(dst io.Writer, body []byte) error {
+	w, err := c.newWriter(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c Compression) newWriter(w io.Writer) (io.WriteCloser, error) {
+	if c.NewWriter != nil {
+		return c.NewWriter(w)
+	}
+	if c.Encoding != EncodingGzip {
+		return nil, fmt.Errorf("no encoder configured for content encoding %q", c.Encoding)
+	}
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
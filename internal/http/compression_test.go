@@ -0,0 +1,139 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/testutils"
+)
+
+func decompress(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(body)
+	switch encoding {
+	case "":
+	case EncodingGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("invalid gzip body: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	case "deflate":
+		fr := flate.NewReader(r)
+		defer fr.Close()
+		r = fr
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to decompress body: %v", err)
+	}
+	return data
+}
+
+func TestCompression_Compress(t *testing.T) {
+	body := []byte(strings.Repeat("compressible ", 100))
+
+	tests := []struct {
+		name        string
+		compression Compression
+		want        string
+	}{
+		{"disabled", Compression{}, ""},
+		{"gzip", Compression{Encoding: EncodingGzip}, EncodingGzip},
+		{"gzip with level", Compression{Encoding: EncodingGzip, Level: gzip.BestSpeed}, EncodingGzip},
+		{"below threshold", Compression{Encoding: EncodingGzip, Threshold: len(body) + 1}, ""},
+		{"at threshold", Compression{Encoding: EncodingGzip, Threshold: len(body)}, EncodingGzip},
+		{"invalid level", Compression{Encoding: EncodingGzip, Level: 42}, ""},
+		{"missing encoder", Compression{Encoding: EncodingZstd}, ""},
+		{"custom encoder", Compression{
+			Encoding: "deflate",
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return flate.NewWriter(w, flate.DefaultCompression)
+			},
+		}, "deflate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, encoding := tt.compression.Compress(body)
+			if encoding != tt.want {
+				t.Fatalf("encoding = %q, want %q", encoding, tt.want)
+			}
+			if encoding != "" && len(got) >= len(body) {
+				t.Errorf("compressed body is %d bytes, expected less than %d", len(got), len(body))
+			}
+			if data := decompress(t, encoding, got); !bytes.Equal(data, body) {
+				t.Errorf("decompressed body mismatch: got %q", data)
+			}
+		})
+	}
+}
+
+func TestTransport_Compression(t *testing.T) {
+	var mu sync.Mutex
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+			return
+		}
+		encoding := r.Header.Get("Content-Encoding")
+		envelope, err := protocol.ParseEnvelope(decompress(t, encoding, data))
+		if err != nil {
+			t.Errorf("failed to parse envelope: %v", err)
+			return
+		}
+		if envelope.Header.EventID != "test-event-id" {
+			t.Errorf("unexpected event id %q", envelope.Header.EventID)
+		}
+		mu.Lock()
+		encodings = append(encodings, encoding)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	options := TransportOptions{
+		Dsn:         "http://key@" + server.URL[7:] + "/123",
+		Compression: Compression{Encoding: EncodingGzip},
+	}
+	transports := map[string]func() protocol.TelemetryTransport{
+		"AsyncTransport": func() protocol.TelemetryTransport { return NewAsyncTransport(options) },
+		"SyncTransport":  func() protocol.TelemetryTransport { return NewSyncTransport(options) },
+	}
+
+	for name, newTransport := range transports {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			encodings = nil
+			mu.Unlock()
+
+			transport := newTransport()
+			defer transport.Close()
+
+			if err := transport.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeLog)); err != nil {
+				t.Fatalf("failed to send envelope: %v", err)
+			}
+			if !transport.Flush(testutils.FlushTimeout()) {
+				t.Fatal("Flush timed out")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(encodings) != 1 || encodings[0] != EncodingGzip {
+				t.Errorf("Content-Encoding = %v, want [gzip]", encodings)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+)
+
+// Destination is a transport together with the DSN that envelopes sent
+// through it are addressed to.
+type Destination struct {
+	Dsn       *protocol.Dsn
+	Transport protocol.TelemetryTransport
+}
+
+// FanoutTransport sends every envelope to a primary transport and mirrors it
+// to the transports of secondary destinations.
+//
+// Each destination is expected to have its own rate limits and client
+// reports, so that a destination that is rate limited or unreachable never
+// affects delivery to the others. An envelope is only considered rate limited
+// when all destinations are rate limited for its category.
+type FanoutTransport struct {
+	primary     protocol.TelemetryTransport
+	secondaries []Destination
+}
+
+// NewFanoutTransport returns a FanoutTransport, or primary itself if there
+// are no secondary destinations.
+func NewFanoutTransport(primary protocol.TelemetryTransport, secondaries []Destination) protocol.TelemetryTransport {
+	if len(secondaries) == 0 {
+		return primary
+	}
+	return &FanoutTransport{primary: primary, secondaries: secondaries}
+}
+
+// SendEnvelope sends the envelope to the primary transport and a copy of it,
+// addressed to the respective DSN, to every secondary destination. Only the
+// error of the primary transport is returned.
+func (t *FanoutTransport) SendEnvelope(envelope *protocol.Envelope) error {
+	if envelope == nil || len(envelope.Items) == 0 {
+		return ErrEmptyEnvelope
+	}
+
+	// Copies are made before the primary transport takes ownership of the
+	// envelope, which may attach client reports to it.
+	copies := make([]*protocol.Envelope, len(t.secondaries))
+	for i, destination := range t.secondaries {
+		copies[i] = readdressEnvelope(envelope, destination.Dsn)
+	}
+
+	err := t.primary.SendEnvelope(envelope)
+	for i, destination := range t.secondaries {
+		_ = destination.Transport.SendEnvelope(copies[i])
+	}
+	return err
+}
+
+// IsRateLimited reports whether all destinations are rate limited for the
+// category.
+func (t *FanoutTransport) IsRateLimited(category ratelimit.Category) bool {
+	return allRateLimited(t.transports(), category)
+}
+
+// HasCapacity reports whether any destination has capacity for another
+// envelope.
+func (t *FanoutTransport) HasCapacity() bool {
+	return anyHasCapacity(t.transports())
+}
+
+func (t *FanoutTransport) Flush(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.FlushWithContext(ctx)
+}
+
+// FlushWithContext flushes all destinations and reports whether all of them
+// were flushed before ctx was done.
+func (t *FanoutTransport) FlushWithContext(ctx context.Context) bool {
+	return flushAll(ctx, t.transports())
+}
+
+func (t *FanoutTransport) Close() {
+	closeAll(t.transports())
+}
+
+// Stats returns the stats of the primary transport.
+func (t *FanoutTransport) Stats() protocol.TransportStats {
+	return Stats(t.primary)
+}
+
+func (t *FanoutTransport) transports() []protocol.TelemetryTransport {
+	transports := []protocol.TelemetryTransport{t.primary}
+	for _, destination := range t.secondaries {
+		transports = append(transports, destination.Transport)
+	}
+	return transports
+}
+
+func allRateLimited(transports []protocol.TelemetryTransport, category ratelimit.Category) bool {
+	for _, transport := range transports {
+		if !transport.IsRateLimited(category) {
+			return false
+		}
+	}
+	return true
+}
+
+func anyHasCapacity(transports []protocol.TelemetryTransport) bool {
+	for _, transport := range transports {
+		if transport.HasCapacity() {
+			return true
+		}
+	}
+	return false
+}
+
+func flushAll(ctx context.Context, transports []protocol.TelemetryTransport) bool {
+	ok := true
+	for _, transport := range transports {
+		if !transport.FlushWithContext(ctx) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+func closeAll(transports []protocol.TelemetryTransport) {
+	for _, transport := range transports {
+		transport.Close()
+	}
+}
+
+// readdressEnvelope returns a shallow copy of the envelope whose header is
+// addressed to dsn. Items are shared, but the item list is not, so that
+// client reports attached to one copy do not leak into another.
+func readdressEnvelope(envelope *protocol.Envelope, dsn *protocol.Dsn) *protocol.Envelope {
+	header := &protocol.EnvelopeHeader{Dsn: dsn}
+	if envelope.Header != nil {
+		header = envelope.Header.Readdress(dsn)
+	}
+	return &protocol.Envelope{Header: header, Items: slices.Clone(envelope.Items)}
+}
+
+// Stats returns the stats of transport if it is a protocol.StatsProvider, or
+// empty stats otherwise.
+func Stats(transport protocol.TelemetryTransport) protocol.TransportStats {
+	if provider, ok := transport.(protocol.StatsProvider); ok {
+		return provider.Stats()
+	}
+	return protocol.TransportStats{}
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/testutils"
+	"github.com/getsentry/sentry-go/report"
+)
+
+type recordingServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	bodies []string
+}
+
+func newRecordingServer(t *testing.T, handle func(w http.ResponseWriter)) *recordingServer {
+	t.Helper()
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.mu.Unlock()
+		handle(w)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func TestFanoutTransport(t *testing.T) {
+	limited := newRecordingServer(t, func(w http.ResponseWriter) {
+		w.Header().Add("X-Sentry-Rate-Limits", "60:error")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	healthy := newRecordingServer(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	primaryReports := report.NewAggregator()
+	primary := NewAsyncTransport(TransportOptions{
+		Dsn:      "http://key@" + limited.URL[7:] + "/123",
+		Recorder: primaryReports,
+		Provider: primaryReports,
+	})
+	secondaryDsn, err := protocol.NewDsn("http://other@" + healthy.URL[7:] + "/456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondaryReports := report.NewAggregator()
+	secondary := NewAsyncTransport(TransportOptions{
+		Dsn:      secondaryDsn.String(),
+		Recorder: secondaryReports,
+		Provider: secondaryReports,
+	})
+
+	transport := NewFanoutTransport(primary, []Destination{{Dsn: secondaryDsn, Transport: secondary}})
+	defer transport.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := transport.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+			t.Fatalf("SendEnvelope() failed: %v", err)
+		}
+		if !transport.Flush(testutils.FlushTimeout()) {
+			t.Fatal("Flush timed out")
+		}
+	}
+
+	if got := len(limited.requests()); got != 1 {
+		t.Errorf("rate limited destination got %d requests, want 1", got)
+	}
+	bodies := healthy.requests()
+	if len(bodies) != 2 {
+		t.Fatalf("healthy destination got %d requests, want 2", len(bodies))
+	}
+	for _, body := range bodies {
+		if !strings.Contains(body, secondaryDsn.String()) {
+			t.Errorf("envelope was not re-addressed to the secondary DSN: %s", body)
+		}
+	}
+
+	if transport.IsRateLimited(ratelimit.CategoryError) {
+		t.Error("IsRateLimited() should be false while a destination accepts errors")
+	}
+	if !primary.IsRateLimited(ratelimit.CategoryError) {
+		t.Error("primary destination should be rate limited")
+	}
+
+	r := primaryReports.TakeReport()
+	if r == nil || len(r.DiscardedEvents) != 1 || r.DiscardedEvents[0].Reason != report.ReasonRateLimitBackoff {
+		t.Errorf("primary client report = %+v, want one rate limited error", r)
+	}
+	if r := secondaryReports.TakeReport(); r != nil {
+		t.Errorf("secondary client report = %+v, want none", r)
+	}
+}
+
+func TestFanoutTransport_RateLimitedWhenAllDestinationsAre(t *testing.T) {
+	server := newRecordingServer(t, func(w http.ResponseWriter) {
+		w.Header().Add("X-Sentry-Rate-Limits", "60:error")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	dsn, err := protocol.NewDsn("http://key@" + server.URL[7:] + "/456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := NewAsyncTransport(TransportOptions{Dsn: "http://key@" + server.URL[7:] + "/123"})
+	secondary := NewAsyncTransport(TransportOptions{Dsn: dsn.String()})
+	transport := NewFanoutTransport(primary, []Destination{{Dsn: dsn, Transport: secondary}})
+	defer transport.Close()
+
+	if err := transport.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+		t.Fatalf("SendEnvelope() failed: %v", err)
+	}
+	if !transport.Flush(testutils.FlushTimeout()) {
+		t.Fatal("Flush timed out")
+	}
+
+	if !transport.IsRateLimited(ratelimit.CategoryError) {
+		t.Error("IsRateLimited() should be true when all destinations are rate limited")
+	}
+	if transport.IsRateLimited(ratelimit.CategoryTransaction) {
+		t.Error("IsRateLimited() should be false for other categories")
+	}
+}
+
+func TestNewFanoutTransport_WithoutSecondaries(t *testing.T) {
+	primary := NewNoopTransport()
+	if got := NewFanoutTransport(primary, nil); got != primary {
+		t.Errorf("NewFanoutTransport() = %T, want the primary transport", got)
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/util"
+	"github.com/getsentry/sentry-go/report"
+)
+
+// ReplayHandled returns what the send function passed to offline.Store.Replay
+// returns, given the result of sending an envelope read from the offline
+// cache. The envelope is kept for a later replay if Sentry could not be
+// reached or failed with a transient error, and recorded as discarded if
+// Sentry rejected it.
+func ReplayHandled(recorder report.ClientReportRecorder, envelope *protocol.Envelope, result *util.SendResult, err error) bool {
+	if err != nil {
+		debuglog.Printf("Replaying cached envelope failed: %v", err)
+	}
+	// Replays are not retried, the envelope stays cached instead.
+	outcome, _, reason := Retry{}.Next(1, result, err, 0)
+	switch outcome {
+	case Undelivered:
+		return false
+	case Rejected:
+		if reason != "" {
+			recorder.RecordForEnvelope(reason, envelope)
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/util"
+	"github.com/getsentry/sentry-go/report"
+)
+
+// Default backoff settings used when retries are enabled.
+const (
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	DefaultRetryMaxBackoff     = 30 * time.Second
+)
+
+// Retry configures how envelopes that failed with a transient error, such as
+// a network error or a 5xx response, are retried. The zero value disables
+// retries.
+type Retry struct {
+	// MaxRetries is the number of times an envelope is retried after the
+	// first attempt failed.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every retry. Defaults to DefaultRetryInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts. Defaults to
+	// DefaultRetryMaxBackoff.
+	MaxBackoff time.Duration
+}
+
+// Enabled reports whether failed envelopes should be retried at all.
+func (r Retry) Enabled() bool {
+	return r.MaxRetries > 0
+}
+
+// Delay returns how long to wait before the given retry, starting at 1, and
+// whether the retry should be attempted.
+//
+// The delay grows exponentially with a random jitter and is extended to at
+// least minWait, which callers derive from Retry-After headers and rate
+// limits. A retry is not attempted once the budget of MaxRetries is used up or
+// if minWait exceeds MaxBackoff, in which case waiting would stall the
+// transport for too long.
+func (r Retry) Delay(retry int, minWait time.Duration) (time.Duration, bool) {
+	if retry < 1 || retry > r.MaxRetries {
+		return 0, false
+	}
+
+	initial, maxBackoff := r.InitialBackoff, r.MaxBackoff
+	if initial <= 0 {
+		initial = DefaultRetryInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	if minWait > maxBackoff {
+		return 0, false
+	}
+
+	backoff := initial
+	for i := 1; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, maxBackoff)
+	// Equal jitter: wait at least half of the backoff, so that retries of
+	// many clients spread out without collapsing to zero.
+	half := backoff / 2
+	delay := half + rand.N(half+1)
+
+	return max(delay, minWait), true
+}
+
+// Outcome is what a transport does with an envelope after an attempt to
+// deliver it.
+type Outcome int
+
+const (
+	// Delivered means that Sentry accepted the envelope.
+	Delivered Outcome = iota
+	// Rejected means that Sentry rejected the envelope for good. It is
+	// recorded as discarded with the returned reason, if there is one.
+	Rejected
+	// RetryLater means that the envelope is sent again after the returned
+	// delay.
+	RetryLater
+	// Undelivered means that the envelope could not be delivered. It is kept
+	// in the offline cache, or recorded as discarded with the returned reason.
+	Undelivered
+)
+
+// Next decides what to do with an envelope after the given attempt to send
+// it, starting at 1, from the result of util.DoSendRequest. rateLimitWait is
+// how long the category of the envelope is still rate limited.
+func (r Retry) Next(attempt int, result *util.SendResult, err error, rateLimitWait time.Duration) (Outcome, time.Duration, report.DiscardReason) {
+	reason := report.ReasonSendError
+	var wait time.Duration
+	if err != nil {
+		reason = report.ReasonNetworkError
+	} else {
+		switch {
+		case result.Success:
+			return Delivered, 0, ""
+		case !result.IsRetryable():
+			if result.IsSendError() {
+				return Rejected, 0, report.ReasonSendError
+			}
+			return Rejected, 0, ""
+		}
+		wait = max(result.RetryAfter, rateLimitWait)
+	}
+
+	if !r.Enabled() {
+		return Undelivered, 0, reason
+	}
+	delay, ok := r.Delay(attempt, wait)
+	if !ok {
+		return Undelivered, 0, report.ReasonRetriesExhausted
+	}
+	return RetryLater, delay, ""
+}
+
+// RetryQueue holds items waiting for another delivery attempt, so that the
+// worker of a transport can send other envelopes in the meantime instead of
+// sleeping through the backoff.
+//
+// A RetryQueue is not safe for concurrent use, it belongs to the worker.
+type RetryQueue[T any] struct {
+	limit   int
+	pending []Pending[T]
+	timer   *time.Timer
+}
+
+// Pending is an item waiting in a RetryQueue.
+type Pending[T any] struct {
+	Item T
+	// Attempt is the number of the upcoming attempt.
+	Attempt int
+	// At is when the attempt is due.
+	At time.Time
+}
+
+// NewRetryQueue returns a RetryQueue that holds at most limit items.
+func NewRetryQueue[T any](limit int) *RetryQueue[T] {
+	return &RetryQueue[T]{limit: limit}
+}
+
+// Push schedules the given attempt of item after delay. It reports false if
+// the queue is full.
+func (q *RetryQueue[T]) Push(item T, attempt int, delay time.Duration) bool {
+	if len(q.pending) >= q.limit {
+		return false
+	}
+	q.pending = append(q.pending, Pending[T]{Item: item, Attempt: attempt, At: time.Now().Add(delay)})
+	slices.SortStableFunc(q.pending, func(a, b Pending[T]) int {
+		return a.At.Compare(b.At)
+	})
+	q.reset()
+	return true
+}
+
+// Len returns the number of items waiting in the queue.
+func (q *RetryQueue[T]) Len() int {
+	return len(q.pending)
+}
+
+// C returns a channel that receives when the earliest item is due. It is nil,
+// and so never receives, while the queue is empty.
+func (q *RetryQueue[T]) C() <-chan time.Time {
+	if q.timer == nil {
+		return nil
+	}
+	return q.timer.C
+}
+
+// Due removes and returns the items that are due at now.
+func (q *RetryQueue[T]) Due(now time.Time) []Pending[T] {
+	i := 0
+	for i < len(q.pending) && !q.pending[i].At.After(now) {
+		i++
+	}
+	due := slices.Clone(q.pending[:i])
+	q.pending = slices.Delete(q.pending, 0, i)
+	q.reset()
+	return due
+}
+
+// Drain removes and returns all items.
+func (q *RetryQueue[T]) Drain() []Pending[T] {
+	pending := q.pending
+	q.pending = nil
+	q.reset()
+	return pending
+}
+
+// reset arms the timer for the earliest item.
+func (q *RetryQueue[T]) reset() {
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if len(q.pending) > 0 {
+		q.timer = time.NewTimer(time.Until(q.pending[0].At))
+	}
+}
+
+// RewindRequest returns a copy of r that uses ctx and reads its body from the
+// start, so that a request can be sent again after a failed attempt.
+func RewindRequest(ctx context.Context, r *http.Request) (*http.Request, error) {
+	clone := r.Clone(ctx)
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
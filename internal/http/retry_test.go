@@ -0,0 +1,167 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/util"
+	"github.com/getsentry/sentry-go/report"
+)
+
+func TestRetry_Delay(t *testing.T) {
+	retry := Retry{MaxRetries: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		name     string
+		retry    int
+		minWait  time.Duration
+		min, max time.Duration
+		ok       bool
+	}{
+		{"first retry", 1, 0, 50 * time.Millisecond, 100 * time.Millisecond, true},
+		{"second retry", 2, 0, 100 * time.Millisecond, 200 * time.Millisecond, true},
+		{"third retry", 3, 0, 200 * time.Millisecond, 400 * time.Millisecond, true},
+		{"capped at max backoff", 5, 0, 500 * time.Millisecond, time.Second, true},
+		{"honors minimum wait", 1, 800 * time.Millisecond, 800 * time.Millisecond, 800 * time.Millisecond, true},
+		{"minimum wait beyond max backoff", 1, 2 * time.Second, 0, 0, false},
+		{"budget exhausted", 6, 0, 0, 0, false},
+		{"invalid retry", 0, 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay, ok := retry.Delay(tt.retry, tt.minWait)
+				if ok != tt.ok {
+					t.Fatalf("Delay() ok = %v, want %v", ok, tt.ok)
+				}
+				if delay < tt.min || delay > tt.max {
+					t.Fatalf("Delay() = %v, want between %v and %v", delay, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetry_DelayDefaults(t *testing.T) {
+	retry := Retry{MaxRetries: 100}
+	if retry := (Retry{}); retry.Enabled() {
+		t.Error("zero Retry should be disabled")
+	}
+
+	delay, ok := retry.Delay(1, 0)
+	if !ok || delay < DefaultRetryInitialBackoff/2 || delay > DefaultRetryInitialBackoff {
+		t.Errorf("Delay(1) = %v, %v", delay, ok)
+	}
+	delay, ok = retry.Delay(100, 0)
+	if !ok || delay < DefaultRetryMaxBackoff/2 || delay > DefaultRetryMaxBackoff {
+		t.Errorf("Delay(100) = %v, %v", delay, ok)
+	}
+}
+
+func TestRetry_Next(t *testing.T) {
+	retry := Retry{MaxRetries: 2, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	networkError := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		retry         Retry
+		attempt       int
+		result        *util.SendResult
+		err           error
+		rateLimitWait time.Duration
+		wantOutcome   Outcome
+		wantReason    report.DiscardReason
+		wantMinDelay  time.Duration
+	}{
+		{"delivered", retry, 1, &util.SendResult{Success: true, StatusCode: 200}, nil, 0, Delivered, "", 0},
+		{"client error", retry, 1, &util.SendResult{StatusCode: 400}, nil, 0, Rejected, report.ReasonSendError, 0},
+		{"too many requests", retry, 1, &util.SendResult{StatusCode: 429}, nil, 0, Rejected, "", 0},
+		{"server error", retry, 1, &util.SendResult{StatusCode: 503}, nil, 0, RetryLater, "", 50 * time.Millisecond},
+		{"network error", retry, 2, nil, networkError, 0, RetryLater, "", 100 * time.Millisecond},
+		{"retry after", retry, 1, &util.SendResult{StatusCode: 503, RetryAfter: 700 * time.Millisecond}, nil, 0, RetryLater, "", 700 * time.Millisecond},
+		{"rate limited", retry, 1, &util.SendResult{StatusCode: 503}, nil, 800 * time.Millisecond, RetryLater, "", 800 * time.Millisecond},
+		{"retries exhausted", retry, 3, &util.SendResult{StatusCode: 503}, nil, 0, Undelivered, report.ReasonRetriesExhausted, 0},
+		{"wait beyond max backoff", retry, 1, &util.SendResult{StatusCode: 503, RetryAfter: time.Minute}, nil, 0, Undelivered, report.ReasonRetriesExhausted, 0},
+		{"server error without retries", Retry{}, 1, &util.SendResult{StatusCode: 503}, nil, 0, Undelivered, report.ReasonSendError, 0},
+		{"network error without retries", Retry{}, 1, nil, networkError, 0, Undelivered, report.ReasonNetworkError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, delay, reason := tt.retry.Next(tt.attempt, tt.result, tt.err, tt.rateLimitWait)
+			if outcome != tt.wantOutcome || reason != tt.wantReason {
+				t.Errorf("Next() = %v, %q, want %v, %q", outcome, reason, tt.wantOutcome, tt.wantReason)
+			}
+			if delay < tt.wantMinDelay {
+				t.Errorf("Next() delay = %v, want at least %v", delay, tt.wantMinDelay)
+			}
+		})
+	}
+}
+
+func TestRetryQueue(t *testing.T) {
+	q := NewRetryQueue[string](2)
+	if q.C() != nil {
+		t.Error("C() of an empty queue should be nil")
+	}
+
+	if !q.Push("late", 2, time.Hour) || !q.Push("soon", 3, 10*time.Millisecond) {
+		t.Fatal("Push() = false, want true")
+	}
+	if q.Push("full", 2, 0) {
+		t.Error("Push() on a full queue = true, want false")
+	}
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", q.Len())
+	}
+	if due := q.Due(time.Now()); len(due) != 0 {
+		t.Errorf("Due() = %v, want nothing due yet", due)
+	}
+
+	select {
+	case <-q.C():
+	case <-time.After(time.Second):
+		t.Fatal("C() did not receive when the earliest item was due")
+	}
+	due := q.Due(time.Now())
+	if len(due) != 1 || due[0].Item != "soon" || due[0].Attempt != 3 {
+		t.Fatalf("Due() = %v, want [soon]", due)
+	}
+
+	drained := q.Drain()
+	if len(drained) != 1 || drained[0].Item != "late" {
+		t.Errorf("Drain() = %v, want [late]", drained)
+	}
+	if q.Len() != 0 || q.C() != nil {
+		t.Error("queue should be empty after Drain()")
+	}
+}
+
+func TestRewindRequest(t *testing.T) {
+	request, err := http.NewRequest(http.MethodPost, "http://example.com", bytes.NewReader([]byte("payload")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(request.Body); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), struct{}{}, "value")
+	rewound, err := RewindRequest(ctx, request)
+	if err != nil {
+		t.Fatalf("RewindRequest() failed: %v", err)
+	}
+	if rewound.Context() != ctx {
+		t.Error("rewound request should use the given context")
+	}
+	body, err := io.ReadAll(rewound.Body)
+	if err != nil || string(body) != "payload" {
+		t.Errorf("rewound body = %q, %v", body, err)
+	}
+}
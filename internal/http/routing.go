@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+)
+
+// RoutingTransport sends every envelope to the destination its header is
+// addressed to, and envelopes addressed to any other DSN to a primary
+// transport.
+//
+// Like FanoutTransport, each destination is expected to have its own rate
+// limits and client reports. A category is only considered rate limited when
+// all destinations are rate limited for it, as the category alone does not
+// tell where an envelope is going to be sent.
+type RoutingTransport struct {
+	primary protocol.TelemetryTransport
+	routes  map[string]protocol.TelemetryTransport
+	all     []protocol.TelemetryTransport
+}
+
+// NewRoutingTransport returns a RoutingTransport, or primary itself if there
+// are no destinations. If several destinations share a DSN, the first one is
+// used.
+func NewRoutingTransport(primary protocol.TelemetryTransport, destinations []Destination) protocol.TelemetryTransport {
+	if len(destinations) == 0 {
+		return primary
+	}
+	t := &RoutingTransport{
+		primary: primary,
+		routes:  make(map[string]protocol.TelemetryTransport, len(destinations)),
+		all:     []protocol.TelemetryTransport{primary},
+	}
+	for _, destination := range destinations {
+		key := destination.Dsn.String()
+		if _, ok := t.routes[key]; ok {
+			continue
+		}
+		t.routes[key] = destination.Transport
+		t.all = append(t.all, destination.Transport)
+	}
+	return t
+}
+
+func (t *RoutingTransport) SendEnvelope(envelope *protocol.Envelope) error {
+	if envelope == nil || len(envelope.Items) == 0 {
+		return ErrEmptyEnvelope
+	}
+	return t.route(envelope).SendEnvelope(envelope)
+}
+
+func (t *RoutingTransport) route(envelope *protocol.Envelope) protocol.TelemetryTransport {
+	if envelope.Header == nil || envelope.Header.Dsn == nil {
+		return t.primary
+	}
+	if transport, ok := t.routes[envelope.Header.Dsn.String()]; ok {
+		return transport
+	}
+	return t.primary
+}
+
+// IsRateLimited reports whether all destinations are rate limited for the
+// category.
+func (t *RoutingTransport) IsRateLimited(category ratelimit.Category) bool {
+	return allRateLimited(t.all, category)
+}
+
+// HasCapacity reports whether any destination has capacity for another
+// envelope.
+func (t *RoutingTransport) HasCapacity() bool {
+	return anyHasCapacity(t.all)
+}
+
+func (t *RoutingTransport) Flush(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.FlushWithContext(ctx)
+}
+
+// FlushWithContext flushes all destinations and reports whether all of them
+// were flushed before ctx was done.
+func (t *RoutingTransport) FlushWithContext(ctx context.Context) bool {
+	return flushAll(ctx, t.all)
+}
+
+// Stats returns the stats of the primary transport.
+func (t *RoutingTransport) Stats() protocol.TransportStats {
+	return Stats(t.primary)
+}
+
+func (t *RoutingTransport) Close() {
+	closeAll(t.all)
+}
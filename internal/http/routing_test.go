@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/testutils"
+	"github.com/getsentry/sentry-go/report"
+)
+
+func TestRoutingTransport(t *testing.T) {
+	primaryServer := newRecordingServer(t, func(w http.ResponseWriter) {
+		w.Header().Add("X-Sentry-Rate-Limits", "60:error")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	routedServer := newRecordingServer(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	primaryDsn, err := protocol.NewDsn("http://key@" + primaryServer.URL[7:] + "/123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	routedDsn, err := protocol.NewDsn("http://other@" + routedServer.URL[7:] + "/456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	primaryReports := report.NewAggregator()
+	routedReports := report.NewAggregator()
+	primary := NewAsyncTransport(TransportOptions{Dsn: primaryDsn.String(), Recorder: primaryReports, Provider: primaryReports})
+	routed := NewAsyncTransport(TransportOptions{Dsn: routedDsn.String(), Recorder: routedReports, Provider: routedReports})
+
+	transport := NewRoutingTransport(primary, []Destination{{Dsn: routedDsn, Transport: routed}})
+	defer transport.Close()
+
+	send := func(dsn *protocol.Dsn) {
+		envelope := testEnvelope(protocol.EnvelopeItemTypeEvent)
+		envelope.Header.Dsn = dsn
+		if err := transport.SendEnvelope(envelope); err != nil {
+			t.Fatalf("SendEnvelope() failed: %v", err)
+		}
+		if !transport.Flush(testutils.FlushTimeout()) {
+			t.Fatal("Flush timed out")
+		}
+	}
+	send(primaryDsn)
+	send(nil)
+	send(routedDsn)
+	send(routedDsn)
+
+	// The second envelope for the primary destination is dropped because of
+	// its rate limit, which does not affect the routed destination.
+	if got := len(primaryServer.requests()); got != 1 {
+		t.Errorf("primary destination got %d requests, want 1", got)
+	}
+	if got := len(routedServer.requests()); got != 2 {
+		t.Errorf("routed destination got %d requests, want 2", got)
+	}
+	if transport.IsRateLimited(ratelimit.CategoryError) {
+		t.Error("IsRateLimited() should be false while a destination accepts errors")
+	}
+
+	r := primaryReports.TakeReport()
+	if r == nil || len(r.DiscardedEvents) != 1 || r.DiscardedEvents[0].Reason != report.ReasonRateLimitBackoff {
+		t.Errorf("primary client report = %+v, want one rate limited error", r)
+	}
+	if r := routedReports.TakeReport(); r != nil {
+		t.Errorf("routed client report = %+v, want none", r)
+	}
+}
+
+func TestNewRoutingTransport_WithoutDestinations(t *testing.T) {
+	primary := NewNoopTransport()
+	if got := NewRoutingTransport(primary, nil); got != primary {
+		t.Errorf("NewRoutingTransport() = %T, want the primary transport", got)
+	}
+}
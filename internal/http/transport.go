@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/offline"
 	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/ratelimit"
 	"github.com/getsentry/sentry-go/internal/util"
@@ -20,8 +21,6 @@ import (
 )
 
 const (
-	apiVersion = 7
-
 	defaultTimeout           = time.Second * 30
 	defaultQueueSize         = 1000
 	defaultClientReportsTick = time.Second * 30
@@ -43,6 +42,14 @@ type TransportOptions struct {
 	Recorder      report.ClientReportRecorder
 	Provider      report.ClientReportProvider
 	SdkInfo       func() *protocol.SdkInfo
+	// OfflineCache configures the on-disk store used by AsyncTransport for
+	// envelopes that could not be delivered. Disabled when the directory is empty.
+	OfflineCache offline.Options
+	// Compression configures compression of request bodies.
+	Compression Compression
+	// Retry configures retries of envelopes that failed with a transient
+	// error. Only used by AsyncTransport.
+	Retry Retry
 }
 
 func getProxyConfig(options TransportOptions) func(*http.Request) (*url.URL, error) {
@@ -72,7 +79,7 @@ func getTLSConfig(options TransportOptions) *tls.Config {
 	return nil
 }
 
-func getSentryRequestFromEnvelope(ctx context.Context, dsn *protocol.Dsn, envelope *protocol.Envelope) (r *http.Request, err error) {
+func getSentryRequestFromEnvelope(ctx context.Context, dsn *protocol.Dsn, envelope *protocol.Envelope, compression Compression) (r *http.Request, err error) {
 	defer func() {
 		if r != nil {
 			var sdkName, sdkVersion string
@@ -84,14 +91,7 @@ func getSentryRequestFromEnvelope(ctx context.Context, dsn *protocol.Dsn, envelo
 			r.Header.Set("User-Agent", fmt.Sprintf("%s/%s", sdkName, sdkVersion))
 			r.Header.Set("Content-Type", "application/x-sentry-envelope")
 
-			auth := fmt.Sprintf("Sentry sentry_version=%d, "+
-				"sentry_client=%s/%s, sentry_key=%s", apiVersion, sdkName, sdkVersion, dsn.GetPublicKey())
-
-			if dsn.GetSecretKey() != "" {
-				auth = fmt.Sprintf("%s, sentry_secret=%s", auth, dsn.GetSecretKey())
-			}
-
-			r.Header.Set("X-Sentry-Auth", auth)
+			r.Header.Set("X-Sentry-Auth", dsn.AuthHeader(sdkName, sdkVersion))
 		}
 	}()
 
@@ -101,41 +101,26 @@ func getSentryRequestFromEnvelope(ctx context.Context, dsn *protocol.Dsn, envelo
 		return nil, err
 	}
 
-	return http.NewRequestWithContext(
+	body, encoding := compression.Compress(buf.Bytes())
+	request, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		dsn.GetAPIURL().String(),
-		&buf,
+		bytes.NewReader(body),
 	)
-}
-
-func categoryFromEnvelope(envelope *protocol.Envelope) ratelimit.Category {
-	if envelope == nil || len(envelope.Items) == 0 {
-		return ratelimit.CategoryAll
+	if err != nil {
+		return nil, err
 	}
-
-	for _, item := range envelope.Items {
-		if item == nil || item.Header == nil {
-			continue
-		}
-
-		switch item.Header.Type {
-		case protocol.EnvelopeItemTypeEvent:
-			return ratelimit.CategoryError
-		case protocol.EnvelopeItemTypeTransaction:
-			return ratelimit.CategoryTransaction
-		case protocol.EnvelopeItemTypeCheckIn:
-			return ratelimit.CategoryMonitor
-		case protocol.EnvelopeItemTypeLog:
-			return ratelimit.CategoryLog
-		case protocol.EnvelopeItemTypeAttachment:
-			continue
-		default:
-			return ratelimit.CategoryAll
-		}
+	if encoding != "" {
+		request.Header.Set("Content-Encoding", encoding)
 	}
+	return request, nil
+}
 
-	return ratelimit.CategoryAll
+// NewEnvelopeRequest creates a request that delivers the envelope to the
+// project identified by dsn, with the same headers the transports use.
+func NewEnvelopeRequest(ctx context.Context, dsn *protocol.Dsn, envelope *protocol.Envelope, compression Compression) (*http.Request, error) {
+	return getSentryRequestFromEnvelope(ctx, dsn, envelope, compression)
 }
 
 // SyncTransport is a blocking implementation of Transport.
@@ -157,6 +142,8 @@ type SyncTransport struct {
 	provider  report.ClientReportProvider
 	sdkInfo   func() *protocol.SdkInfo
 
+	compression Compression
+
 	mu     sync.Mutex
 	limits ratelimit.Map
 
@@ -186,6 +173,8 @@ func NewSyncTransport(options TransportOptions) protocol.TelemetryTransport {
 		recorder: recorder,
 		provider: provider,
 		sdkInfo:  options.SdkInfo,
+
+		compression: options.Compression,
 	}
 
 	if options.HTTPTransport != nil {
@@ -226,7 +215,7 @@ func (t *SyncTransport) SendEnvelopeWithContext(ctx context.Context, envelope *p
 		return ErrEmptyEnvelope
 	}
 
-	category := categoryFromEnvelope(envelope)
+	category := util.EnvelopeCategory(envelope)
 	if t.disabled(category) {
 		t.recorder.RecordForEnvelope(report.ReasonRateLimitBackoff, envelope)
 		return nil
@@ -234,7 +223,7 @@ func (t *SyncTransport) SendEnvelopeWithContext(ctx context.Context, envelope *p
 	// the sync transport needs to attach client reports when available
 	t.provider.AttachToEnvelope(envelope)
 
-	request, err := getSentryRequestFromEnvelope(ctx, t.dsn, envelope)
+	request, err := getSentryRequestFromEnvelope(ctx, t.dsn, envelope, t.compression)
 	if err != nil {
 		debuglog.Printf("There was an issue creating the request: %v", err)
 		t.recorder.RecordForEnvelope(report.ReasonInternalError, envelope)
@@ -296,8 +285,19 @@ type AsyncTransport struct {
 	provider  report.ClientReportProvider
 	sdkInfo   func() *protocol.SdkInfo
 
+	compression Compression
+	retry       Retry
+
 	queue chan *protocol.Envelope
 
+	// retries and flushWaiters belong to the worker. A flush is done once no
+	// envelope is waiting for a retry.
+	retries      *RetryQueue[retryEnvelope]
+	flushWaiters []chan struct{}
+
+	// cache persists envelopes that could not be delivered.
+	cache *offline.Store
+
 	mu     sync.RWMutex
 	limits ratelimit.Map
 
@@ -340,11 +340,21 @@ func NewAsyncTransport(options TransportOptions) protocol.TelemetryTransport {
 		recorder:  recorder,
 		provider:  provider,
 		sdkInfo:   options.SdkInfo,
+
+		compression: options.Compression,
+		retry:       options.Retry,
 	}
 
 	transport.queue = make(chan *protocol.Envelope, transport.QueueSize)
+	transport.retries = NewRetryQueue[retryEnvelope](transport.QueueSize)
 	transport.flushRequest = make(chan chan struct{})
 
+	cache, err := offline.NewStore(options.OfflineCache, recorder)
+	if err != nil {
+		debuglog.Printf("Offline cache is disabled: %v", err)
+	}
+	transport.cache = cache
+
 	if options.HTTPTransport != nil {
 		transport.transport = options.HTTPTransport
 	} else {
@@ -375,6 +385,9 @@ func (t *AsyncTransport) start() {
 		if t.provider == nil {
 			t.provider = report.NoopProvider()
 		}
+		if t.retries == nil {
+			t.retries = NewRetryQueue[retryEnvelope](cap(t.queue))
+		}
 		t.wg.Add(1)
 		go t.worker()
 	})
@@ -407,7 +420,7 @@ func (t *AsyncTransport) SendEnvelope(envelope *protocol.Envelope) error {
 		return ErrEmptyEnvelope
 	}
 
-	category := categoryFromEnvelope(envelope)
+	category := util.EnvelopeCategory(envelope)
 	if t.isRateLimited(category) {
 		t.recorder.RecordForEnvelope(report.ReasonRateLimitBackoff, envelope)
 		return nil
@@ -427,6 +440,10 @@ func (t *AsyncTransport) SendEnvelope(envelope *protocol.Envelope) error {
 		)
 		return nil
 	default:
+		if t.cache.KeepEnvelope(envelope) {
+			debuglog.Printf("Transport queue full, stored %s in offline cache", identifier)
+			return nil
+		}
 		t.recorder.RecordForEnvelope(report.ReasonQueueOverflow, envelope)
 		return ErrTransportQueueFull
 	}
@@ -469,6 +486,8 @@ func (t *AsyncTransport) Close() {
 
 		close(t.done)
 		t.wg.Wait()
+		t.persistQueue()
+		t.persistRetries()
 	})
 }
 
@@ -489,27 +508,45 @@ func (t *AsyncTransport) worker() {
 	crTicker := time.NewTicker(defaultClientReportsTick)
 	defer crTicker.Stop()
 
+	t.cache.ReplayPending(t.replayEnvelope)
+
 	for {
 		select {
 		case <-t.done:
 			return
 		case <-crTicker.C:
 			t.sendClientReport()
+			t.cache.ReplayPending(t.replayEnvelope)
 		case envelope, open := <-t.queue:
 			if !open {
 				return
 			}
 			t.sendEnvelopeHTTP(envelope)
+		case <-t.retries.C():
+			t.retryDue()
 		case flushResponse, open := <-t.flushRequest:
 			if !open {
 				return
 			}
 			t.drainQueue()
-			close(flushResponse)
+			t.flushWaiters = append(t.flushWaiters, flushResponse)
 		}
+		t.notifyFlushed()
 	}
 }
 
+// notifyFlushed completes pending flushes once no envelope is waiting for a
+// retry.
+func (t *AsyncTransport) notifyFlushed() {
+	if t.retries.Len() > 0 {
+		return
+	}
+	for _, flushResponse := range t.flushWaiters {
+		close(flushResponse)
+	}
+	t.flushWaiters = nil
+}
+
 // sendClientReport sends a standalone envelope containing only a client report.
 func (t *AsyncTransport) sendClientReport() {
 	r := t.provider.TakeReport()
@@ -532,7 +569,7 @@ func (t *AsyncTransport) sendClientReport() {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
 	defer cancel()
 
-	request, err := getSentryRequestFromEnvelope(ctx, t.dsn, envelope)
+	request, err := getSentryRequestFromEnvelope(ctx, t.dsn, envelope, t.compression)
 	if err != nil {
 		debuglog.Printf("Failed to create client report request: %v", err)
 		return
@@ -563,7 +600,7 @@ func (t *AsyncTransport) drainQueue() {
 }
 
 func (t *AsyncTransport) sendEnvelopeHTTP(envelope *protocol.Envelope) bool { //nolint: unparam
-	category := categoryFromEnvelope(envelope)
+	category := util.EnvelopeCategory(envelope)
 	if t.isRateLimited(category) {
 		t.recorder.RecordForEnvelope(report.ReasonRateLimitBackoff, envelope)
 		return false
@@ -571,32 +608,165 @@ func (t *AsyncTransport) sendEnvelopeHTTP(envelope *protocol.Envelope) bool { //
 	// attach to envelope after rate-limit check
 	t.provider.AttachToEnvelope(envelope)
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
-	defer cancel()
-
-	request, err := getSentryRequestFromEnvelope(ctx, t.dsn, envelope)
+	request, err := getSentryRequestFromEnvelope(context.Background(), t.dsn, envelope, t.compression)
 	if err != nil {
 		debuglog.Printf("Failed to create request from envelope: %v", err)
 		t.recorder.RecordForEnvelope(report.ReasonInternalError, envelope)
 		return false
 	}
 
-	identifier := util.EnvelopeIdentifier(envelope)
-	result, err := util.DoSendRequest(t.client, request, identifier)
+	return t.attempt(retryEnvelope{envelope: envelope, request: request}, 1)
+}
+
+// retryEnvelope is an envelope waiting for another delivery attempt.
+type retryEnvelope struct {
+	envelope *protocol.Envelope
+	request  *http.Request
+}
+
+// attempt makes one attempt to deliver an envelope. If it failed with a
+// transient error, the envelope is queued for a retry when retries are
+// enabled, and otherwise kept in the offline cache or recorded as discarded.
+// It reports whether the envelope was delivered.
+func (t *AsyncTransport) attempt(r retryEnvelope, attempt int) bool {
+	identifier := util.EnvelopeIdentifier(r.envelope)
+	category := util.EnvelopeCategory(r.envelope)
+
+	result, err := t.doSendRequest(r.request, identifier)
 	if err != nil {
 		debuglog.Printf("HTTP request failed: %v", err)
-		t.recorder.RecordForEnvelope(report.ReasonNetworkError, envelope)
+	} else {
+		t.mu.Lock()
+		t.limits.Merge(result.Limits)
+		t.mu.Unlock()
+	}
+
+	outcome, delay, reason := t.retry.Next(attempt, result, err, t.rateLimitWait(category))
+	switch outcome {
+	case Delivered:
+		// Sentry is reachable again, deliver what was stored while it was not.
+		t.cache.ReplayPending(t.replayEnvelope)
+		return true
+	case Rejected:
+		if reason != "" {
+			t.recorder.RecordForEnvelope(reason, r.envelope)
+		}
+	case RetryLater:
+		if t.retries.Push(r, attempt+1, delay) {
+			debuglog.Printf("Retrying %s in %s", identifier, delay)
+			return false
+		}
+		t.storeOrRecord(report.ReasonQueueOverflow, r.envelope)
+	case Undelivered:
+		if reason == report.ReasonRetriesExhausted {
+			debuglog.Printf("Giving up on %s after %d attempts", identifier, attempt)
+		}
+		t.storeOrRecord(reason, r.envelope)
+	}
+	return false
+}
+
+// retryDue sends the envelopes whose retry is due.
+func (t *AsyncTransport) retryDue() {
+	for _, pending := range t.retries.Due(time.Now()) {
+		t.attempt(pending.Item, pending.Attempt)
+	}
+}
+
+// doSendRequest makes a single attempt to send the request, which may have
+// been sent before.
+func (t *AsyncTransport) doSendRequest(request *http.Request, identifier string) (*util.SendResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	attempt, err := RewindRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return util.DoSendRequest(t.client, attempt, identifier)
+}
+
+// rateLimitWait returns how long the category is still rate limited.
+func (t *AsyncTransport) rateLimitWait(category ratelimit.Category) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return time.Until(time.Time(t.limits.Deadline(category)))
+}
+
+// storeOrRecord keeps an undelivered envelope in the offline cache, or records
+// it as discarded with the given reason if there is no cache.
+func (t *AsyncTransport) storeOrRecord(reason report.DiscardReason, envelope *protocol.Envelope) {
+	if t.cache.KeepEnvelope(envelope) {
+		debuglog.Printf("Stored %s in offline cache", util.EnvelopeIdentifier(envelope))
+		return
+	}
+	t.recorder.RecordForEnvelope(reason, envelope)
+}
+
+// persistQueue moves envelopes still waiting in the queue to the offline cache
+// so they survive a restart. It must only be called after the worker stopped.
+func (t *AsyncTransport) persistQueue() {
+	if t.cache == nil {
+		return
+	}
+	for {
+		select {
+		case envelope := <-t.queue:
+			t.storeOrRecord(report.ReasonQueueOverflow, envelope)
+		default:
+			return
+		}
+	}
+}
+
+// persistRetries moves envelopes still waiting for a retry to the offline
+// cache. It must only be called after the worker stopped.
+func (t *AsyncTransport) persistRetries() {
+	if t.retries == nil {
+		return
+	}
+	for _, pending := range t.retries.Drain() {
+		t.storeOrRecord(report.ReasonRetriesExhausted, pending.Item.envelope)
+	}
+}
+
+// replayEnvelope sends an envelope read from the offline cache. It returns
+// false if the envelope should be kept for a later attempt.
+func (t *AsyncTransport) replayEnvelope(envelope *protocol.Envelope) bool {
+	if t.isRateLimited(util.EnvelopeCategory(envelope)) {
+		// Keep it until the rate limit is over.
 		return false
 	}
-	if result.IsSendError() {
-		t.recorder.RecordForEnvelope(report.ReasonSendError, envelope)
+	envelope.Header.SentAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	request, err := getSentryRequestFromEnvelope(ctx, t.dsn, envelope, t.compression)
+	if err != nil {
+		debuglog.Printf("Failed to create request from cached envelope: %v", err)
+		t.recorder.RecordForEnvelope(report.ReasonInternalError, envelope)
+		return true
 	}
 
-	t.mu.Lock()
-	t.limits.Merge(result.Limits)
-	t.mu.Unlock()
+	result, err := util.DoSendRequest(t.client, request, util.EnvelopeIdentifier(envelope))
+	if err == nil {
+		t.mu.Lock()
+		t.limits.Merge(result.Limits)
+		t.mu.Unlock()
+	}
+	return ReplayHandled(t.recorder, envelope, result, err)
+}
 
-	return result.Success
+// Stats returns the queue depth and active rate limits of the transport.
+func (t *AsyncTransport) Stats() protocol.TransportStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return protocol.TransportStats{
+		QueueSize:     len(t.queue),
+		QueueCapacity: cap(t.queue),
+		RateLimits:    t.limits.Active(),
+	}
 }
 
 func (t *AsyncTransport) isRateLimited(category ratelimit.Category) bool {
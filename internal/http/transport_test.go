@@ -1,10 +1,12 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -15,10 +17,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/getsentry/sentry-go/internal/offline"
 	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/ratelimit"
 	"github.com/getsentry/sentry-go/internal/testutils"
 	"github.com/getsentry/sentry-go/internal/util"
+	"github.com/getsentry/sentry-go/report"
 	"go.uber.org/goleak"
 )
 
@@ -365,6 +369,266 @@ func TestAsyncTransport_Close(t *testing.T) {
 	}
 }
 
+func TestAsyncTransport_OfflineCache(t *testing.T) {
+	t.Run("stores failed envelopes and replays them once reachable", func(t *testing.T) {
+		var online atomic.Bool
+		var delivered int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if !online.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			atomic.AddInt64(&delivered, 1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		tr := NewAsyncTransport(TransportOptions{
+			Dsn:          "http://key@" + server.URL[7:] + "/123",
+			OfflineCache: offline.Options{Dir: dir},
+		})
+		transport, ok := tr.(*AsyncTransport)
+		if !ok {
+			t.Fatalf("expected *AsyncTransport, got %T", tr)
+		}
+		defer transport.Close()
+
+		for i := 0; i < 2; i++ {
+			if err := transport.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+				t.Fatalf("failed to send envelope: %v", err)
+			}
+		}
+		if !transport.Flush(testutils.FlushTimeout()) {
+			t.Fatal("Flush timed out")
+		}
+		if got := transport.cache.Len(); got != 2 {
+			t.Fatalf("expected 2 cached envelopes, got %d", got)
+		}
+
+		online.Store(true)
+		if err := transport.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+			t.Fatalf("failed to send envelope: %v", err)
+		}
+		if !transport.Flush(testutils.FlushTimeout()) {
+			t.Fatal("Flush timed out")
+		}
+
+		if got := atomic.LoadInt64(&delivered); got != 3 {
+			t.Errorf("expected 3 delivered envelopes, got %d", got)
+		}
+		if got := transport.cache.Len(); got != 0 {
+			t.Errorf("expected empty cache, got %d envelopes", got)
+		}
+	})
+
+	t.Run("replays envelopes from a previous run on start", func(t *testing.T) {
+		received := make(chan struct{}, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			received <- struct{}{}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		store, err := offline.NewStore(offline.Options{Dir: dir}, nil)
+		if err != nil {
+			t.Fatalf("NewStore() failed: %v", err)
+		}
+		if err := store.SaveEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+			t.Fatalf("SaveEnvelope() failed: %v", err)
+		}
+
+		tr := NewAsyncTransport(TransportOptions{
+			Dsn:          "http://key@" + server.URL[7:] + "/123",
+			OfflineCache: offline.Options{Dir: dir},
+		})
+		defer tr.Close()
+
+		select {
+		case <-received:
+		case <-time.After(testutils.FlushTimeout()):
+			t.Fatal("cached envelope was not replayed")
+		}
+	})
+
+	t.Run("keeps cached envelopes while rate limited", func(t *testing.T) {
+		var requests int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt64(&requests, 1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		aggregator := report.NewAggregator()
+		tr := NewAsyncTransport(TransportOptions{
+			Dsn:          "http://key@" + server.URL[7:] + "/123",
+			OfflineCache: offline.Options{Dir: t.TempDir()},
+			Recorder:     aggregator,
+		})
+		transport, ok := tr.(*AsyncTransport)
+		if !ok {
+			t.Fatalf("expected *AsyncTransport, got %T", tr)
+		}
+		defer transport.Close()
+
+		envelope := testEnvelope(protocol.EnvelopeItemTypeEvent)
+		transport.mu.Lock()
+		transport.limits[ratelimit.CategoryError] = ratelimit.Deadline(time.Now().Add(time.Minute))
+		transport.mu.Unlock()
+
+		if transport.replayEnvelope(envelope) {
+			t.Error("expected the envelope to be kept while rate limited")
+		}
+		if got := atomic.LoadInt64(&requests); got != 0 {
+			t.Errorf("expected no requests, got %d", got)
+		}
+		if r := aggregator.TakeReport(); r != nil {
+			t.Errorf("expected no discarded envelopes, got %v", r.DiscardedEvents)
+		}
+
+		transport.mu.Lock()
+		delete(transport.limits, ratelimit.CategoryError)
+		transport.mu.Unlock()
+		if !transport.replayEnvelope(envelope) {
+			t.Error("expected the envelope to be replayed once the rate limit is over")
+		}
+	})
+
+	t.Run("persists queued envelopes on close", func(t *testing.T) {
+		dir := t.TempDir()
+		transport := &AsyncTransport{
+			queue: make(chan *protocol.Envelope, 2),
+			done:  make(chan struct{}),
+		}
+		store, err := offline.NewStore(offline.Options{Dir: dir}, nil)
+		if err != nil {
+			t.Fatalf("NewStore() failed: %v", err)
+		}
+		transport.cache = store
+		transport.queue <- testEnvelope(protocol.EnvelopeItemTypeEvent)
+		transport.queue <- testEnvelope(protocol.EnvelopeItemTypeTransaction)
+
+		transport.Close()
+
+		if got := store.Len(); got != 2 {
+			t.Errorf("expected 2 persisted envelopes, got %d", got)
+		}
+	})
+}
+
+func TestAsyncTransport_Retry(t *testing.T) {
+	retry := Retry{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		responses    []int
+		retryAfter   string
+		wantRequests int64
+		wantReason   report.DiscardReason
+	}{
+		{"succeeds after transient errors", []int{503, 408, 200}, "", 3, ""},
+		{"gives up after retry budget", []int{500, 502, 503, 200}, "", 3, report.ReasonRetriesExhausted},
+		{"does not retry client errors", []int{400, 200}, "", 1, report.ReasonSendError},
+		{"does not wait longer than max backoff", []int{503, 200}, "60", 1, report.ReasonRetriesExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := atomic.AddInt64(&requests, 1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.responses[n-1])
+			}))
+			defer server.Close()
+
+			aggregator := report.NewAggregator()
+			tr := NewAsyncTransport(TransportOptions{
+				Dsn:      "http://key@" + server.URL[7:] + "/123",
+				Recorder: aggregator,
+				Retry:    retry,
+			})
+			defer tr.Close()
+
+			if err := tr.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+				t.Fatalf("failed to send envelope: %v", err)
+			}
+			if !tr.Flush(testutils.FlushTimeout()) {
+				t.Fatal("Flush timed out")
+			}
+
+			if got := atomic.LoadInt64(&requests); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			var discarded []report.DiscardedEvent
+			if r := aggregator.TakeReport(); r != nil {
+				discarded = r.DiscardedEvents
+			}
+			if tt.wantReason == "" {
+				if len(discarded) != 0 {
+					t.Errorf("unexpected discarded events: %v", discarded)
+				}
+				return
+			}
+			if len(discarded) != 1 || discarded[0].Reason != tt.wantReason || discarded[0].Category != ratelimit.CategoryError {
+				t.Errorf("discarded events = %v, want one error with reason %q", discarded, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestAsyncTransport_RetryDoesNotBlock(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	var failed int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte(`"type":"transaction"`)) {
+			atomic.AddInt64(&failed, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+
+	aggregator := report.NewAggregator()
+	tr := NewAsyncTransport(TransportOptions{
+		Dsn:      "http://key@" + server.URL[7:] + "/123",
+		Recorder: aggregator,
+		Retry:    Retry{MaxRetries: 2, InitialBackoff: time.Minute, MaxBackoff: time.Minute},
+	})
+
+	if err := tr.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeTransaction)); err != nil {
+		t.Fatalf("failed to send envelope: %v", err)
+	}
+	if err := tr.SendEnvelope(testEnvelope(protocol.EnvelopeItemTypeEvent)); err != nil {
+		t.Fatalf("failed to send envelope: %v", err)
+	}
+
+	// The second envelope is sent while the first one waits for its retry.
+	select {
+	case <-delivered:
+	case <-time.After(testutils.FlushTimeout()):
+		t.Fatal("envelope was blocked by the retry of another envelope")
+	}
+	if got := atomic.LoadInt64(&failed); got != 1 {
+		t.Errorf("failed requests = %d, want 1", got)
+	}
+
+	// Flushing waits for the retry, closing gives up on it.
+	if tr.Flush(100 * time.Millisecond) {
+		t.Error("Flush() = true, want false while an envelope waits for a retry")
+	}
+	tr.Close()
+	r := aggregator.TakeReport()
+	if r == nil || len(r.DiscardedEvents) != 1 || r.DiscardedEvents[0].Reason != report.ReasonRetriesExhausted {
+		t.Errorf("expected the retried envelope to be discarded with reason %q, got %v", report.ReasonRetriesExhausted, r)
+	}
+}
+
 func TestSyncTransport_SendEnvelope(t *testing.T) {
 	t.Run("invalid DSN", func(t *testing.T) {
 		transport := NewSyncTransport(TransportOptions{})
@@ -0,0 +1,388 @@
+// Package offline implements an on-disk store for envelopes that could not be
+// delivered to Sentry, so they can be replayed once connectivity returns.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/report"
+)
+
+const (
+	// DefaultMaxBytes is the default upper bound for the total size of all
+	// envelopes kept on disk.
+	DefaultMaxBytes int64 = 16 << 20
+	// DefaultMaxAge is the default age after which stored envelopes are
+	// discarded instead of replayed.
+	DefaultMaxAge = 24 * time.Hour
+
+	fileExtension = ".envelope"
+)
+
+// ErrStoreDisabled is returned when trying to use a nil Store.
+var ErrStoreDisabled = errors.New("offline store is disabled")
+
+// Options configures a Store.
+type Options struct {
+	// Dir is the directory envelopes are written to. The store is disabled
+	// when Dir is empty.
+	Dir string
+	// MaxBytes is the maximum total size of stored envelopes. When exceeded,
+	// the oldest envelopes are evicted. Defaults to DefaultMaxBytes.
+	MaxBytes int64
+	// MaxAge is the maximum age of a stored envelope. Older envelopes are
+	// evicted instead of replayed. Defaults to DefaultMaxAge.
+	MaxAge time.Duration
+}
+
+// DirForDsn returns the subdirectory of dir that stores envelopes addressed
+// to dsn, so that transports for different projects sharing a cache directory
+// never replay each other's envelopes. It returns dir if dir is empty.
+func DirForDsn(dir string, dsn *protocol.Dsn) string {
+	if dir == "" || dsn == nil {
+		return dir
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s-%s", dsn.GetHost(), dsn.GetProjectID()))
+}
+
+// Store persists serialized envelopes as individual files in a directory.
+//
+// A Store is safe for concurrent use. Files are written atomically, so
+// multiple processes may share a directory, although every envelope is then
+// replayed by whichever process reads it first.
+type Store struct {
+	dir      string
+	maxBytes int64
+	maxAge   time.Duration
+	recorder report.ClientReportRecorder
+
+	mu sync.Mutex
+	// sending is the name of the file Replay is sending, which must not be
+	// evicted in the meantime.
+	sending string
+
+	// replayMu serializes replays, so that an envelope is sent only once.
+	replayMu sync.Mutex
+	// pending is set whenever the store may contain envelopes to replay.
+	pending atomic.Bool
+}
+
+// NewStore creates the store directory if needed and returns a Store. It
+// returns nil and no error when options.Dir is empty.
+//
+// Evicted envelopes are recorded with report.ReasonCacheOverflow.
+func NewStore(options Options, recorder report.ClientReportRecorder) (*Store, error) {
+	if options.Dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(options.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("offline store: %w", err)
+	}
+
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = DefaultMaxBytes
+	}
+	if options.MaxAge <= 0 {
+		options.MaxAge = DefaultMaxAge
+	}
+	if recorder == nil {
+		recorder = report.NoopRecorder()
+	}
+
+	s := &Store{
+		dir:      options.Dir,
+		maxBytes: options.MaxBytes,
+		maxAge:   options.MaxAge,
+		recorder: recorder,
+	}
+	// Replay envelopes left over from a previous run.
+	s.pending.Store(true)
+	return s, nil
+}
+
+// Save writes a serialized envelope to disk and evicts the oldest envelopes
+// if the store grows beyond its size limit. Envelopes that are larger than
+// the limit on their own are rejected with an error and are not recorded, so
+// the caller can account for them.
+func (s *Store) Save(data []byte) error {
+	if s == nil {
+		return ErrStoreDisabled
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if int64(len(data)) > s.maxBytes {
+		return fmt.Errorf("offline store: envelope of %d bytes exceeds limit of %d bytes", len(data), s.maxBytes)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("offline store: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("offline store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("offline store: %w", err)
+	}
+
+	name := fmt.Sprintf("%020d-%s%s", time.Now().UnixNano(), protocol.GenerateEventID()[:8], fileExtension)
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("offline store: %w", err)
+	}
+
+	s.enforceLimits()
+	s.pending.Store(true)
+	return nil
+}
+
+// SaveEnvelope serializes and saves an envelope.
+func (s *Store) SaveEnvelope(envelope *protocol.Envelope) error {
+	if s == nil {
+		return ErrStoreDisabled
+	}
+	data, err := envelope.Serialize()
+	if err != nil {
+		return fmt.Errorf("offline store: %w", err)
+	}
+	return s.Save(data)
+}
+
+// Keep saves a serialized envelope that could not be delivered and reports
+// whether it was stored. It is safe to call on a nil Store, which keeps
+// nothing, so callers can fall back to recording the envelope as discarded.
+func (s *Store) Keep(data []byte) bool {
+	if s == nil {
+		return false
+	}
+	if err := s.Save(data); err != nil {
+		debuglog.Printf("Failed to store envelope in offline cache: %v", err)
+		return false
+	}
+	return true
+}
+
+// KeepEnvelope is like Keep, for an envelope that is not serialized yet.
+func (s *Store) KeepEnvelope(envelope *protocol.Envelope) bool {
+	if s == nil {
+		return false
+	}
+	if err := s.SaveEnvelope(envelope); err != nil {
+		debuglog.Printf("Failed to store envelope in offline cache: %v", err)
+		return false
+	}
+	return true
+}
+
+// Len returns the number of envelopes currently stored.
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.files())
+}
+
+// Replay passes stored envelopes to send, oldest first, and returns the
+// number of envelopes that were handed off.
+//
+// send reports whether the envelope was handled, either because it was
+// delivered or because it was dropped for good. Handled envelopes are removed
+// from disk. Replay stops at the first envelope that was not handled, which
+// typically means Sentry is still unreachable. Envelopes older than the
+// configured maximum age are evicted instead of replayed.
+//
+// The store is not locked while send runs, so envelopes can be saved while
+// others are sent.
+func (s *Store) Replay(send func(envelope *protocol.Envelope) bool) int {
+	if s == nil {
+		return 0
+	}
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	s.mu.Lock()
+	files := s.files()
+	s.mu.Unlock()
+
+	replayed := 0
+	for _, f := range files {
+		envelope, ok := s.claim(f)
+		if !ok {
+			continue
+		}
+
+		handled := send(envelope)
+
+		s.mu.Lock()
+		s.sending = ""
+		if handled {
+			s.remove(f)
+		}
+		s.mu.Unlock()
+
+		if !handled {
+			break
+		}
+		replayed++
+	}
+	return replayed
+}
+
+// claim reads a stored envelope for Replay and protects it from eviction
+// until Replay is done with it. It reports false if the envelope was removed
+// in the meantime, has expired or cannot be read.
+func (s *Store) claim(f storedFile) (*protocol.Envelope, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(f.modTime) > s.maxAge {
+		debuglog.Printf("Evicting expired envelope from offline store: %s", f.name)
+		s.evict(f)
+		return nil, false
+	}
+
+	data, err := os.ReadFile(f.path())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			debuglog.Printf("Failed to read envelope from offline store: %v", err)
+		}
+		return nil, false
+	}
+	envelope, err := protocol.ParseEnvelope(data)
+	if err != nil {
+		debuglog.Printf("Removing unreadable envelope from offline store %s: %v", f.name, err)
+		s.remove(f)
+		return nil, false
+	}
+
+	s.sending = f.name
+	return envelope, true
+}
+
+// ReplayPending works like Replay, but does nothing unless envelopes were
+// saved since the last replay that emptied the store, so transports can call
+// it whenever Sentry may be reachable again. It is safe to call on a nil
+// Store.
+func (s *Store) ReplayPending(send func(envelope *protocol.Envelope) bool) {
+	if s == nil || !s.pending.Load() {
+		return
+	}
+	// Clear the flag first, so that envelopes saved during the replay set it
+	// again.
+	s.pending.Store(false)
+	if n := s.Replay(send); n > 0 {
+		debuglog.Printf("Replayed %d envelopes from offline cache", n)
+	}
+	if s.Len() > 0 {
+		s.pending.Store(true)
+	}
+}
+
+type storedFile struct {
+	dir     string
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (f storedFile) path() string {
+	return filepath.Join(f.dir, f.name)
+}
+
+// files lists stored envelopes, oldest first. Must be called with s.mu held.
+func (s *Store) files() []storedFile {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		debuglog.Printf("Failed to list offline store: %v", err)
+		return nil
+	}
+
+	files := make([]storedFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, storedFile{
+			dir:     s.dir,
+			name:    entry.Name(),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+	// os.ReadDir sorts by file name, which starts with the creation time.
+	return files
+}
+
+// enforceLimits evicts expired envelopes and then the oldest envelopes until
+// the store fits within its size limit. The envelope that Replay is sending
+// is left alone, it is removed once delivered. Must be called with s.mu held.
+func (s *Store) enforceLimits() {
+	files := s.files()
+
+	var total int64
+	kept := files[:0]
+	for _, f := range files {
+		if f.name == s.sending {
+			continue
+		}
+		if time.Since(f.modTime) > s.maxAge {
+			s.evict(f)
+			continue
+		}
+		total += f.size
+		kept = append(kept, f)
+	}
+
+	for _, f := range kept {
+		if total <= s.maxBytes {
+			break
+		}
+		debuglog.Printf("Offline store exceeds %d bytes, evicting %s", s.maxBytes, f.name)
+		s.evict(f)
+		total -= f.size
+	}
+}
+
+// evict removes a stored envelope and records its items as discarded.
+func (s *Store) evict(f storedFile) {
+	if data, err := os.ReadFile(f.path()); err == nil {
+		s.evictData(data)
+	}
+	s.remove(f)
+}
+
+func (s *Store) evictData(data []byte) {
+	envelope, err := protocol.ParseEnvelope(data)
+	if err != nil {
+		return
+	}
+	s.recorder.RecordForEnvelope(report.ReasonCacheOverflow, envelope)
+}
+
+func (s *Store) remove(f storedFile) {
+	if err := os.Remove(f.path()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		debuglog.Printf("Failed to remove envelope from offline store: %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
+)
+
+func testEnvelope(t *testing.T, eventID string) []byte {
+	t.Helper()
+	envelope := protocol.NewEnvelope(&protocol.EnvelopeHeader{EventID: eventID})
+	envelope.AddItem(protocol.NewEnvelopeItem(protocol.EnvelopeItemTypeEvent, []byte(`{"message":"test"}`)))
+	data, err := envelope.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize() failed: %v", err)
+	}
+	return data
+}
+
+func discarded(a *report.Aggregator, reason report.DiscardReason, category ratelimit.Category) int64 {
+	r := a.TakeReport()
+	if r == nil {
+		return 0
+	}
+	var total int64
+	for _, e := range r.DiscardedEvents {
+		if e.Reason == reason && e.Category == category {
+			total += e.Quantity
+		}
+	}
+	return total
+}
+
+func TestNewStore_Disabled(t *testing.T) {
+	store, err := NewStore(Options{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store != nil {
+		t.Fatal("expected nil store for empty directory")
+	}
+	if err := store.Save([]byte("x")); err != ErrStoreDisabled {
+		t.Errorf("Save() on nil store = %v, want ErrStoreDisabled", err)
+	}
+	if store.Len() != 0 {
+		t.Error("Len() on nil store should be 0")
+	}
+	if n := store.Replay(func(*protocol.Envelope) bool { return true }); n != 0 {
+		t.Errorf("Replay() on nil store = %d, want 0", n)
+	}
+}
+
+func TestStore_SaveAndReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	store, err := NewStore(Options{Dir: dir}, nil)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+
+	for _, id := range []string{"first", "second", "third"} {
+		if err := store.Save(testEnvelope(t, id)); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+	}
+	if got := store.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+
+	// Simulate Sentry becoming unreachable after the first envelope.
+	var replayed []string
+	n := store.Replay(func(envelope *protocol.Envelope) bool {
+		replayed = append(replayed, envelope.Header.EventID)
+		return len(replayed) < 2
+	})
+	if n != 1 {
+		t.Errorf("Replay() = %d, want 1", n)
+	}
+	if len(replayed) != 2 || replayed[0] != "first" || replayed[1] != "second" {
+		t.Errorf("unexpected replay order: %v", replayed)
+	}
+	if got := store.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+
+	replayed = nil
+	n = store.Replay(func(envelope *protocol.Envelope) bool {
+		replayed = append(replayed, envelope.Header.EventID)
+		return true
+	})
+	if n != 2 {
+		t.Errorf("Replay() = %d, want 2", n)
+	}
+	if len(replayed) != 2 || replayed[0] != "second" || replayed[1] != "third" {
+		t.Errorf("unexpected replay order: %v", replayed)
+	}
+	if got := store.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+}
+
+func TestStore_EvictsOldestOverMaxBytes(t *testing.T) {
+	aggregator := report.NewAggregator()
+	envelopeSize := int64(len(testEnvelope(t, "sizing")))
+
+	store, err := NewStore(Options{Dir: t.TempDir(), MaxBytes: 2 * envelopeSize}, aggregator)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+
+	for _, id := range []string{"first", "second", "third"} {
+		if err := store.Save(testEnvelope(t, id)); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+		// Ensure distinct, ordered file names on platforms with coarse clocks.
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := store.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+	if got := discarded(aggregator, report.ReasonCacheOverflow, ratelimit.CategoryError); got != 1 {
+		t.Errorf("cache_overflow errors = %d, want 1", got)
+	}
+
+	var replayed []string
+	store.Replay(func(envelope *protocol.Envelope) bool {
+		replayed = append(replayed, envelope.Header.EventID)
+		return true
+	})
+	if len(replayed) != 2 || replayed[0] != "second" || replayed[1] != "third" {
+		t.Errorf("expected oldest envelope to be evicted, replayed %v", replayed)
+	}
+}
+
+func TestStore_RejectsOversizedEnvelope(t *testing.T) {
+	aggregator := report.NewAggregator()
+	store, err := NewStore(Options{Dir: t.TempDir(), MaxBytes: 10}, aggregator)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+
+	if err := store.Save(testEnvelope(t, "big")); err == nil {
+		t.Error("expected an error for an envelope larger than MaxBytes")
+	}
+	if got := store.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+	if got := discarded(aggregator, report.ReasonCacheOverflow, ratelimit.CategoryError); got != 0 {
+		t.Errorf("rejected envelopes should be recorded by the caller, got %d cache_overflow errors", got)
+	}
+}
+
+func TestStore_EvictsExpiredEnvelopes(t *testing.T) {
+	aggregator := report.NewAggregator()
+	dir := t.TempDir()
+	store, err := NewStore(Options{Dir: dir, MaxAge: time.Hour}, aggregator)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+
+	if err := store.Save(testEnvelope(t, "old")); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one stored file, got %d (%v)", len(entries), err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, entries[0].Name()), past, past); err != nil {
+		t.Fatalf("Chtimes() failed: %v", err)
+	}
+
+	called := false
+	n := store.Replay(func(*protocol.Envelope) bool {
+		called = true
+		return true
+	})
+	if called || n != 0 {
+		t.Error("expired envelope should not be replayed")
+	}
+	if got := store.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+	if got := discarded(aggregator, report.ReasonCacheOverflow, ratelimit.CategoryError); got != 1 {
+		t.Errorf("cache_overflow errors = %d, want 1", got)
+	}
+}
+
+func TestStore_RemovesUnreadableEnvelopes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(Options{Dir: dir}, nil)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000001-corrupt"+fileExtension), []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	if err := store.Save(testEnvelope(t, "valid")); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	var replayed []string
+	store.Replay(func(envelope *protocol.Envelope) bool {
+		replayed = append(replayed, envelope.Header.EventID)
+		return true
+	})
+	if len(replayed) != 1 || replayed[0] != "valid" {
+		t.Errorf("replayed = %v, want [valid]", replayed)
+	}
+	if got := store.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+}
+
+func TestStore_SaveDuringReplay(t *testing.T) {
+	envelopeSize := int64(len(testEnvelope(t, "sizing")))
+	store, err := NewStore(Options{Dir: t.TempDir(), MaxBytes: envelopeSize}, nil)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+	if err := store.Save(testEnvelope(t, "replay")); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		done <- store.Replay(func(*protocol.Envelope) bool {
+			close(sending)
+			<-release
+			return true
+		})
+	}()
+	<-sending
+
+	// Saving must not wait for the envelope being sent, and must not evict
+	// it even though the store is full.
+	saved := make(chan error)
+	go func() { saved <- store.Save(testEnvelope(t, "stored")) }()
+	select {
+	case err := <-saved:
+		if err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Save() blocked while an envelope was replayed")
+	}
+	if got := store.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+
+	close(release)
+	if n := <-done; n != 1 {
+		t.Errorf("Replay() = %d, want 1", n)
+	}
+
+	var replayed []string
+	store.Replay(func(envelope *protocol.Envelope) bool {
+		replayed = append(replayed, envelope.Header.EventID)
+		return true
+	})
+	if len(replayed) != 1 || replayed[0] != "stored" {
+		t.Errorf("replayed = %v, want [stored]", replayed)
+	}
+}
+
+func TestStore_ReplayPending(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(Options{Dir: dir}, nil)
+	if err != nil {
+		t.Fatalf("NewStore() failed: %v", err)
+	}
+
+	var sent int
+	send := func(*protocol.Envelope) bool {
+		sent++
+		return true
+	}
+
+	// A new store replays whatever a previous run left behind.
+	store.ReplayPending(send)
+	if sent != 0 {
+		t.Fatalf("sent = %d, want 0", sent)
+	}
+
+	if !store.Keep(testEnvelope(t, "kept")) {
+		t.Fatal("Keep() = false, want true")
+	}
+	// Envelopes written by another process are picked up by the next replay
+	// after something was kept.
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000001-other"+fileExtension), testEnvelope(t, "other"), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	store.ReplayPending(send)
+	if sent != 2 {
+		t.Fatalf("sent = %d, want 2", sent)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "00000000000000000002-other"+fileExtension), testEnvelope(t, "other"), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	store.ReplayPending(send)
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2: the store was replayed although nothing was kept", sent)
+	}
+
+	var nilStore *Store
+	if nilStore.Keep(testEnvelope(t, "nil")) {
+		t.Error("Keep() on nil store = true, want false")
+	}
+	nilStore.ReplayPending(send)
+}
@@ -0,0 +1,399 @@
+// Package profile decodes the profiles written by runtime/pprof.
+//
+// Only the parts of the format needed to convert CPU samples to Sentry
+// profiles are decoded: the stacks, values and labels of samples, and the
+// time range of the profile.
+//
+// See https://github.com/google/pprof/blob/main/proto/profile.proto.
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// A Profile is a decoded pprof profile.
+type Profile struct {
+	// Samples are the samples of the profile, in the order they were written.
+	Samples []Sample
+	// Start is the time the profile was started at.
+	Start time.Time
+	// Duration is the duration of the profile.
+	Duration time.Duration
+	// Period is the interval between samples, in the unit of the sample
+	// values. For CPU profiles, it is the sampling interval in nanoseconds.
+	Period int64
+}
+
+// A Sample is a stack and the values measured for it.
+type Sample struct {
+	// Stack is the stack of the sample, the innermost frame first.
+	Stack []Frame
+	// Values are the values of the sample, in the order of the sample types
+	// of the profile. For CPU profiles, they are the number of samples and
+	// the CPU time in nanoseconds.
+	Values []int64
+	// Labels are the string labels of the sample, as set with runtime/pprof.
+	Labels map[string]string
+}
+
+// A Frame is a source line of a stack. Inlined calls get a frame each.
+type Frame struct {
+	// Function is the package path-qualified name of the function.
+	Function string
+	// File is the path of the source file.
+	File string
+	// Line is the line number in File.
+	Line int
+}
+
+// Parse decodes a profile, gzipped or not, as written by runtime/pprof.
+func Parse(data []byte) (*Profile, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("profile: %w", err)
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("profile: %w", err)
+		}
+	}
+
+	var p rawProfile
+	if err := p.decode(data); err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	profile, err := p.resolve()
+	if err != nil {
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+	return profile, nil
+}
+
+// Field numbers of the messages in profile.proto.
+const (
+	fieldProfileSample        = 2
+	fieldProfileLocation      = 4
+	fieldProfileFunction      = 5
+	fieldProfileStringTable   = 6
+	fieldProfileTimeNanos     = 9
+	fieldProfileDurationNanos = 10
+	fieldProfilePeriod        = 12
+
+	fieldSampleLocationID = 1
+	fieldSampleValue      = 2
+	fieldSampleLabel      = 3
+
+	fieldLabelKey = 1
+	fieldLabelStr = 2
+
+	fieldLocationID   = 1
+	fieldLocationLine = 4
+
+	fieldLineFunctionID = 1
+	fieldLineLine       = 2
+
+	fieldFunctionID       = 1
+	fieldFunctionName     = 2
+	fieldFunctionFilename = 4
+)
+
+// rawProfile holds the messages of a profile before references between them
+// are resolved, as they can appear in any order.
+type rawProfile struct {
+	samples       []rawSample
+	locations     map[uint64][]rawLine
+	functions     map[uint64]rawFunction
+	strings       []string
+	timeNanos     int64
+	durationNanos int64
+	period        int64
+}
+
+type rawSample struct {
+	locationIDs []uint64
+	values      []int64
+	labels      [][2]int64
+}
+
+type rawLine struct {
+	functionID uint64
+	line       int64
+}
+
+type rawFunction struct {
+	name     int64
+	filename int64
+}
+
+func (p *rawProfile) decode(data []byte) error {
+	p.locations = make(map[uint64][]rawLine)
+	p.functions = make(map[uint64]rawFunction)
+	return decodeMessage(data, func(field int, value uint64, msg []byte) error {
+		switch field {
+		case fieldProfileSample:
+			var s rawSample
+			if err := s.decode(msg); err != nil {
+				return err
+			}
+			p.samples = append(p.samples, s)
+		case fieldProfileLocation:
+			var id uint64
+			var lines []rawLine
+			err := decodeMessage(msg, func(field int, value uint64, msg []byte) error {
+				switch field {
+				case fieldLocationID:
+					id = value
+				case fieldLocationLine:
+					var l rawLine
+					if err := l.decode(msg); err != nil {
+						return err
+					}
+					lines = append(lines, l)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			p.locations[id] = lines
+		case fieldProfileFunction:
+			var id uint64
+			var f rawFunction
+			err := decodeMessage(msg, func(field int, value uint64, _ []byte) error {
+				switch field {
+				case fieldFunctionID:
+					id = value
+				case fieldFunctionName:
+					f.name = int64(value)
+				case fieldFunctionFilename:
+					f.filename = int64(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			p.functions[id] = f
+		case fieldProfileStringTable:
+			p.strings = append(p.strings, string(msg))
+		case fieldProfileTimeNanos:
+			p.timeNanos = int64(value)
+		case fieldProfileDurationNanos:
+			p.durationNanos = int64(value)
+		case fieldProfilePeriod:
+			p.period = int64(value)
+		}
+		return nil
+	})
+}
+
+func (s *rawSample) decode(data []byte) error {
+	return decodeMessage(data, func(field int, value uint64, msg []byte) error {
+		switch field {
+		case fieldSampleLocationID:
+			if msg == nil {
+				s.locationIDs = append(s.locationIDs, value)
+				return nil
+			}
+			return decodePacked(msg, func(v uint64) {
+				s.locationIDs = append(s.locationIDs, v)
+			})
+		case fieldSampleValue:
+			if msg == nil {
+				s.values = append(s.values, int64(value))
+				return nil
+			}
+			return decodePacked(msg, func(v uint64) {
+				s.values = append(s.values, int64(v))
+			})
+		case fieldSampleLabel:
+			var label [2]int64
+			err := decodeMessage(msg, func(field int, value uint64, _ []byte) error {
+				switch field {
+				case fieldLabelKey:
+					label[0] = int64(value)
+				case fieldLabelStr:
+					label[1] = int64(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			s.labels = append(s.labels, label)
+		}
+		return nil
+	})
+}
+
+func (l *rawLine) decode(data []byte) error {
+	return decodeMessage(data, func(field int, value uint64, _ []byte) error {
+		switch field {
+		case fieldLineFunctionID:
+			l.functionID = value
+		case fieldLineLine:
+			l.line = int64(value)
+		}
+		return nil
+	})
+}
+
+// resolve replaces the references between messages with their values.
+func (p *rawProfile) resolve() (*Profile, error) {
+	str := func(i int64) (string, error) {
+		if i < 0 || i >= int64(len(p.strings)) {
+			return "", fmt.Errorf("string index %d out of range", i)
+		}
+		return p.strings[i], nil
+	}
+
+	// Locations are shared by many samples, resolve each of them once.
+	frames := make(map[uint64][]Frame, len(p.locations))
+	for id, lines := range p.locations {
+		location := make([]Frame, 0, len(lines))
+		for _, line := range lines {
+			function, ok := p.functions[line.functionID]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %d", line.functionID)
+			}
+			name, err := str(function.name)
+			if err != nil {
+				return nil, err
+			}
+			file, err := str(function.filename)
+			if err != nil {
+				return nil, err
+			}
+			location = append(location, Frame{Function: name, File: file, Line: int(line.line)})
+		}
+		frames[id] = location
+	}
+
+	profile := &Profile{
+		Samples:  make([]Sample, 0, len(p.samples)),
+		Start:    time.Unix(0, p.timeNanos),
+		Duration: time.Duration(p.durationNanos),
+		Period:   p.period,
+	}
+	for _, s := range p.samples {
+		sample := Sample{Values: s.values}
+		for _, id := range s.locationIDs {
+			location, ok := frames[id]
+			if !ok {
+				return nil, fmt.Errorf("unknown location %d", id)
+			}
+			sample.Stack = append(sample.Stack, location...)
+		}
+		for _, label := range s.labels {
+			key, err := str(label[0])
+			if err != nil {
+				return nil, err
+			}
+			// Numeric labels have no string value.
+			if label[1] == 0 {
+				continue
+			}
+			value, err := str(label[1])
+			if err != nil {
+				return nil, err
+			}
+			if sample.Labels == nil {
+				sample.Labels = make(map[string]string)
+			}
+			sample.Labels[key] = value
+		}
+		profile.Samples = append(profile.Samples, sample)
+	}
+	return profile, nil
+}
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated message")
+
+// decodeMessage calls fn for every field of a protocol buffer message. For
+// varint fields, value is set and msg is nil. For length-delimited fields,
+// msg is set. Fixed-size fields are skipped.
+func decodeMessage(data []byte, fn func(field int, value uint64, msg []byte) error) error {
+	for len(data) > 0 {
+		key, n := decodeVarint(data)
+		if n == 0 {
+			return errTruncated
+		}
+		data = data[n:]
+		field, wireType := int(key>>3), key&7
+
+		switch wireType {
+		case wireVarint:
+			value, n := decodeVarint(data)
+			if n == 0 {
+				return errTruncated
+			}
+			data = data[n:]
+			if err := fn(field, value, nil); err != nil {
+				return err
+			}
+		case wireBytes:
+			length, n := decodeVarint(data)
+			if n == 0 || uint64(len(data)-n) < length {
+				return errTruncated
+			}
+			msg := data[n : n+int(length)]
+			data = data[n+int(length):]
+			if err := fn(field, 0, msg); err != nil {
+				return err
+			}
+		case wireFixed64:
+			if len(data) < 8 {
+				return errTruncated
+			}
+			data = data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return errTruncated
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+	}
+	return nil
+}
+
+// decodePacked calls fn for every value of a packed repeated varint field.
+func decodePacked(data []byte, fn func(uint64)) error {
+	for len(data) > 0 {
+		value, n := decodeVarint(data)
+		if n == 0 {
+			return errTruncated
+		}
+		data = data[n:]
+		fn(value)
+	}
+	return nil
+}
+
+// decodeVarint returns a varint and its length, or a length of 0 if data
+// does not start with a valid varint.
+func decodeVarint(data []byte) (uint64, int) {
+	var value uint64
+	for i := 0; i < len(data) && i < 10; i++ {
+		b := data[i]
+		value |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
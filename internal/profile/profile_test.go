@@ -0,0 +1,103 @@
+package profile
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+func blockWithLabels(ready chan<- struct{}, release <-chan struct{}) {
+	pprof.Do(context.Background(), pprof.Labels("span_id", "b0e6f15b45c36b12"), func(context.Context) {
+		close(ready)
+		<-release
+	})
+}
+
+func TestParse(t *testing.T) {
+	ready, release := make(chan struct{}), make(chan struct{})
+	go blockWithLabels(ready, release)
+	defer close(release)
+	<-ready
+
+	// Goroutine profiles are written in the same format as CPU profiles, but
+	// contain deterministic samples.
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Start.IsZero() || time.Since(p.Start) > time.Minute {
+		t.Errorf("Start = %v, want a recent time", p.Start)
+	}
+	var found bool
+	for _, sample := range p.Samples {
+		if sample.Labels["span_id"] != "b0e6f15b45c36b12" {
+			continue
+		}
+		found = true
+		if len(sample.Values) != 1 || sample.Values[0] != 1 {
+			t.Errorf("Values = %v, want [1]", sample.Values)
+		}
+		var inBlock bool
+		for _, frame := range sample.Stack {
+			if strings.HasSuffix(frame.Function, "profile.blockWithLabels.func1") {
+				inBlock = true
+				if !strings.HasSuffix(frame.File, "profile_test.go") || frame.Line == 0 {
+					t.Errorf("frame = %+v, want a line of profile_test.go", frame)
+				}
+			}
+		}
+		if !inBlock {
+			t.Errorf("Stack = %+v, want a frame of blockWithLabels", sample.Stack)
+		}
+		if !strings.HasPrefix(sample.Stack[0].Function, "runtime.") {
+			t.Errorf("Stack[0] = %+v, want the innermost frame first", sample.Stack[0])
+		}
+	}
+	if !found {
+		t.Errorf("no sample with the span_id label in %d samples", len(p.Samples))
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"truncated varint": {0x08},
+		"truncated bytes":  {0x12, 0x05, 0x01},
+		"bad gzip":         {0x1f, 0x8b, 0x00},
+		"bad string index": {0x12, 0x04, 0x1a, 0x02, 0x08, 0x05},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(data); err == nil {
+				t.Error("Parse() returned no error")
+			}
+		})
+	}
+}
+
+func TestDecodeVarint(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		value uint64
+		n     int
+	}{
+		{[]byte{0x00}, 0, 1},
+		{[]byte{0x96, 0x01}, 150, 2},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, 1<<64 - 1, 10},
+		{[]byte{0x96}, 0, 0},
+		{nil, 0, 0},
+	}
+	for _, tt := range tests {
+		value, n := decodeVarint(tt.data)
+		if value != tt.value || n != tt.n {
+			t.Errorf("decodeVarint(%x) = %d, %d, want %d, %d", tt.data, value, n, tt.value, tt.n)
+		}
+	}
+}
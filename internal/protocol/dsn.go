@@ -223,6 +223,21 @@ func (dsn Dsn) GetAPIURL() *url.URL {
 	return parsedURL
 }
 
+// AuthHeader returns the value of the X-Sentry-Auth header that authenticates
+// envelopes sent by the given SDK to the /envelope endpoint.
+func (dsn Dsn) AuthHeader(sdkName, sdkVersion string) string {
+	auth := fmt.Sprintf("Sentry sentry_version=%s, "+
+		"sentry_client=%s/%s, sentry_key=%s", apiVersion, sdkName, sdkVersion, dsn.publicKey)
+
+	// The key sentry_secret is effectively deprecated and no longer needs to be set.
+	// However, since it was required in older self-hosted versions,
+	// it should still be passed through to Sentry if set.
+	if dsn.secretKey != "" {
+		auth = fmt.Sprintf("%s, sentry_secret=%s", auth, dsn.secretKey)
+	}
+	return auth
+}
+
 // RequestHeaders returns all the necessary headers that have to be used in the transport when sending events
 // to the /store endpoint.
 //
@@ -3,11 +3,16 @@ package protocol
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"time"
 )
 
+// ErrMalformedEnvelope is returned by ParseEnvelope when the data does not
+// follow the envelope format.
+var ErrMalformedEnvelope = errors.New("malformed envelope")
+
 // Envelope represents a Sentry envelope containing headers and items.
 type Envelope struct {
 	Header *EnvelopeHeader `json:"-"`
@@ -36,6 +41,33 @@ type EnvelopeHeader struct {
 	Trace map[string]string `json:"trace,omitempty"`
 }
 
+// Readdress returns a copy of the header addressed to dsn. The trace header is
+// updated with ReaddressTrace.
+func (h *EnvelopeHeader) Readdress(dsn *Dsn) *EnvelopeHeader {
+	header := *h
+	header.Dsn = dsn
+	header.Trace = ReaddressTrace(h.Trace, h.Dsn, dsn)
+	return &header
+}
+
+// ReaddressTrace returns the dynamic sampling context entries in trace for an
+// envelope that is sent to the project of to instead of from.
+//
+// The public key identifies the project whose dynamic sampling rules apply, so
+// it is replaced if it was set for from. A trace that was propagated from
+// another project keeps its public key. trace itself is never modified.
+func ReaddressTrace(trace map[string]string, from, to *Dsn) map[string]string {
+	if from == nil || to == nil || trace["public_key"] != from.GetPublicKey() {
+		return trace
+	}
+	readdressed := make(map[string]string, len(trace))
+	for k, v := range trace {
+		readdressed[k] = v
+	}
+	readdressed["public_key"] = to.GetPublicKey()
+	return readdressed
+}
+
 // EnvelopeItemType represents the type of envelope item.
 type EnvelopeItemType string
 
@@ -47,6 +79,9 @@ const (
 	EnvelopeItemTypeAttachment   EnvelopeItemType = "attachment"
 	EnvelopeItemTypeLog          EnvelopeItemType = "log"
 	EnvelopeItemTypeTraceMetric  EnvelopeItemType = "trace_metric"
+	EnvelopeItemTypeSpan         EnvelopeItemType = "span"
+	EnvelopeItemTypeProfile      EnvelopeItemType = "profile"
+	EnvelopeItemTypeProfileChunk EnvelopeItemType = "profile_chunk"
 	EnvelopeItemTypeClientReport EnvelopeItemType = "client_report"
 )
 
@@ -67,9 +102,13 @@ type EnvelopeItemHeader struct {
 	// ContentType is the MIME type of the item payload (used for attachments and some other item types)
 	ContentType string `json:"content_type,omitempty"`
 
-	// ItemCount is the number of items in a batch (used for logs)
+	// ItemCount is the number of items in a batch (used for logs, metrics and spans)
 	ItemCount *int `json:"item_count,omitempty"`
 
+	// Platform is the platform of a profile chunk, which selects its rate
+	// limit category on the server.
+	Platform string `json:"platform,omitempty"`
+
 	// SpanCount is the number of spans in a transaction (used for client reports)
 	SpanCount int `json:"-"`
 }
@@ -177,6 +216,107 @@ func (e *Envelope) Size() (int, error) {
 	return len(data), nil
 }
 
+// ParseEnvelope parses data in the Sentry envelope format, as produced by
+// Serialize, back into an Envelope.
+//
+// Items with an explicit length are read by length, other items extend to the
+// next newline. Span counts of transaction items are restored from their
+// payload so that client reports remain accurate.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	envelope, _, err := parseEnvelope(data, false)
+	return envelope, err
+}
+
+// ParseEnvelopes parses a sequence of envelopes written back to back, for
+// example by repeatedly calling WriteTo on the same file.
+//
+// A line that follows a complete item and has no "type" field is read as the
+// header of the next envelope. Items must therefore always carry their type,
+// which is the case for all envelopes produced by this SDK.
+func ParseEnvelopes(data []byte) ([]*Envelope, error) {
+	var envelopes []*Envelope
+	for rest := data; len(bytes.TrimSpace(rest)) > 0; {
+		rest = bytes.TrimLeft(rest, "\r\n")
+		envelope, next, err := parseEnvelope(rest, true)
+		if err != nil {
+			return envelopes, fmt.Errorf("envelope %d: %w", len(envelopes)+1, err)
+		}
+		envelopes = append(envelopes, envelope)
+		rest = next
+	}
+	return envelopes, nil
+}
+
+// parseEnvelope parses a single envelope from the start of data. In stream
+// mode, it stops at the header of the next envelope and returns the data that
+// was not consumed.
+func parseEnvelope(data []byte, stream bool) (*Envelope, []byte, error) {
+	headerLine, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok && len(bytes.TrimSpace(headerLine)) == 0 {
+		return nil, nil, fmt.Errorf("%w: missing header", ErrMalformedEnvelope)
+	}
+
+	var header EnvelopeHeader
+	if err := json.Unmarshal(headerLine, &header); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid header: %w", ErrMalformedEnvelope, err)
+	}
+	envelope := NewEnvelope(&header)
+
+	for len(bytes.TrimSpace(rest)) > 0 {
+		remaining := rest
+		var itemHeaderLine []byte
+		itemHeaderLine, rest, _ = bytes.Cut(rest, []byte("\n"))
+		if len(bytes.TrimSpace(itemHeaderLine)) == 0 {
+			continue
+		}
+
+		var itemHeader EnvelopeItemHeader
+		if err := json.Unmarshal(itemHeaderLine, &itemHeader); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid item header: %w", ErrMalformedEnvelope, err)
+		}
+		if stream && itemHeader.Type == "" {
+			return envelope, remaining, nil
+		}
+
+		var payload []byte
+		if itemHeader.Length != nil {
+			length := *itemHeader.Length
+			if length < 0 || length > len(rest) {
+				return nil, nil, fmt.Errorf("%w: item length %d out of range", ErrMalformedEnvelope, length)
+			}
+			payload, rest = rest[:length], rest[length:]
+			rest = bytes.TrimPrefix(rest, []byte("\n"))
+		} else {
+			payload, rest, _ = bytes.Cut(rest, []byte("\n"))
+			length := len(payload)
+			itemHeader.Length = &length
+		}
+
+		if itemHeader.Type == EnvelopeItemTypeTransaction {
+			itemHeader.SpanCount = TransactionSpanCount(payload)
+		}
+
+		envelope.AddItem(&EnvelopeItem{
+			Header:  &itemHeader,
+			Payload: bytes.Clone(payload),
+		})
+	}
+
+	return envelope, nil, nil
+}
+
+// TransactionSpanCount returns the number of spans in a serialized
+// transaction, including the transaction itself.
+func TransactionSpanCount(payload []byte) int {
+	var transaction struct {
+		Spans []json.RawMessage `json:"spans"`
+	}
+	if err := json.Unmarshal(payload, &transaction); err != nil {
+		return 0
+	}
+	return len(transaction.Spans) + 1
+}
+
 // NewEnvelopeItem creates a new envelope item with the specified type and payload.
 func NewEnvelopeItem(itemType EnvelopeItemType, payload []byte) *EnvelopeItem {
 	length := len(payload)
@@ -245,6 +385,33 @@ func NewTraceMetricItem(itemCount int, payload []byte) *EnvelopeItem {
 	}
 }
 
+// NewSpanItem creates a new envelope item for a batch of streamed spans.
+func NewSpanItem(itemCount int, payload []byte) *EnvelopeItem {
+	length := len(payload)
+	return &EnvelopeItem{
+		Header: &EnvelopeItemHeader{
+			Type:        EnvelopeItemTypeSpan,
+			Length:      &length,
+			ItemCount:   &itemCount,
+			ContentType: "application/vnd.sentry.items.span.v2+json",
+		},
+		Payload: payload,
+	}
+}
+
+// NewProfileChunkItem creates a new envelope item for a profile chunk.
+func NewProfileChunkItem(platform string, payload []byte) *EnvelopeItem {
+	length := len(payload)
+	return &EnvelopeItem{
+		Header: &EnvelopeItemHeader{
+			Type:     EnvelopeItemTypeProfileChunk,
+			Length:   &length,
+			Platform: platform,
+		},
+		Payload: payload,
+	}
+}
+
 // NewClientReportItem creates a new envelope item for client reports.
 func NewClientReportItem(payload []byte) *EnvelopeItem {
 	length := len(payload)
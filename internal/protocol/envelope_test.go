@@ -3,6 +3,7 @@ package protocol
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -184,3 +185,193 @@ func TestEnvelope_Size(t *testing.T) {
 		t.Errorf("Size() = %d, but Serialize() length = %d", size2, len(data))
 	}
 }
+
+func TestParseEnvelope(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		header := &EnvelopeHeader{
+			EventID: "9ec79c33ec9942ab8353589fcb2e04dc",
+			SentAt:  time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+			Sdk:     &SdkInfo{Name: "sentry.go", Version: "1.0.0"},
+			Trace:   map[string]string{"trace_id": "abc"},
+		}
+		envelope := NewEnvelope(header)
+		envelope.AddItem(NewTransactionItem(3, []byte(`{"type":"transaction","spans":[{},{}]}`)))
+		envelope.AddItem(NewAttachmentItem("file.txt", "text/plain", []byte("line1\nline2")))
+		envelope.AddItem(NewLogItem(2, []byte(`{"items":[{},{}]}`)))
+
+		data, err := envelope.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize() failed: %v", err)
+		}
+
+		parsed, err := ParseEnvelope(data)
+		if err != nil {
+			t.Fatalf("ParseEnvelope() failed: %v", err)
+		}
+
+		if parsed.Header.EventID != header.EventID {
+			t.Errorf("EventID = %q, want %q", parsed.Header.EventID, header.EventID)
+		}
+		if !parsed.Header.SentAt.Equal(header.SentAt) {
+			t.Errorf("SentAt = %v, want %v", parsed.Header.SentAt, header.SentAt)
+		}
+		if parsed.Header.Sdk == nil || parsed.Header.Sdk.Name != "sentry.go" {
+			t.Errorf("Sdk = %+v, want name sentry.go", parsed.Header.Sdk)
+		}
+		if parsed.Header.Trace["trace_id"] != "abc" {
+			t.Errorf("Trace = %v, want trace_id abc", parsed.Header.Trace)
+		}
+		if len(parsed.Items) != 3 {
+			t.Fatalf("expected 3 items, got %d", len(parsed.Items))
+		}
+		if got := parsed.Items[0].Header.SpanCount; got != 3 {
+			t.Errorf("SpanCount = %d, want 3", got)
+		}
+		if got := string(parsed.Items[1].Payload); got != "line1\nline2" {
+			t.Errorf("attachment payload = %q", got)
+		}
+		if got := parsed.Items[1].Header.Filename; got != "file.txt" {
+			t.Errorf("attachment filename = %q", got)
+		}
+		if got := *parsed.Items[2].Header.ItemCount; got != 2 {
+			t.Errorf("ItemCount = %d, want 2", got)
+		}
+
+		reserialized, err := parsed.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize() of parsed envelope failed: %v", err)
+		}
+		if !bytes.Equal(data, reserialized) {
+			t.Errorf("round trip mismatch:\n got: %s\nwant: %s", reserialized, data)
+		}
+	})
+
+	t.Run("items without length", func(t *testing.T) {
+		data := []byte("{\"event_id\":\"abc\"}\n{\"type\":\"event\"}\n{\"message\":\"hello\"}\n")
+
+		parsed, err := ParseEnvelope(data)
+		if err != nil {
+			t.Fatalf("ParseEnvelope() failed: %v", err)
+		}
+		if len(parsed.Items) != 1 {
+			t.Fatalf("expected 1 item, got %d", len(parsed.Items))
+		}
+		if got := string(parsed.Items[0].Payload); got != `{"message":"hello"}` {
+			t.Errorf("payload = %q", got)
+		}
+		if got := *parsed.Items[0].Header.Length; got != len(`{"message":"hello"}`) {
+			t.Errorf("Length = %d", got)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for name, data := range map[string]string{
+			"empty":           "",
+			"invalid header":  "not json\n",
+			"invalid item":    "{}\nnot json\n{}\n",
+			"length overflow": "{}\n{\"type\":\"event\",\"length\":100}\n{}\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				if _, err := ParseEnvelope([]byte(data)); err == nil {
+					t.Error("expected an error")
+				}
+			})
+		}
+	})
+}
+
+func TestParseEnvelopes(t *testing.T) {
+	var buf bytes.Buffer
+	for _, id := range []string{"first", "second", "third"} {
+		envelope := NewEnvelope(&EnvelopeHeader{EventID: id})
+		envelope.AddItem(NewEnvelopeItem(EnvelopeItemTypeEvent, []byte(`{"message":"hello"}`)))
+		envelope.AddItem(NewAttachmentItem("file.txt", "text/plain", []byte("{\"event_id\":\"fake\"}\n")))
+		if _, err := envelope.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo() failed: %v", err)
+		}
+	}
+
+	envelopes, err := ParseEnvelopes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ParseEnvelopes() failed: %v", err)
+	}
+	if len(envelopes) != 3 {
+		t.Fatalf("expected 3 envelopes, got %d", len(envelopes))
+	}
+	for i, id := range []string{"first", "second", "third"} {
+		if got := envelopes[i].Header.EventID; got != id {
+			t.Errorf("envelope %d: EventID = %q, want %q", i, got, id)
+		}
+		if got := len(envelopes[i].Items); got != 2 {
+			t.Errorf("envelope %d: expected 2 items, got %d", i, got)
+		}
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		envelopes, err := ParseEnvelopes([]byte("\n\n"))
+		if err != nil || len(envelopes) != 0 {
+			t.Errorf("ParseEnvelopes() = %v, %v, want no envelopes", envelopes, err)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		data := buf.Bytes()[:buf.Len()-10]
+		envelopes, err := ParseEnvelopes(data)
+		if !errors.Is(err, ErrMalformedEnvelope) {
+			t.Fatalf("expected ErrMalformedEnvelope, got %v", err)
+		}
+		if len(envelopes) != 2 {
+			t.Errorf("expected the 2 complete envelopes before the error, got %d", len(envelopes))
+		}
+	})
+}
+
+func TestEnvelopeHeader_Readdress(t *testing.T) {
+	from, _ := NewDsn("https://from@example.com/1")
+	to, _ := NewDsn("https://to@example.com/2")
+
+	header := &EnvelopeHeader{
+		EventID: "id",
+		Dsn:     from,
+		Trace:   map[string]string{"public_key": "from", "trace_id": "abc"},
+	}
+	readdressed := header.Readdress(to)
+
+	if readdressed.Dsn != to || readdressed.EventID != "id" {
+		t.Errorf("Readdress() = %+v", readdressed)
+	}
+	if got := readdressed.Trace["public_key"]; got != "to" {
+		t.Errorf("public_key = %q, want %q", got, "to")
+	}
+	if got := readdressed.Trace["trace_id"]; got != "abc" {
+		t.Errorf("trace_id = %q, want %q", got, "abc")
+	}
+	if header.Dsn != from || header.Trace["public_key"] != "from" {
+		t.Error("Readdress() modified the original header")
+	}
+}
+
+func TestReaddressTrace(t *testing.T) {
+	from, _ := NewDsn("https://from@example.com/1")
+	to, _ := NewDsn("https://to@example.com/2")
+
+	tests := []struct {
+		name  string
+		trace map[string]string
+		want  string
+	}{
+		{"created for from", map[string]string{"public_key": "from"}, "to"},
+		{"propagated from another project", map[string]string{"public_key": "upstream"}, "upstream"},
+		{"without public key", map[string]string{"trace_id": "abc"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReaddressTrace(tt.trace, from, to)["public_key"]; got != tt.want {
+				t.Errorf("public_key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := ReaddressTrace(nil, from, to); got != nil {
+		t.Errorf("ReaddressTrace(nil) = %v, want nil", got)
+	}
+}
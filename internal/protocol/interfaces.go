@@ -35,6 +35,14 @@ type EnvelopeConvertible interface {
 	ToEnvelope(*EnvelopeHeader) (*Envelope, error)
 }
 
+// Routable is implemented by items that may be addressed to a different
+// project than the one of the client's DSN.
+type Routable interface {
+	// GetDsn returns the DSN the item is addressed to, or nil if it is sent
+	// to the client's DSN.
+	GetDsn() *Dsn
+}
+
 // TelemetryTransport represents the envelope-first transport interface.
 // This interface is designed for the telemetry buffer system and provides
 // non-blocking sends with backpressure signals.
@@ -58,3 +66,20 @@ type TelemetryTransport interface {
 	// Close shuts down the transport gracefully
 	Close()
 }
+
+// TransportStats is a snapshot of the state of a transport.
+type TransportStats struct {
+	// QueueSize is the number of envelopes waiting to be sent.
+	QueueSize int
+	// QueueCapacity is the number of envelopes the queue can hold.
+	QueueCapacity int
+	// RateLimits maps rate limited categories to the time their limit
+	// expires. CategoryAll limits all categories.
+	RateLimits map[ratelimit.Category]time.Time
+}
+
+// StatsProvider is implemented by transports that can report their state.
+type StatsProvider interface {
+	// Stats returns a snapshot of the state of the transport.
+	Stats() TransportStats
+}
@@ -12,6 +12,7 @@ var errNoSerializableItems = errors.New("item container contains no serializable
 
 type ItemContainer struct {
 	items    []TelemetryItem
+	payloads []json.RawMessage
 	category ratelimit.Category
 }
 
@@ -20,14 +21,23 @@ func NewItemContainer(category ratelimit.Category, items []TelemetryItem) ItemCo
 	return ItemContainer{category: category, items: items}
 }
 
+// NewBatchContainer constructs a batched envelope producer from a batch
+// returned by SplitBatch, reusing its serialized items.
+func NewBatchContainer(category ratelimit.Category, batch Batch[TelemetryItem]) ItemContainer {
+	return ItemContainer{category: category, items: batch.Items, payloads: batch.Payloads}
+}
+
 func (b ItemContainer) marshalPayload() ([]byte, int, error) {
-	items := make([]json.RawMessage, 0, len(b.items))
-	for _, item := range b.items {
-		itemPayload, err := json.Marshal(item)
-		if err != nil {
-			continue
+	items := b.payloads
+	if items == nil {
+		items = make([]json.RawMessage, 0, len(b.items))
+		for _, item := range b.items {
+			itemPayload, err := json.Marshal(item)
+			if err != nil {
+				continue
+			}
+			items = append(items, itemPayload)
 		}
-		items = append(items, itemPayload)
 	}
 
 	if len(items) == 0 {
@@ -51,6 +61,8 @@ func (b ItemContainer) newEnvelopeItem(itemCount int, payload []byte) (*Envelope
 		return NewLogItem(itemCount, payload), nil
 	case ratelimit.CategoryTraceMetric:
 		return NewTraceMetricItem(itemCount, payload), nil
+	case ratelimit.CategorySpan:
+		return NewSpanItem(itemCount, payload), nil
 	default:
 		return nil, fmt.Errorf("unsupported batched category: %s", b.category)
 	}
@@ -76,7 +88,21 @@ func (b ItemContainer) ToEnvelope(header *EnvelopeHeader) (*Envelope, error) {
 	return NewEnvelope(header, item), nil
 }
 
-func (b ItemContainer) GetCategory() ratelimit.Category            { return b.category }
-func (ItemContainer) GetEventID() string                           { return "" }
-func (ItemContainer) GetSdkInfo() *SdkInfo                         { return nil }
-func (ItemContainer) GetDynamicSamplingContext() map[string]string { return nil }
+func (b ItemContainer) GetCategory() ratelimit.Category { return b.category }
+func (ItemContainer) GetEventID() string                { return "" }
+func (ItemContainer) GetSdkInfo() *SdkInfo              { return nil }
+
+// GetDynamicSamplingContext returns the trace context of the first item, for
+// batches whose items all belong to the same trace.
+func (b ItemContainer) GetDynamicSamplingContext() map[string]string {
+	type dscProvider interface {
+		GetDynamicSamplingContext() map[string]string
+	}
+	if len(b.items) == 0 {
+		return nil
+	}
+	if provider, ok := b.items[0].(dscProvider); ok {
+		return provider.GetDynamicSamplingContext()
+	}
+	return nil
+}
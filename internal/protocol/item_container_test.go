@@ -29,6 +29,15 @@ type failingMetric struct {
 func (failingMetric) GetCategory() ratelimit.Category { return ratelimit.CategoryTraceMetric }
 func (failingMetric) MakeSerializationSafe()          {}
 
+type dummySpan struct {
+	TraceID string `json:"trace_id"`
+	dsc     map[string]string
+}
+
+func (dummySpan) GetCategory() ratelimit.Category                { return ratelimit.CategorySpan }
+func (dummySpan) MakeSerializationSafe()                         {}
+func (s dummySpan) GetDynamicSamplingContext() map[string]string { return s.dsc }
+
 func TestItemContainer_ToEnvelopeItem_And_Getters(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -54,6 +63,13 @@ func TestItemContainer_ToEnvelopeItem_And_Getters(t *testing.T) {
 			itemType:  EnvelopeItemTypeTraceMetric,
 			wantItems: 2,
 		},
+		{
+			name:      "spans",
+			category:  ratelimit.CategorySpan,
+			items:     []TelemetryItem{dummySpan{TraceID: "a"}, dummySpan{TraceID: "a"}},
+			itemType:  EnvelopeItemTypeSpan,
+			wantItems: 2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -140,6 +156,20 @@ func TestItemContainer_AllItemsFailMarshal(t *testing.T) {
 	}
 }
 
+func TestItemContainer_DynamicSamplingContext(t *testing.T) {
+	dsc := map[string]string{"trace_id": "a"}
+	container := NewItemContainer(ratelimit.CategorySpan, []TelemetryItem{
+		dummySpan{TraceID: "a", dsc: dsc},
+		dummySpan{TraceID: "a"},
+	})
+	if got := container.GetDynamicSamplingContext(); got["trace_id"] != "a" {
+		t.Fatalf("expected the dsc of the first item, got %v", got)
+	}
+	if got := NewItemContainer(ratelimit.CategorySpan, nil).GetDynamicSamplingContext(); got != nil {
+		t.Fatalf("expected no dsc for an empty container, got %v", got)
+	}
+}
+
 func TestItemContainer_UnsupportedCategory(t *testing.T) {
 	container := NewItemContainer(ratelimit.CategoryError, []TelemetryItem{
 		dummyMetric{Name: "metric1", Type: "gauge", Value: 42},
@@ -148,3 +178,34 @@ func TestItemContainer_UnsupportedCategory(t *testing.T) {
 		t.Fatal("expected unsupported batched category error")
 	}
 }
+
+func TestNewBatchContainer_ReusesPayloads(t *testing.T) {
+	items := []TelemetryItem{
+		dummyMetric{Name: "metric1", Type: "gauge", Value: 42},
+		failingMetric{Name: "bad", Value: func() string { return "nope" }},
+		dummyMetric{Name: "metric2", Type: "count", Value: 7},
+	}
+	batches, _ := SplitBatch(items, DefaultLimits)
+	if len(batches) != 1 || len(batches[0].Items) != 2 {
+		t.Fatalf("expected one batch of 2 serializable items, got %v", batches)
+	}
+	// Items are not serialized again, so a changed payload is sent as is.
+	batches[0].Payloads[0] = json.RawMessage(`{"name":"reused"}`)
+
+	item, err := NewBatchContainer(ratelimit.CategoryTraceMetric, batches[0]).ToEnvelopeItem()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := *item.Header.ItemCount; got != 2 {
+		t.Fatalf("expected item_count 2, got %d", got)
+	}
+	var payload struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(item.Payload, &payload); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if got := string(payload.Items[0]); got != `{"name":"reused"}` {
+		t.Errorf("first item = %s, want the payload of the batch", got)
+	}
+}
@@ -0,0 +1,131 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrTooLarge is returned when an item exceeds a size limit and cannot be made
+// small enough to be sent.
+var ErrTooLarge = errors.New("item exceeds the size limit")
+
+// Limits are the size limits that Sentry enforces during ingestion. Sentry
+// rejects an envelope as a whole if any of them is exceeded, so the SDK
+// applies them before sending.
+//
+// See https://develop.sentry.dev/sdk/data-model/envelopes/#size-limits.
+type Limits struct {
+	// MaxEnvelopeSize is the maximum size of an uncompressed envelope.
+	MaxEnvelopeSize int
+	// MaxEventSize is the maximum size of an event or transaction item.
+	MaxEventSize int
+	// MaxAttachmentSize is the maximum size of a single attachment item.
+	MaxAttachmentSize int
+	// MaxBatchSize is the maximum size of a log or metric item.
+	MaxBatchSize int
+	// MaxBatchItems is the maximum number of logs or metrics in one item.
+	MaxBatchItems int
+}
+
+// DefaultLimits are the limits documented for sentry.io.
+var DefaultLimits = Limits{
+	MaxEnvelopeSize:   200 << 20,
+	MaxEventSize:      1 << 20,
+	MaxAttachmentSize: 100 << 20,
+	MaxBatchSize:      1 << 20,
+	MaxBatchItems:     1000,
+}
+
+// batchOverhead leaves room for the fields that wrap the items of a batch in
+// its payload.
+const batchOverhead = 1 << 10
+
+// Batch is a batch of logs or metrics within the size limits. Payloads holds
+// the serialized Items, so that they are not serialized a second time when
+// the batch is sent.
+type Batch[T any] struct {
+	Items    []T
+	Payloads []json.RawMessage
+}
+
+// SplitBatch serializes a batch of logs or metrics and splits it into batches
+// that stay within MaxBatchItems and MaxBatchSize, keeping the order of items.
+// Items that exceed MaxBatchSize on their own are returned separately, since
+// they can never be sent. Items that cannot be serialized are skipped.
+func SplitBatch[T any](items []T, limits Limits) (batches []Batch[T], tooLarge []T) {
+	var batch Batch[T]
+	size := batchOverhead
+	for _, item := range items {
+		payload, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		n := len(payload) + 1
+		if batchOverhead+n > limits.MaxBatchSize {
+			tooLarge = append(tooLarge, item)
+			continue
+		}
+		if len(batch.Items) > 0 && (len(batch.Items) >= limits.MaxBatchItems || size+n > limits.MaxBatchSize) {
+			batches = append(batches, batch)
+			batch, size = Batch[T]{}, batchOverhead
+		}
+		batch.Items = append(batch.Items, item)
+		batch.Payloads = append(batch.Payloads, payload)
+		size += n
+	}
+	if len(batch.Items) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches, tooLarge
+}
+
+// DropAttachments removes attachment items from the envelope that exceed
+// MaxAttachmentSize, or that would make the envelope exceed MaxEnvelopeSize.
+// Attachments are kept in order for as long as they fit. It returns the
+// removed items.
+func (l Limits) DropAttachments(envelope *Envelope) []*EnvelopeItem {
+	size := 0
+	if header, err := json.Marshal(envelope.Header); err == nil {
+		size = len(header) + 1
+	}
+	for _, item := range envelope.Items {
+		if !isAttachment(item) {
+			size += itemSize(item)
+		}
+	}
+
+	var dropped []*EnvelopeItem
+	items := make([]*EnvelopeItem, 0, len(envelope.Items))
+	for _, item := range envelope.Items {
+		if !isAttachment(item) {
+			items = append(items, item)
+			continue
+		}
+		n := itemSize(item)
+		if len(item.Payload) > l.MaxAttachmentSize || size+n > l.MaxEnvelopeSize {
+			dropped = append(dropped, item)
+			continue
+		}
+		items = append(items, item)
+		size += n
+	}
+	envelope.Items = items
+	return dropped
+}
+
+func isAttachment(item *EnvelopeItem) bool {
+	return item != nil && item.Header != nil && item.Header.Type == EnvelopeItemTypeAttachment
+}
+
+// itemSize returns the serialized size of an item, including its header and
+// the newlines that terminate both.
+func itemSize(item *EnvelopeItem) int {
+	if item == nil {
+		return 0
+	}
+	header, err := json.Marshal(item.Header)
+	if err != nil {
+		return len(item.Payload) + 1
+	}
+	return len(header) + 1 + len(item.Payload) + 1
+}
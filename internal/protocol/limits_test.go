@@ -0,0 +1,129 @@
+package protocol
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type sizedItem struct {
+	Body string `json:"body"`
+}
+
+func TestSplitBatch(t *testing.T) {
+	// {"body":"xxxxxxxx"} is 19 bytes, 20 with its newline.
+	item := sizedItem{Body: strings.Repeat("x", 8)}
+	tests := []struct {
+		name        string
+		items       []sizedItem
+		limits      Limits
+		wantBatches []int
+		wantLarge   int
+	}{
+		{
+			name:        "fits in one batch",
+			items:       []sizedItem{item, item, item},
+			limits:      Limits{MaxBatchSize: 1 << 20, MaxBatchItems: 10},
+			wantBatches: []int{3},
+		},
+		{
+			name:        "split by count",
+			items:       []sizedItem{item, item, item, item, item},
+			limits:      Limits{MaxBatchSize: 1 << 20, MaxBatchItems: 2},
+			wantBatches: []int{2, 2, 1},
+		},
+		{
+			name:        "split by size",
+			items:       []sizedItem{item, item, item, item, item},
+			limits:      Limits{MaxBatchSize: batchOverhead + 40, MaxBatchItems: 10},
+			wantBatches: []int{2, 2, 1},
+		},
+		{
+			name:        "item too large",
+			items:       []sizedItem{item, {Body: strings.Repeat("x", 100)}, item},
+			limits:      Limits{MaxBatchSize: batchOverhead + 40, MaxBatchItems: 10},
+			wantBatches: []int{2},
+			wantLarge:   1,
+		},
+		{
+			name:   "empty",
+			limits: DefaultLimits,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches, tooLarge := SplitBatch(tt.items, tt.limits)
+			if len(batches) != len(tt.wantBatches) {
+				t.Fatalf("got %d batches, want %d", len(batches), len(tt.wantBatches))
+			}
+			for i, batch := range batches {
+				if len(batch.Items) != tt.wantBatches[i] {
+					t.Errorf("batch %d has %d items, want %d", i, len(batch.Items), tt.wantBatches[i])
+				}
+				if len(batch.Payloads) != len(batch.Items) {
+					t.Errorf("batch %d has %d payloads for %d items", i, len(batch.Payloads), len(batch.Items))
+				}
+				for j, payload := range batch.Payloads {
+					if want, _ := json.Marshal(batch.Items[j]); string(payload) != string(want) {
+						t.Errorf("batch %d payload %d = %s, want %s", i, j, payload, want)
+					}
+				}
+			}
+			if len(tooLarge) != tt.wantLarge {
+				t.Errorf("got %d items too large, want %d", len(tooLarge), tt.wantLarge)
+			}
+		})
+	}
+}
+
+func TestLimits_DropAttachments(t *testing.T) {
+	attachment := func(name string, size int) *EnvelopeItem {
+		return NewAttachmentItem(name, "text/plain", []byte(strings.Repeat("a", size)))
+	}
+	event := &EnvelopeItem{Header: &EnvelopeItemHeader{Type: EnvelopeItemTypeEvent}, Payload: []byte(`{}`)}
+
+	tests := []struct {
+		name        string
+		limits      Limits
+		items       []*EnvelopeItem
+		wantDropped []string
+	}{
+		{
+			name:   "within limits",
+			limits: DefaultLimits,
+			items:  []*EnvelopeItem{event, attachment("a.txt", 100), attachment("b.txt", 100)},
+		},
+		{
+			name:        "attachment too large",
+			limits:      Limits{MaxEnvelopeSize: 1 << 20, MaxAttachmentSize: 50},
+			items:       []*EnvelopeItem{event, attachment("a.txt", 100), attachment("b.txt", 10)},
+			wantDropped: []string{"a.txt"},
+		},
+		{
+			name:        "envelope too large",
+			limits:      Limits{MaxEnvelopeSize: 500, MaxAttachmentSize: 1 << 20},
+			items:       []*EnvelopeItem{event, attachment("a.txt", 200), attachment("b.txt", 200), attachment("c.txt", 10)},
+			wantDropped: []string{"b.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope := NewEnvelope(&EnvelopeHeader{EventID: "event-id"}, tt.items...)
+			dropped := tt.limits.DropAttachments(envelope)
+
+			var names []string
+			for _, item := range dropped {
+				names = append(names, item.Header.Filename)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantDropped, ",") {
+				t.Errorf("dropped %v, want %v", names, tt.wantDropped)
+			}
+			if got, want := len(envelope.Items), len(tt.items)-len(dropped); got != want {
+				t.Errorf("envelope has %d items, want %d", got, want)
+			}
+			if envelope.Items[0] != event {
+				t.Error("event item was not kept first")
+			}
+		})
+	}
+}
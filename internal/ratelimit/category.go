@@ -16,26 +16,32 @@ type Category string
 
 // Known rate limit categories that are specified in rate limit headers.
 const (
-	CategoryUnknown     Category = "unknown" // Unknown category should not get rate limited
-	CategoryAll         Category = ""        // Special category for empty categories (applies to all)
-	CategoryError       Category = "error"
-	CategoryTransaction Category = "transaction"
-	CategorySpan        Category = "span"
-	CategoryLog         Category = "log_item"
-	CategoryLogByte     Category = "log_byte"
-	CategoryMonitor     Category = "monitor"
-	CategoryTraceMetric Category = "trace_metric"
+	CategoryUnknown      Category = "unknown" // Unknown category should not get rate limited
+	CategoryAll          Category = ""        // Special category for empty categories (applies to all)
+	CategoryError        Category = "error"
+	CategoryTransaction  Category = "transaction"
+	CategorySpan         Category = "span"
+	CategoryLog          Category = "log_item"
+	CategoryLogByte      Category = "log_byte"
+	CategoryMonitor      Category = "monitor"
+	CategoryTraceMetric  Category = "trace_metric"
+	CategoryProfile      Category = "profile"
+	CategoryProfileChunk Category = "profile_chunk"
+	CategoryAttachment   Category = "attachment" // Counted in bytes, only used for client reports
 )
 
 // knownCategories is the set of currently known categories. Other categories
 // are ignored for the purpose of rate-limiting.
 var knownCategories = map[Category]struct{}{
-	CategoryAll:         {},
-	CategoryError:       {},
-	CategoryTransaction: {},
-	CategoryLog:         {},
-	CategoryMonitor:     {},
-	CategoryTraceMetric: {},
+	CategoryAll:          {},
+	CategoryError:        {},
+	CategoryTransaction:  {},
+	CategorySpan:         {},
+	CategoryLog:          {},
+	CategoryMonitor:      {},
+	CategoryTraceMetric:  {},
+	CategoryProfile:      {},
+	CategoryProfileChunk: {},
 }
 
 // String returns the category formatted for debugging.
@@ -57,6 +63,12 @@ func (c Category) String() string {
 		return "CategoryMonitor"
 	case CategoryTraceMetric:
 		return "CategoryTraceMetric"
+	case CategoryProfile:
+		return "CategoryProfile"
+	case CategoryProfileChunk:
+		return "CategoryProfileChunk"
+	case CategoryAttachment:
+		return "CategoryAttachment"
 	default:
 		// For unknown categories, use the original formatting logic
 		caser := cases.Title(language.English)
@@ -105,10 +117,12 @@ func (c Category) GetPriority() Priority {
 		return PriorityHigh
 	case CategoryLog:
 		return PriorityLow
-	case CategoryTransaction:
+	case CategoryTransaction, CategorySpan:
 		return PriorityMedium
 	case CategoryTraceMetric:
 		return PriorityLow
+	case CategoryProfile, CategoryProfileChunk:
+		return PriorityLowest
 	default:
 		return PriorityMedium
 	}
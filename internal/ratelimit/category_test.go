@@ -15,6 +15,8 @@ func TestCategory_String(t *testing.T) {
 		{CategoryMonitor, "CategoryMonitor"},
 		{CategoryLog, "CategoryLog"},
 		{CategoryTraceMetric, "CategoryTraceMetric"},
+		{CategoryProfile, "CategoryProfile"},
+		{CategoryProfileChunk, "CategoryProfileChunk"},
 		{Category("custom type"), "CategoryCustomType"},
 		{Category("multi word type"), "CategoryMultiWordType"},
 	}
@@ -34,9 +36,12 @@ func TestKnownCategories(t *testing.T) {
 		CategoryAll,
 		CategoryError,
 		CategoryTransaction,
+		CategorySpan,
 		CategoryMonitor,
 		CategoryLog,
 		CategoryTraceMetric,
+		CategoryProfile,
+		CategoryProfileChunk,
 	}
 
 	for _, category := range expectedCategories {
@@ -92,7 +97,10 @@ func TestCategory_GetPriority(t *testing.T) {
 		{CategoryMonitor, PriorityHigh},
 		{CategoryLog, PriorityLow},
 		{CategoryTransaction, PriorityMedium},
+		{CategorySpan, PriorityMedium},
 		{CategoryTraceMetric, PriorityLow},
+		{CategoryProfile, PriorityLowest},
+		{CategoryProfileChunk, PriorityLowest},
 		{Category("unknown"), PriorityMedium},
 	}
 
@@ -45,6 +45,22 @@ func (m Map) Merge(other Map) {
 	}
 }
 
+// Active returns the deadlines of all categories that are currently rate
+// limited.
+func (m Map) Active() map[Category]time.Time {
+	return m.active(time.Now())
+}
+
+func (m Map) active(now time.Time) map[Category]time.Time {
+	active := make(map[Category]time.Time)
+	for c, d := range m {
+		if d.After(Deadline(now)) {
+			active[c] = time.Time(d)
+		}
+	}
+	return active
+}
+
 // FromResponse returns a rate limit map from an HTTP response.
 func FromResponse(r *http.Response) Map {
 	return fromResponse(r, time.Now())
@@ -267,3 +267,18 @@ func TestMapMerge(t *testing.T) {
 		})
 	}
 }
+
+func TestMapActive(t *testing.T) {
+	m := Map{
+		CategoryAll:         Deadline(now.Add(-time.Second)),
+		CategoryError:       Deadline(now),
+		CategoryTransaction: Deadline(now.Add(time.Minute)),
+	}
+	got := m.active(now)
+	want := map[Category]time.Time{
+		CategoryTransaction: now.Add(time.Minute),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
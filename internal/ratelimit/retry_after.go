@@ -2,6 +2,7 @@ package ratelimit
 
 import (
 	"errors"
+	"net/http"
 	"strconv"
 	"time"
 )
@@ -38,3 +39,21 @@ func parseRetryAfter(s string, now time.Time) (Deadline, error) {
 invalid:
 	return Deadline(now.Add(defaultRetryAfter)), errInvalidRetryAfter
 }
+
+// RetryAfter returns how long to wait before retrying the request that
+// produced the response, as requested by its Retry-After header. It returns
+// zero if the header is missing or invalid.
+func RetryAfter(r *http.Response) time.Duration {
+	return retryAfter(r, time.Now())
+}
+
+func retryAfter(r *http.Response, now time.Time) time.Duration {
+	deadline, err := parseRetryAfter(r.Header.Get("Retry-After"), now)
+	if err != nil {
+		return 0
+	}
+	if d := time.Time(deadline).Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
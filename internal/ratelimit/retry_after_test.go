@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"net/http"
 	"testing"
 	"time"
 )
@@ -56,3 +57,28 @@ func TestParseRetryAfter(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   time.Duration
+	}{
+		"Missing":  {"", 0},
+		"Invalid":  {"x", 0},
+		"Seconds":  {"30", 30 * time.Second},
+		"Date":     {now.Add(time.Minute).Format(time.RFC1123), time.Minute},
+		"PastDate": {now.Add(-time.Minute).Format(time.RFC1123), 0},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			r := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				r.Header.Set("Retry-After", tt.header)
+			}
+			if got := retryAfter(r, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package spotlight mirrors envelopes to a local Spotlight sidecar, a
+// development tool that displays everything an SDK sends.
+//
+// See https://spotlightjs.com.
+package spotlight
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/protocol"
+)
+
+const (
+	// DefaultURL is the address of the Spotlight sidecar when it runs with
+	// its default settings.
+	DefaultURL = "http://localhost:8969/stream"
+
+	queueSize      = 100
+	requestTimeout = 5 * time.Second
+)
+
+// Sink sends envelopes to a Spotlight sidecar in the background.
+//
+// Mirroring must never affect delivery to Sentry, so envelopes are dropped
+// when the queue is full and failures are only logged.
+type Sink struct {
+	url    string
+	client *http.Client
+
+	queue        chan []byte
+	flushRequest chan chan struct{}
+	done         chan struct{}
+	wg           sync.WaitGroup
+
+	closeMu   sync.RWMutex
+	closeOnce sync.Once
+}
+
+// NewSink returns a Sink that posts envelopes to url, or DefaultURL if url is
+// empty, and starts its worker.
+func NewSink(url string) *Sink {
+	if url == "" {
+		url = DefaultURL
+	}
+	s := &Sink{
+		url:          url,
+		client:       &http.Client{Timeout: requestTimeout},
+		queue:        make(chan []byte, queueSize),
+		flushRequest: make(chan chan struct{}),
+		done:         make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.worker()
+	return s
+}
+
+// Send queues a serialized envelope. It never blocks.
+func (s *Sink) Send(data []byte) {
+	if s == nil || len(data) == 0 {
+		return
+	}
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	select {
+	case s.queue <- data:
+	default:
+		debuglog.Println("Spotlight queue is full, dropping envelope")
+	}
+}
+
+// SendEnvelope serializes and queues an envelope. It never blocks.
+func (s *Sink) SendEnvelope(envelope *protocol.Envelope) {
+	if s == nil || envelope == nil {
+		return
+	}
+	data, err := envelope.Serialize()
+	if err != nil {
+		debuglog.Printf("Failed to serialize envelope for Spotlight: %v", err)
+		return
+	}
+	s.Send(data)
+}
+
+// FlushWithContext waits until all queued envelopes were sent, or until ctx is
+// done.
+func (s *Sink) FlushWithContext(ctx context.Context) bool {
+	if s == nil {
+		return true
+	}
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	flushResponse := make(chan struct{})
+	select {
+	case s.flushRequest <- flushResponse:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case <-flushResponse:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close stops the worker. Queued envelopes that were not sent yet are
+// dropped.
+func (s *Sink) Close() {
+	if s == nil {
+		return
+	}
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		close(s.done)
+		s.closeMu.Unlock()
+		s.wg.Wait()
+	})
+}
+
+func (s *Sink) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case data := <-s.queue:
+			s.post(data)
+		case flushResponse := <-s.flushRequest:
+			s.drain()
+			close(flushResponse)
+		}
+	}
+}
+
+func (s *Sink) drain() {
+	for {
+		select {
+		case data := <-s.queue:
+			s.post(data)
+		default:
+			return
+		}
+	}
+}
+
+func (s *Sink) post(data []byte) {
+	request, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		debuglog.Printf("Failed to create Spotlight request: %v", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/x-sentry-envelope")
+
+	response, err := s.client.Do(request) //nolint:gosec // G704: the URL is configured by the user
+	if err != nil {
+		debuglog.Printf("Failed to send envelope to Spotlight: %v", err)
+		return
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		debuglog.Printf("Spotlight responded with status %d", response.StatusCode)
+	}
+}
+
+// Tee returns a TelemetryTransport that mirrors every envelope to sink before
+// passing it on to transport.
+func Tee(transport protocol.TelemetryTransport, sink *Sink) protocol.TelemetryTransport {
+	return &teeTransport{TelemetryTransport: transport, sink: sink}
+}
+
+type teeTransport struct {
+	protocol.TelemetryTransport
+	sink *Sink
+}
+
+func (t *teeTransport) SendEnvelope(envelope *protocol.Envelope) error {
+	t.sink.SendEnvelope(envelope)
+	return t.TelemetryTransport.SendEnvelope(envelope)
+}
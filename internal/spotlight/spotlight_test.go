@@ -0,0 +1,138 @@
+package spotlight
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/testutils"
+)
+
+func testEnvelope(eventID string) *protocol.Envelope {
+	return protocol.NewEnvelope(
+		&protocol.EnvelopeHeader{EventID: eventID},
+		protocol.NewEnvelopeItem(protocol.EnvelopeItemTypeEvent, []byte(`{"message":"test"}`)),
+	)
+}
+
+type recordingServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	eventIDs  []string
+	mediaType string
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		envelope, err := protocol.ParseEnvelope(data)
+		if err != nil {
+			t.Errorf("invalid envelope: %v", err)
+			return
+		}
+		s.mu.Lock()
+		s.eventIDs = append(s.eventIDs, envelope.Header.EventID)
+		s.mediaType = r.Header.Get("Content-Type")
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.eventIDs...)
+}
+
+func flush(t *testing.T, sink *Sink) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), testutils.FlushTimeout())
+	defer cancel()
+	if !sink.FlushWithContext(ctx) {
+		t.Fatal("FlushWithContext timed out")
+	}
+}
+
+func TestSink(t *testing.T) {
+	server := newRecordingServer(t)
+	sink := NewSink(server.URL + "/stream")
+	defer sink.Close()
+
+	sink.SendEnvelope(testEnvelope("first"))
+	sink.SendEnvelope(testEnvelope("second"))
+	flush(t, sink)
+
+	got := server.received()
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Errorf("received %v, want [first second]", got)
+	}
+	if server.mediaType != "application/x-sentry-envelope" {
+		t.Errorf("Content-Type = %q", server.mediaType)
+	}
+}
+
+func TestSink_NeverBlocks(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	sink := NewSink(server.URL)
+	defer sink.Close()
+	defer close(release)
+
+	start := time.Now()
+	for i := 0; i < 10*queueSize; i++ {
+		sink.SendEnvelope(testEnvelope("event"))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sending to a stalled sidecar took %v", elapsed)
+	}
+}
+
+func TestSink_Nil(t *testing.T) {
+	var sink *Sink
+	sink.SendEnvelope(testEnvelope("event"))
+	if !sink.FlushWithContext(context.Background()) {
+		t.Error("FlushWithContext on a nil sink should succeed")
+	}
+	sink.Close()
+}
+
+type recordingTransport struct {
+	protocol.TelemetryTransport
+	sent []*protocol.Envelope
+}
+
+func (t *recordingTransport) SendEnvelope(envelope *protocol.Envelope) error {
+	t.sent = append(t.sent, envelope)
+	return nil
+}
+
+func TestTee(t *testing.T) {
+	server := newRecordingServer(t)
+	sink := NewSink(server.URL)
+	defer sink.Close()
+
+	primary := &recordingTransport{}
+	transport := Tee(primary, sink)
+	if err := transport.SendEnvelope(testEnvelope("event")); err != nil {
+		t.Fatalf("SendEnvelope() failed: %v", err)
+	}
+	flush(t, sink)
+
+	if len(primary.sent) != 1 {
+		t.Errorf("expected the envelope to reach the primary transport, got %d", len(primary.sent))
+	}
+	if got := server.received(); len(got) != 1 || got[0] != "event" {
+		t.Errorf("Spotlight received %v, want [event]", got)
+	}
+}
@@ -39,6 +39,7 @@ func TestProcessorFlush_EnvelopeCarriesScopeAttachments(t *testing.T) {
 			return &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
 		},
 		nil,
+		nil,
 	)
 
 	require.True(t, processor.Add(event), "add failed")
@@ -5,6 +5,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/getsentry/sentry-go/internal/debuglog"
 	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/ratelimit"
 	"github.com/getsentry/sentry-go/report"
@@ -73,10 +74,9 @@ func NewBucketedBuffer[T any](
 		recorder = report.NoopRecorder()
 	}
 
-	bucketCapacity := capacity / 10
-	if bucketCapacity < 10 {
-		bucketCapacity = 10
-	}
+	// Every bucket holds at least one item, so the buffer is bounded by its
+	// item capacity alone.
+	bucketCapacity := capacity
 
 	return &BucketedBuffer[T]{
 		buckets:        make([]*Bucket[T], bucketCapacity),
@@ -108,26 +108,26 @@ func (b *BucketedBuffer[T]) Offer(item T) bool {
 	return b.offerToBucket(item, traceID)
 }
 
+// offerToBucket adds the item to the bucket of its trace, or to a new bucket.
+// Items without a trace share the newest bucket if it has no trace either.
+//
+// When the buffer is full, OverflowPolicyDropOldest evicts the oldest bucket
+// as a whole, so that the items of a trace are kept or dropped together.
 func (b *BucketedBuffer[T]) offerToBucket(item T, traceID string) bool {
-	if traceID != "" {
-		if idx, exists := b.traceIndex[traceID]; exists {
-			bucket := b.buckets[idx]
-			if len(bucket.items) >= perBucketItemLimit {
-				delete(b.traceIndex, traceID)
-			} else {
-				bucket.items = append(bucket.items, item)
-				bucket.lastUpdatedAt = time.Now()
-				b.totalItems++
-				return true
-			}
+	idx, exists := b.bucketFor(traceID)
+	if b.totalItems >= b.itemCapacity {
+		if !b.evictOldestBucket(idx, exists) {
+			b.dropNewest(item)
+			return false
 		}
 	}
 
-	if b.totalItems >= b.itemCapacity {
-		return b.handleOverflow(item, traceID)
-	}
-	if b.bucketCount >= b.bucketCapacity {
-		return b.handleOverflow(item, traceID)
+	if exists {
+		bucket := b.buckets[idx]
+		bucket.items = append(bucket.items, item)
+		bucket.lastUpdatedAt = time.Now()
+		b.totalItems++
+		return true
 	}
 
 	bucket := &Bucket[T]{
@@ -146,56 +146,78 @@ func (b *BucketedBuffer[T]) offerToBucket(item T, traceID string) bool {
 	return true
 }
 
-func (b *BucketedBuffer[T]) handleOverflow(item T, traceID string) bool {
-	switch b.overflowPolicy {
-	case OverflowPolicyDropOldest:
-		oldestBucket := b.buckets[b.head]
-		if oldestBucket == nil {
-			b.recordDroppedItem(item)
-			atomic.AddInt64(&b.dropped, 1)
-			if b.onDropped != nil {
-				b.onDropped(item, "buffer_full_invalid_state")
-			}
-			return false
-		}
-		if oldestBucket.traceID != "" {
-			delete(b.traceIndex, oldestBucket.traceID)
-		}
-		droppedCount := len(oldestBucket.items)
-		atomic.AddInt64(&b.dropped, int64(droppedCount))
-		for _, di := range oldestBucket.items {
-			b.recordDroppedItem(di)
-			if b.onDropped != nil {
-				b.onDropped(di, "buffer_full_drop_oldest_bucket")
-			}
+// bucketFor returns the index of the bucket a new item of the trace is added
+// to, if there is one with room for it.
+func (b *BucketedBuffer[T]) bucketFor(traceID string) (int, bool) {
+	if traceID == "" {
+		if b.bucketCount == 0 {
+			return 0, false
 		}
-		b.totalItems -= droppedCount
-		b.bucketCount--
-		b.head = (b.head + 1) % b.bucketCapacity
-		// add new bucket
-		bucket := &Bucket[T]{traceID: traceID, items: []T{item}, createdAt: time.Now(), lastUpdatedAt: time.Now()}
-		b.buckets[b.tail] = bucket
-		if traceID != "" {
-			b.traceIndex[traceID] = b.tail
-		}
-		b.tail = (b.tail + 1) % b.bucketCapacity
-		b.bucketCount++
-		b.totalItems++
-		return true
-	case OverflowPolicyDropNewest:
-		atomic.AddInt64(&b.dropped, 1)
-		b.recordDroppedItem(item)
-		if b.onDropped != nil {
-			b.onDropped(item, "buffer_full_drop_newest")
+		idx := (b.tail - 1 + b.bucketCapacity) % b.bucketCapacity
+		bucket := b.buckets[idx]
+		if bucket == nil || bucket.traceID != "" || len(bucket.items) >= perBucketItemLimit {
+			return 0, false
 		}
+		return idx, true
+	}
+
+	idx, exists := b.traceIndex[traceID]
+	if !exists {
+		return 0, false
+	}
+	if len(b.buckets[idx].items) >= perBucketItemLimit {
+		// Later items of the trace start a new bucket.
+		delete(b.traceIndex, traceID)
+		return 0, false
+	}
+	return idx, true
+}
+
+// evictOldestBucket drops all items of the oldest bucket to make room for a
+// new item, unless the policy is not OverflowPolicyDropOldest or the oldest
+// bucket is the one at keep, which the new item is added to.
+func (b *BucketedBuffer[T]) evictOldestBucket(keep int, hasKeep bool) bool {
+	if b.overflowPolicy != OverflowPolicyDropOldest || b.bucketCount == 0 {
 		return false
-	default:
-		atomic.AddInt64(&b.dropped, 1)
-		b.recordDroppedItem(item)
+	}
+	if hasKeep && b.head == keep {
+		return false
+	}
+	oldestBucket := b.buckets[b.head]
+	if oldestBucket == nil {
+		return false
+	}
+	if oldestBucket.traceID != "" {
+		delete(b.traceIndex, oldestBucket.traceID)
+		debuglog.Printf("Dropping %d %s items of trace %s, the buffer is full", len(oldestBucket.items), b.category, oldestBucket.traceID)
+	}
+	droppedCount := len(oldestBucket.items)
+	atomic.AddInt64(&b.dropped, int64(droppedCount))
+	for _, di := range oldestBucket.items {
+		b.recordDroppedItem(di)
 		if b.onDropped != nil {
-			b.onDropped(item, "unknown_overflow_policy")
+			b.onDropped(di, "buffer_full_drop_oldest_bucket")
 		}
-		return false
+	}
+	b.buckets[b.head] = nil
+	b.totalItems -= droppedCount
+	b.bucketCount--
+	b.head = (b.head + 1) % b.bucketCapacity
+	return true
+}
+
+// dropNewest drops an item that does not fit into the buffer.
+func (b *BucketedBuffer[T]) dropNewest(item T) {
+	atomic.AddInt64(&b.dropped, 1)
+	b.recordDroppedItem(item)
+	if b.onDropped == nil {
+		return
+	}
+	switch b.overflowPolicy {
+	case OverflowPolicyDropOldest, OverflowPolicyDropNewest:
+		b.onDropped(item, "buffer_full_drop_newest")
+	default:
+		b.onDropped(item, "unknown_overflow_policy")
 	}
 }
 
@@ -258,6 +280,9 @@ func (b *BucketedBuffer[T]) PollBatch(maxItems int) []T {
 	return res
 }
 
+// PollIfReady returns the oldest buckets, up to batchSize items but at least
+// one bucket, if the buffer is ready to flush. Buckets are never split, so the
+// items of a trace are sent together.
 func (b *BucketedBuffer[T]) PollIfReady() []T {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -268,18 +293,21 @@ func (b *BucketedBuffer[T]) PollIfReady() []T {
 	if !ready {
 		return nil
 	}
-	oldest := b.buckets[b.head]
-	if oldest == nil {
-		return nil
-	}
-	items := oldest.items
-	if oldest.traceID != "" {
-		delete(b.traceIndex, oldest.traceID)
+	var items []T
+	for b.bucketCount > 0 {
+		bucket := b.buckets[b.head]
+		if bucket == nil || (len(items) > 0 && len(items)+len(bucket.items) > b.batchSize) {
+			break
+		}
+		items = append(items, bucket.items...)
+		if bucket.traceID != "" {
+			delete(b.traceIndex, bucket.traceID)
+		}
+		b.buckets[b.head] = nil
+		b.head = (b.head + 1) % b.bucketCapacity
+		b.totalItems -= len(bucket.items)
+		b.bucketCount--
 	}
-	b.buckets[b.head] = nil
-	b.head = (b.head + 1) % b.bucketCapacity
-	b.totalItems -= len(items)
-	b.bucketCount--
 	b.lastFlushTime = time.Now()
 	return items
 }
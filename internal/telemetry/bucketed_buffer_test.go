@@ -1,12 +1,15 @@
 package telemetry
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
 )
 
 type tbItem struct {
@@ -21,6 +24,15 @@ func (i tbItem) GetTraceID() (string, bool) {
 	return i.trace, true
 }
 
+type traceItem struct {
+	id    int
+	trace string
+}
+
+func (i *traceItem) GetCategory() ratelimit.Category { return ratelimit.CategoryTransaction }
+func (i *traceItem) MakeSerializationSafe()          {}
+func (i *traceItem) GetTraceID() (string, bool)      { return i.trace, i.trace != "" }
+
 func TestBucketedBufferPollOperation(t *testing.T) {
 	b := NewBucketedBuffer[tbItem](ratelimit.CategoryError, 10, OverflowPolicyDropOldest, 3, 0, nil)
 	if !b.Offer(tbItem{id: 1}) || !b.Offer(tbItem{id: 2}) {
@@ -95,6 +107,36 @@ func TestNewBucketedBuffer(t *testing.T) {
 	}
 }
 
+func TestBucketedBufferDistinctTracesUpToCapacity(t *testing.T) {
+	const capacity = 500
+	b := NewBucketedBuffer[tbItem](ratelimit.CategoryTransaction, capacity, OverflowPolicyDropOldest, 1, 0, nil)
+	for i := 0; i < capacity; i++ {
+		if !b.Offer(tbItem{id: i, trace: fmt.Sprintf("trace-%d", i)}) {
+			t.Fatalf("offer of trace %d failed", i)
+		}
+	}
+	if b.Size() != capacity || b.DroppedCount() != 0 {
+		t.Fatalf("size %d dropped %d, want %d items and no drops", b.Size(), b.DroppedCount(), capacity)
+	}
+	if !b.IsFull() || b.Utilization() != 1 {
+		t.Fatalf("full %v utilization %f, want a full buffer", b.IsFull(), b.Utilization())
+	}
+	if m := b.GetMetrics(); m.Utilization != 1 || m.Size != capacity {
+		t.Fatalf("metrics utilization %f size %d, want 1 and %d", m.Utilization, m.Size, capacity)
+	}
+
+	// One more trace evicts the oldest one.
+	if !b.Offer(tbItem{id: capacity, trace: "trace-new"}) {
+		t.Fatal("offer of a new trace failed")
+	}
+	if b.Size() != capacity || b.DroppedCount() != 1 {
+		t.Fatalf("size %d dropped %d, want %d items and one drop", b.Size(), b.DroppedCount(), capacity)
+	}
+	if item, _ := b.Peek(); item.id != 1 {
+		t.Fatalf("oldest item is %d, want 1", item.id)
+	}
+}
+
 func TestBucketedBufferBasicOperations(t *testing.T) {
 	b := NewBucketedBuffer[tbItem](ratelimit.CategoryError, 10, OverflowPolicyDropOldest, 1, 0, nil)
 	if !b.IsEmpty() || b.IsFull() || b.Size() != 0 {
@@ -260,3 +302,75 @@ func TestBucketedBufferConcurrency(t *testing.T) {
 		t.Fatal("expected to poll some items")
 	}
 }
+
+func TestBucketedBufferDropOldestEvictsWholeTrace(t *testing.T) {
+	recorder := report.NewAggregator()
+	b := NewBucketedBuffer[protocol.TelemetryItem](ratelimit.CategoryTransaction, 4, OverflowPolicyDropOldest, 1, 0, recorder)
+	b.Offer(&traceItem{id: 1, trace: "a"})
+	b.Offer(&traceItem{id: 2, trace: "b"})
+	b.Offer(&traceItem{id: 3, trace: "a"})
+	b.Offer(&traceItem{id: 4, trace: "b"})
+
+	if !b.Offer(&traceItem{id: 5, trace: "c"}) {
+		t.Fatal("offer should succeed and drop the oldest trace")
+	}
+
+	var ids []int
+	for _, item := range b.Drain() {
+		ids = append(ids, item.(*traceItem).id)
+	}
+	if fmt.Sprint(ids) != "[2 4 5]" {
+		t.Errorf("got items %v, want [2 4 5]", ids)
+	}
+	clientReport := recorder.TakeReport()
+	if clientReport == nil || len(clientReport.DiscardedEvents) != 1 {
+		t.Fatalf("expected one outcome, got %+v", clientReport)
+	}
+	if got := clientReport.DiscardedEvents[0]; got.Reason != report.ReasonBufferOverflow || got.Quantity != 2 {
+		t.Errorf("unexpected outcome: %+v", got)
+	}
+}
+
+func TestBucketedBufferEnforcesCapacityWithinTrace(t *testing.T) {
+	b := NewBucketedBuffer[tbItem](ratelimit.CategoryLog, 3, OverflowPolicyDropNewest, 1, 0, nil)
+	for i := 0; i < 3; i++ {
+		if !b.Offer(tbItem{id: i, trace: "a"}) {
+			t.Fatalf("offer %d failed", i)
+		}
+	}
+	if b.Offer(tbItem{id: 3, trace: "a"}) {
+		t.Fatal("offer should fail when the buffer is full")
+	}
+	if b.Size() != 3 {
+		t.Errorf("size want 3 got %d", b.Size())
+	}
+}
+
+func TestBucketedBufferUntracedItemsShareBuckets(t *testing.T) {
+	// 100 items only allow for 10 buckets.
+	b := NewBucketedBuffer[tbItem](ratelimit.CategoryLog, 100, OverflowPolicyDropNewest, 100, 0, nil)
+	for i := 0; i < 100; i++ {
+		if !b.Offer(tbItem{id: i}) {
+			t.Fatalf("offer %d failed", i)
+		}
+	}
+	if b.Size() != 100 {
+		t.Errorf("size want 100 got %d", b.Size())
+	}
+}
+
+func TestBucketedBufferPollIfReady_WholeBuckets(t *testing.T) {
+	b := NewBucketedBuffer[tbItem](ratelimit.CategoryLog, 100, OverflowPolicyDropOldest, 4, 0, nil)
+	b.Offer(tbItem{id: 1, trace: "a"})
+	b.Offer(tbItem{id: 2, trace: "b"})
+	b.Offer(tbItem{id: 3, trace: "b"})
+	b.Offer(tbItem{id: 4, trace: "c"})
+	b.Offer(tbItem{id: 5, trace: "c"})
+
+	if got := len(b.PollIfReady()); got != 3 {
+		t.Fatalf("expected buckets a and b with 3 items, got %d", got)
+	}
+	if b.Size() != 2 {
+		t.Errorf("expected bucket c to remain, size %d", b.Size())
+	}
+}
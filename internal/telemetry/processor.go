@@ -21,8 +21,9 @@ func NewProcessor(
 	dsn *protocol.Dsn,
 	sdkInfo func() *protocol.SdkInfo,
 	recorder report.ClientReportRecorder,
+	weights map[ratelimit.Priority]int,
 ) *Processor {
-	scheduler := NewScheduler(buffers, transport, dsn, sdkInfo, recorder)
+	scheduler := NewScheduler(buffers, transport, dsn, sdkInfo, recorder, weights)
 	scheduler.Start()
 
 	return &Processor{
@@ -39,6 +40,11 @@ func (b *Processor) Add(item protocol.TelemetryItem) bool {
 	return b.scheduler.Add(item)
 }
 
+// BufferMetrics returns the metrics of every buffer by category.
+func (b *Processor) BufferMetrics() map[ratelimit.Category]BufferMetrics {
+	return b.scheduler.BufferMetrics()
+}
+
 // Flush forces all buffers to flush within the given timeout.
 func (b *Processor) Flush(timeout time.Duration) bool {
 	return b.scheduler.Flush(timeout)
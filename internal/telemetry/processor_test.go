@@ -36,7 +36,7 @@ func TestBuffer_Add_MissingCategory(t *testing.T) {
 	sdk := &protocol.SdkInfo{Name: "s", Version: "v"}
 	storage := map[ratelimit.Category]Buffer[protocol.TelemetryItem]{}
 
-	b := NewProcessor(storage, transport, dsn, func() *protocol.SdkInfo { return sdk }, nil)
+	b := NewProcessor(storage, transport, dsn, func() *protocol.SdkInfo { return sdk }, nil, nil)
 	ok := b.Add(bwItem{id: "1"})
 	if ok {
 		t.Fatal("expected Add to return false without storage for category")
@@ -51,7 +51,7 @@ func TestBuffer_AddAndFlush_Sends(t *testing.T) {
 	storage := map[ratelimit.Category]Buffer[protocol.TelemetryItem]{
 		ratelimit.CategoryError: NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryError, 10, OverflowPolicyDropOldest, 1, 0, nil),
 	}
-	b := NewProcessor(storage, transport, dsn, func() *protocol.SdkInfo { return sdk }, nil)
+	b := NewProcessor(storage, transport, dsn, func() *protocol.SdkInfo { return sdk }, nil, nil)
 	if !b.Add(bwItem{id: "1"}) {
 		t.Fatal("add failed")
 	}
@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -11,6 +12,16 @@ import (
 	"github.com/getsentry/sentry-go/report"
 )
 
+// DefaultPriorityWeights is the number of times each priority is visited in a
+// scheduling cycle, unless overridden by the weights passed to NewScheduler.
+var DefaultPriorityWeights = map[ratelimit.Priority]int{
+	ratelimit.PriorityCritical: 5,
+	ratelimit.PriorityHigh:     4,
+	ratelimit.PriorityMedium:   3,
+	ratelimit.PriorityLow:      2,
+	ratelimit.PriorityLowest:   1,
+}
+
 // Scheduler implements a weighted round-robin scheduler for processing buffered events.
 type Scheduler struct {
 	buffers   map[ratelimit.Category]Buffer[protocol.TelemetryItem]
@@ -18,6 +29,7 @@ type Scheduler struct {
 	dsn       *protocol.Dsn
 	sdkInfo   func() *protocol.SdkInfo
 	recorder  report.ClientReportRecorder
+	limits    protocol.Limits
 
 	currentCycle []ratelimit.Priority
 	cyclePos     int
@@ -38,6 +50,7 @@ func NewScheduler(
 	dsn *protocol.Dsn,
 	sdkInfo func() *protocol.SdkInfo,
 	recorder report.ClientReportRecorder,
+	weights map[ratelimit.Priority]int,
 ) *Scheduler {
 	if recorder == nil {
 		recorder = report.NoopRecorder()
@@ -45,12 +58,12 @@ func NewScheduler(
 
 	ctx, cancel := context.WithCancel(context.Background()) //nolint:gosec // G118: cancel is stored in s.cancel and called in Shutdown()
 
-	priorityWeights := map[ratelimit.Priority]int{
-		ratelimit.PriorityCritical: 5,
-		ratelimit.PriorityHigh:     4,
-		ratelimit.PriorityMedium:   3,
-		ratelimit.PriorityLow:      2,
-		ratelimit.PriorityLowest:   1,
+	priorityWeights := make(map[ratelimit.Priority]int, len(DefaultPriorityWeights))
+	for priority, weight := range DefaultPriorityWeights {
+		if w, ok := weights[priority]; ok && w > 0 {
+			weight = w
+		}
+		priorityWeights[priority] = weight
 	}
 
 	var currentCycle []ratelimit.Priority
@@ -76,6 +89,7 @@ func NewScheduler(
 		dsn:          dsn,
 		sdkInfo:      sdkInfo,
 		recorder:     recorder,
+		limits:       protocol.DefaultLimits,
 		currentCycle: currentCycle,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -138,6 +152,15 @@ func (s *Scheduler) Add(item protocol.TelemetryItem) bool {
 	return accepted
 }
 
+// BufferMetrics returns the metrics of every buffer by category.
+func (s *Scheduler) BufferMetrics() map[ratelimit.Category]BufferMetrics {
+	metrics := make(map[ratelimit.Category]BufferMetrics, len(s.buffers))
+	for category, buffer := range s.buffers {
+		metrics[category] = buffer.GetMetrics()
+	}
+	return metrics
+}
+
 func (s *Scheduler) Flush(timeout time.Duration) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -250,7 +273,15 @@ func (s *Scheduler) processItems(buffer Buffer[protocol.TelemetryItem], category
 func (s *Scheduler) envelopeConvertibles(category ratelimit.Category, items []protocol.TelemetryItem) []protocol.EnvelopeConvertible {
 	switch category {
 	case ratelimit.CategoryLog, ratelimit.CategoryTraceMetric:
-		return []protocol.EnvelopeConvertible{protocol.NewItemContainer(category, items)}
+		return s.batches(category, items)
+	case ratelimit.CategorySpan:
+		// The envelope header carries the trace of its spans, so spans of
+		// different traces are sent in separate envelopes.
+		var convertibles []protocol.EnvelopeConvertible
+		for _, trace := range groupByTrace(items) {
+			convertibles = append(convertibles, s.batches(category, trace)...)
+		}
+		return convertibles
 	default:
 		convertibles := make([]protocol.EnvelopeConvertible, 0, len(items))
 		for _, item := range items {
@@ -264,6 +295,44 @@ func (s *Scheduler) envelopeConvertibles(category ratelimit.Category, items []pr
 	}
 }
 
+// batches splits items into batches within the size limits, dropping items
+// that do not fit in a batch on their own.
+func (s *Scheduler) batches(category ratelimit.Category, items []protocol.TelemetryItem) []protocol.EnvelopeConvertible {
+	batches, tooLarge := protocol.SplitBatch(items, s.limits)
+	for _, item := range tooLarge {
+		debuglog.Printf("Dropping %s item larger than %d bytes", category, s.limits.MaxBatchSize)
+		s.recorder.RecordItem(report.ReasonTooLarge, item)
+	}
+	convertibles := make([]protocol.EnvelopeConvertible, 0, len(batches))
+	for _, batch := range batches {
+		convertibles = append(convertibles, protocol.NewBatchContainer(category, batch))
+	}
+	return convertibles
+}
+
+// groupByTrace groups items by trace ID, in the order each trace first
+// appears. Items without a trace ID are grouped together.
+func groupByTrace(items []protocol.TelemetryItem) [][]protocol.TelemetryItem {
+	var groups [][]protocol.TelemetryItem
+	index := make(map[string]int)
+	for _, item := range items {
+		var traceID string
+		if aware, ok := item.(TraceAware); ok {
+			if id, traced := aware.GetTraceID(); traced {
+				traceID = id
+			}
+		}
+		i, ok := index[traceID]
+		if !ok {
+			i = len(groups)
+			index[traceID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], item)
+	}
+	return groups
+}
+
 func (s *Scheduler) sendItem(item protocol.EnvelopeConvertible) {
 	header := &protocol.EnvelopeHeader{
 		EventID: item.GetEventID(),
@@ -278,13 +347,26 @@ func (s *Scheduler) sendItem(item protocol.EnvelopeConvertible) {
 	if header.Sdk == nil {
 		header.Sdk = s.resolveSdkInfo()
 	}
+	if routable, ok := item.(protocol.Routable); ok {
+		if dsn := routable.GetDsn(); dsn != nil {
+			header.Dsn = dsn
+		}
+	}
 
 	envelope, err := item.ToEnvelope(header)
 	if err != nil {
 		debuglog.Printf("error while converting to envelope: %v", err)
-		s.recorder.RecordItem(report.ReasonInternalError, item)
+		reason := report.ReasonInternalError
+		if errors.Is(err, protocol.ErrTooLarge) {
+			reason = report.ReasonTooLarge
+		}
+		s.recorder.RecordItem(reason, item)
 		return
 	}
+	for _, attachment := range s.limits.DropAttachments(envelope) {
+		debuglog.Printf("Dropping attachment %q of %d bytes, over the size limit", attachment.Header.Filename, len(attachment.Payload))
+		s.recorder.Record(report.ReasonTooLarge, ratelimit.CategoryAttachment, int64(len(attachment.Payload)))
+	}
 	if err := s.transport.SendEnvelope(envelope); err != nil {
 		debuglog.Printf("error sending envelope: %v", err)
 	}
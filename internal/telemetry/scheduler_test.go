@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -85,7 +86,7 @@ func TestNewTelemetryScheduler(t *testing.T) {
 		Version: "1.0.0",
 	}
 
-	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil)
+	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil, nil)
 
 	if scheduler == nil {
 		t.Fatal("Expected non-nil scheduler")
@@ -208,7 +209,7 @@ func TestTelemetrySchedulerFlush(t *testing.T) {
 			sdkInfo := &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
 
 			buffers := tt.setupBuffers()
-			scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil)
+			scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil, nil)
 
 			tt.addItems(buffers)
 
@@ -238,7 +239,7 @@ func TestTelemetrySchedulerRateLimiting(t *testing.T) {
 	// no log buffer used in simplified scheduler tests
 	sdkInfo := &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
 
-	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil)
+	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil, nil)
 
 	transport.SetRateLimited("error", true)
 
@@ -271,7 +272,7 @@ func TestTelemetrySchedulerStartStop(t *testing.T) {
 	// no log buffer used in simplified scheduler tests
 	sdkInfo := &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
 
-	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil)
+	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil, nil)
 
 	scheduler.Start()
 	scheduler.Start()
@@ -298,7 +299,7 @@ func TestTelemetrySchedulerContextCancellation(t *testing.T) {
 	}
 	sdkInfo := &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
 
-	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil)
+	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, nil, nil)
 
 	scheduler.Start()
 
@@ -332,7 +333,7 @@ func TestTelemetrySchedulerRecordsFullDiscardCountsOnEnvelopeError(t *testing.T)
 	}
 	sdkInfo := &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
 
-	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, recorder)
+	scheduler := NewScheduler(buffers, transport, dsn, func() *protocol.SdkInfo { return sdkInfo }, recorder, nil)
 
 	buffer.Offer(&failingTransactionTelemetryItem{
 		testTelemetryItem: testTelemetryItem{data: "tx", category: ratelimit.CategoryTransaction},
@@ -361,3 +362,111 @@ func TestTelemetrySchedulerRecordsFullDiscardCountsOnEnvelopeError(t *testing.T)
 		t.Fatalf("expected discarded span count to be recorded, got %d", outcomes[ratelimit.CategorySpan])
 	}
 }
+
+type sizedLogItem struct {
+	Body string `json:"body"`
+}
+
+func (sizedLogItem) GetCategory() ratelimit.Category { return ratelimit.CategoryLog }
+func (sizedLogItem) MakeSerializationSafe()          {}
+
+func TestTelemetrySchedulerSplitsBatchesOverTheSizeLimit(t *testing.T) {
+	transport := &testutils.MockTelemetryTransport{}
+	recorder := reportpkg.NewAggregator()
+
+	buffer := NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryLog, 10, OverflowPolicyDropOldest, 10, 0, nil)
+	buffers := map[ratelimit.Category]Buffer[protocol.TelemetryItem]{
+		ratelimit.CategoryLog: buffer,
+	}
+	sdkInfo := &protocol.SdkInfo{Name: "test-sdk", Version: "1.0.0"}
+
+	scheduler := NewScheduler(buffers, transport, &protocol.Dsn{}, func() *protocol.SdkInfo { return sdkInfo }, recorder, nil)
+	scheduler.limits.MaxBatchItems = 2
+
+	for i := 0; i < 5; i++ {
+		buffer.Offer(sizedLogItem{Body: "log"})
+	}
+	buffer.Offer(sizedLogItem{Body: strings.Repeat("x", protocol.DefaultLimits.MaxBatchSize)})
+
+	scheduler.Flush(time.Second)
+
+	if got := transport.GetSendCount(); got != 3 {
+		t.Errorf("expected 3 envelopes, got %d", got)
+	}
+	clientReport := recorder.TakeReport()
+	if clientReport == nil || len(clientReport.DiscardedEvents) != 1 {
+		t.Fatalf("expected one discarded outcome, got %+v", clientReport)
+	}
+	discarded := clientReport.DiscardedEvents[0]
+	if discarded.Reason != reportpkg.ReasonTooLarge || discarded.Category != ratelimit.CategoryLog || discarded.Quantity != 1 {
+		t.Errorf("unexpected outcome: %+v", discarded)
+	}
+}
+
+type tracedSpanItem struct {
+	TraceID string `json:"trace_id"`
+}
+
+func (tracedSpanItem) GetCategory() ratelimit.Category { return ratelimit.CategorySpan }
+func (tracedSpanItem) MakeSerializationSafe()          {}
+func (s tracedSpanItem) GetTraceID() (string, bool)    { return s.TraceID, s.TraceID != "" }
+func (s tracedSpanItem) GetDynamicSamplingContext() map[string]string {
+	return map[string]string{"trace_id": s.TraceID}
+}
+
+func TestTelemetrySchedulerSendsSpansOfATraceTogether(t *testing.T) {
+	transport := &testutils.MockTelemetryTransport{}
+
+	buffer := NewBucketedBuffer[protocol.TelemetryItem](ratelimit.CategorySpan, 10, OverflowPolicyDropOldest, 10, 0, nil)
+	buffers := map[ratelimit.Category]Buffer[protocol.TelemetryItem]{
+		ratelimit.CategorySpan: buffer,
+	}
+	scheduler := NewScheduler(buffers, transport, &protocol.Dsn{}, nil, nil, nil)
+
+	for _, traceID := range []string{"a", "b", "a"} {
+		buffer.Offer(tracedSpanItem{TraceID: traceID})
+	}
+	scheduler.Flush(time.Second)
+
+	envelopes := transport.GetSentEnvelopes()
+	if len(envelopes) != 2 {
+		t.Fatalf("expected one envelope per trace, got %d", len(envelopes))
+	}
+	counts := make(map[string]int)
+	for _, envelope := range envelopes {
+		item := envelope.Items[0]
+		if item.Header.Type != protocol.EnvelopeItemTypeSpan {
+			t.Errorf("expected a span item, got %q", item.Header.Type)
+		}
+		counts[envelope.Header.Trace["trace_id"]] = *item.Header.ItemCount
+	}
+	if counts["a"] != 2 || counts["b"] != 1 {
+		t.Errorf("unexpected spans by trace: %v", counts)
+	}
+}
+
+func TestNewSchedulerPriorityWeights(t *testing.T) {
+	buffers := map[ratelimit.Category]Buffer[protocol.TelemetryItem]{
+		ratelimit.CategoryError: NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryError, 10, OverflowPolicyDropOldest, 1, 0, nil),
+		ratelimit.CategoryLog:   NewRingBuffer[protocol.TelemetryItem](ratelimit.CategoryLog, 10, OverflowPolicyDropOldest, 1, 0, nil),
+	}
+	weights := map[ratelimit.Priority]int{
+		ratelimit.PriorityCritical: 1,
+		ratelimit.PriorityLow:      7,
+		ratelimit.PriorityHigh:     -1,
+	}
+
+	scheduler := NewScheduler(buffers, &testutils.MockTelemetryTransport{}, &protocol.Dsn{}, nil, nil, weights)
+
+	visits := map[ratelimit.Priority]int{}
+	for _, priority := range scheduler.currentCycle {
+		visits[priority]++
+	}
+	want := map[ratelimit.Priority]int{
+		ratelimit.PriorityCritical: 1,
+		ratelimit.PriorityLow:      7,
+	}
+	if len(visits) != len(want) || visits[ratelimit.PriorityCritical] != 1 || visits[ratelimit.PriorityLow] != 7 {
+		t.Errorf("got visits %v, want %v", visits, want)
+	}
+}
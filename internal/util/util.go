@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/getsentry/sentry-go/internal/debuglog"
 	"github.com/getsentry/sentry-go/internal/protocol"
@@ -76,6 +77,14 @@ func EnvelopeIdentifier(envelope *protocol.Envelope) string {
 			}
 		}
 		description = fmt.Sprintf("%d metric events", metricCount)
+	case protocol.EnvelopeItemTypeSpan:
+		spanCount := 0
+		for _, item := range envelope.Items {
+			if item != nil && item.Header != nil && item.Header.Type == protocol.EnvelopeItemTypeSpan && item.Header.ItemCount != nil {
+				spanCount += *item.Header.ItemCount
+			}
+		}
+		description = fmt.Sprintf("%d spans", spanCount)
 	default:
 		description = fmt.Sprintf("%s event", itemType)
 	}
@@ -83,11 +92,54 @@ func EnvelopeIdentifier(envelope *protocol.Envelope) string {
 	return fmt.Sprintf("%s [%s]", description, envelope.Header.EventID)
 }
 
+// EnvelopeCategory returns the rate limit category of the first reportable
+// item in the envelope. Attachments are skipped, as they are always sent
+// together with the item they belong to.
+func EnvelopeCategory(envelope *protocol.Envelope) ratelimit.Category {
+	if envelope == nil || len(envelope.Items) == 0 {
+		return ratelimit.CategoryAll
+	}
+
+	for _, item := range envelope.Items {
+		if item == nil || item.Header == nil {
+			continue
+		}
+
+		switch item.Header.Type {
+		case protocol.EnvelopeItemTypeEvent:
+			return ratelimit.CategoryError
+		case protocol.EnvelopeItemTypeTransaction:
+			return ratelimit.CategoryTransaction
+		case protocol.EnvelopeItemTypeCheckIn:
+			return ratelimit.CategoryMonitor
+		case protocol.EnvelopeItemTypeLog:
+			return ratelimit.CategoryLog
+		case protocol.EnvelopeItemTypeTraceMetric:
+			return ratelimit.CategoryTraceMetric
+		case protocol.EnvelopeItemTypeSpan:
+			return ratelimit.CategorySpan
+		case protocol.EnvelopeItemTypeProfile:
+			return ratelimit.CategoryProfile
+		case protocol.EnvelopeItemTypeProfileChunk:
+			return ratelimit.CategoryProfileChunk
+		case protocol.EnvelopeItemTypeAttachment:
+			continue
+		default:
+			return ratelimit.CategoryAll
+		}
+	}
+
+	return ratelimit.CategoryAll
+}
+
 // SendResult holds the outcome of an HTTP request sent to Sentry.
 type SendResult struct {
 	Success    bool
 	StatusCode int
 	Limits     ratelimit.Map
+	// RetryAfter is the delay requested by the Retry-After response header,
+	// or zero if the header was not set.
+	RetryAfter time.Duration
 }
 
 // IsSendError returns true if the response indicates a server/client error that should be recorded
@@ -96,6 +148,12 @@ func (r *SendResult) IsSendError() bool {
 	return !r.Success && r.StatusCode != http.StatusTooManyRequests
 }
 
+// IsRetryable returns true if the request failed because of a server error or
+// a request timeout, so sending the same payload again later may succeed.
+func (r *SendResult) IsRetryable() bool {
+	return !r.Success && (r.StatusCode >= http.StatusInternalServerError || r.StatusCode == http.StatusRequestTimeout)
+}
+
 // DoSendRequest executes an HTTP request, handles response logging, extracts rate limits, and
 // drains+closes the response body.
 func DoSendRequest(client *http.Client, request *http.Request, identifier string) (*SendResult, error) {
@@ -115,5 +173,6 @@ func DoSendRequest(client *http.Client, request *http.Request, identifier string
 		Success:    success,
 		StatusCode: response.StatusCode,
 		Limits:     limits,
+		RetryAfter: ratelimit.RetryAfter(response),
 	}, nil
 }
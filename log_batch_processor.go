@@ -2,6 +2,9 @@ package sentry
 
 import (
 	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/report"
 )
 
 // logBatchProcessor batches logs and sends them to Sentry.
@@ -16,13 +19,20 @@ func newLogBatchProcessor(client *Client) *logBatchProcessor {
 				return
 			}
 
-			event := NewEvent()
-			event.Timestamp = time.Now()
-			event.EventID = EventID(uuid())
-			event.Type = logEvent.Type
-			event.Logs = items
+			batches, tooLarge := protocol.SplitBatch(items, protocol.DefaultLimits)
+			for i := range tooLarge {
+				client.reportRecorder.RecordItem(report.ReasonTooLarge, &tooLarge[i])
+			}
+			for _, batch := range batches {
+				event := NewEvent()
+				event.Timestamp = time.Now()
+				event.EventID = EventID(uuid())
+				event.Type = logEvent.Type
+				event.Logs = batch.Items
+				event.sdkMetaData.serializedItems = batch.Payloads
 
-			client.Transport.SendEvent(event)
+				client.sendEvent(event)
+			}
 		}),
 	}
 }
@@ -0,0 +1,52 @@
+package sentry
+
+// Measurement is a numeric value of a transaction, such as the number of rows
+// returned by its queries. Unit is one of the Unit constants, or empty for
+// counts without a unit.
+type Measurement struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// SetMeasurement sets a measurement of the transaction the span belongs to.
+// Measurements are sent with the transaction, so calling SetMeasurement on a
+// child span sets it on its transaction.
+func (s *Span) SetMeasurement(name string, value float64, unit string) {
+	t := s.measurementTarget()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.measurements == nil {
+		t.measurements = make(map[string]Measurement)
+	}
+	t.measurements[name] = Measurement{Value: value, Unit: unit}
+}
+
+// AddMeasurement adds value to a measurement of the transaction the span
+// belongs to, starting from zero. It is meant for values that several spans
+// contribute to, such as the rows returned by all queries of a transaction.
+// The unit of the first call is kept.
+func (s *Span) AddMeasurement(name string, value float64, unit string) {
+	t := s.measurementTarget()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.measurements == nil {
+		t.measurements = make(map[string]Measurement)
+	}
+	m, ok := t.measurements[name]
+	if !ok {
+		m.Unit = unit
+	}
+	m.Value += value
+	t.measurements[name] = m
+}
+
+// measurementTarget returns the transaction of the span, or the span itself
+// if it was not started as part of a transaction.
+func (s *Span) measurementTarget() *Span {
+	if t := s.GetTransaction(); t != nil {
+		return t
+	}
+	return s
+}
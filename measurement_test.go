@@ -0,0 +1,76 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpan_SetMeasurement(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	transaction := StartTransaction(ctx, "transaction")
+	transaction.SetMeasurement("queue.depth", 3, "")
+	child := transaction.StartChild("child")
+	child.SetMeasurement("bytes.processed", 1024, UnitByte)
+	child.AddMeasurement("rows.returned", 2, "")
+	child.AddMeasurement("rows.returned", 5, "")
+	child.Finish()
+	transaction.Finish()
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	want := map[string]Measurement{
+		"queue.depth":     {Value: 3},
+		"bytes.processed": {Value: 1024, Unit: UnitByte},
+		"rows.returned":   {Value: 7},
+	}
+	assert.Equal(t, want, events[0].Measurements)
+
+	data, err := json.Marshal(events[0])
+	require.NoError(t, err)
+	var payload struct {
+		Measurements map[string]map[string]interface{} `json:"measurements"`
+		Spans        []map[string]interface{}          `json:"spans"`
+	}
+	require.NoError(t, json.Unmarshal(data, &payload))
+	assert.Equal(t, map[string]interface{}{"value": 1024.0, "unit": "byte"}, payload.Measurements["bytes.processed"])
+	assert.Equal(t, map[string]interface{}{"value": 3.0}, payload.Measurements["queue.depth"])
+	require.Len(t, payload.Spans, 1)
+	assert.NotContains(t, payload.Spans[0], "measurements")
+}
+
+func TestEvent_MeasurementsOnlyOnTransactions(t *testing.T) {
+	event := NewEvent()
+	event.Message = "error"
+	event.Measurements = map[string]Measurement{"queue.depth": {Value: 1}}
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "measurements")
+
+	event.MakeSerializationSafe()
+	data, err = json.Marshal(event)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "measurements")
+}
+
+func TestSpan_SetMeasurementWithoutTransaction(t *testing.T) {
+	span := &Span{}
+	span.SetMeasurement("queue.depth", 1, "")
+	assert.Equal(t, map[string]Measurement{"queue.depth": {Value: 1}}, span.measurements)
+
+	ctx := context.Background()
+	transaction := StartTransaction(ctx, "transaction")
+	transaction.AddMeasurement("queue.depth", 1, "")
+	transaction.AddMeasurement("queue.depth", 1, "")
+	assert.Equal(t, Measurement{Value: 2}, transaction.measurements["queue.depth"])
+}
@@ -98,7 +98,7 @@ type sentryMeter struct {
 	mu                sync.RWMutex
 }
 
-func (m *sentryMeter) emit(ctx context.Context, metricType MetricType, name string, value MetricValue, unit string, attributes map[string]attribute.Value, customScope *Scope) {
+func (m *sentryMeter) emit(ctx context.Context, metricType MetricType, name string, value MetricValue, o *meterOptions) {
 	if name == "" {
 		debuglog.Println("empty name provided, dropping metric")
 		return
@@ -115,13 +115,20 @@ func (m *sentryMeter) emit(ctx context.Context, metricType MetricType, name stri
 	}
 
 	scope := hub.Scope()
-	if customScope != nil {
-		scope = customScope
+	if o.scope != nil {
+		scope = o.scope
+	}
+	traceID, spanID := o.traceID, o.spanID
+	if traceID == zeroTraceID {
+		traceID, spanID = resolveTrace(scope, client, ctx, m.ctx)
+	}
+	timestamp := o.timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
 	}
-	traceID, spanID := resolveTrace(scope, client, ctx, m.ctx)
 
 	// Pre-allocate with capacity hint to avoid map growth reallocations
-	estimatedCap := len(m.defaultAttributes) + len(attributes) + 8 // scope ~3 + call-specific ~5
+	estimatedCap := len(m.defaultAttributes) + len(o.attributes) + 8 // scope ~3 + call-specific ~5
 	attrs := make(map[string]attribute.Value, estimatedCap)
 
 	// attribute precedence: default -> scope -> instance (from SetAttrs) -> entry-specific
@@ -136,23 +143,23 @@ func (m *sentryMeter) emit(ctx context.Context, metricType MetricType, name stri
 	}
 	m.mu.RUnlock()
 
-	for k, v := range attributes {
+	for k, v := range o.attributes {
 		attrs[k] = v
 	}
 
 	metric := &Metric{
-		Timestamp:  time.Now(),
+		Timestamp:  timestamp,
 		TraceID:    traceID,
 		SpanID:     spanID,
 		Type:       metricType,
 		Name:       name,
 		Value:      value,
-		Unit:       unit,
+		Unit:       o.unit,
 		Attributes: attrs,
 	}
 
 	if client.captureMetric(metric, scope) && client.options.Debug {
-		debuglog.Printf("Metric %s [%s]: %v %s", metricType, name, value.AsInterface(), unit)
+		debuglog.Printf("Metric %s [%s]: %v %s", metricType, name, value.AsInterface(), o.unit)
 	}
 }
 
@@ -182,19 +189,19 @@ func (m *sentryMeter) applyOptions(opts []MeterOption) *meterOptions {
 // Count implements Meter.
 func (m *sentryMeter) Count(name string, count int64, opts ...MeterOption) {
 	o := m.applyOptions(opts)
-	m.emit(m.ctx, MetricTypeCounter, name, Int64MetricValue(count), o.unit, o.attributes, o.scope)
+	m.emit(m.ctx, MetricTypeCounter, name, Int64MetricValue(count), o)
 }
 
 // Distribution implements Meter.
 func (m *sentryMeter) Distribution(name string, sample float64, opts ...MeterOption) {
 	o := m.applyOptions(opts)
-	m.emit(m.ctx, MetricTypeDistribution, name, Float64MetricValue(sample), o.unit, o.attributes, o.scope)
+	m.emit(m.ctx, MetricTypeDistribution, name, Float64MetricValue(sample), o)
 }
 
 // Gauge implements Meter.
 func (m *sentryMeter) Gauge(name string, value float64, opts ...MeterOption) {
 	o := m.applyOptions(opts)
-	m.emit(m.ctx, MetricTypeGauge, name, Float64MetricValue(value), o.unit, o.attributes, o.scope)
+	m.emit(m.ctx, MetricTypeGauge, name, Float64MetricValue(value), o)
 }
 
 // SetAttributes implements Meter.
@@ -787,3 +787,27 @@ func TestSentryMeter_ScopeSetAttributesNoLeak(t *testing.T) {
 	assert.Equal(t, attribute.BoolValue(true), metricsAfterScope[0].Attributes["key.bool"])
 	assert.Equal(t, attribute.StringValue("str"), metricsAfterScope[0].Attributes["key.string"])
 }
+
+func Test_sentryMeter_WithTraceAndTimestamp(t *testing.T) {
+	ctx, mockTransport := setupMetricsTest()
+	meter := NewMeter(ctx)
+
+	traceID := TraceIDFromHex("2f9a5c8e1b7d4e6fa3c0b1d2e3f40516")
+	spanID := SpanIDFromHex("a1b2c3d4e5f60718")
+	timestamp := time.Unix(1700000000, 0)
+	meter.Gauge("test.gauge", 1, WithTrace(traceID, spanID), WithTimestamp(timestamp))
+	meter.Gauge("test.gauge.zero.trace", 2, WithTrace(TraceID{}, spanID))
+	flushFromContext(ctx, testutils.FlushTimeout())
+
+	events := mockTransport.Events()
+	assert.Len(t, events, 1)
+	metrics := events[0].Metrics
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, traceID, metrics[0].TraceID)
+	assert.Equal(t, spanID, metrics[0].SpanID)
+	assert.True(t, timestamp.Equal(metrics[0].Timestamp))
+	// A zero trace ID leaves the trace of the scope.
+	assert.Equal(t, TraceIDFromHex(LogTraceID), metrics[1].TraceID)
+	assert.Equal(t, SpanID{}, metrics[1].SpanID)
+	assert.WithinDuration(t, time.Now(), metrics[1].Timestamp, time.Minute)
+}
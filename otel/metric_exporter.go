@@ -0,0 +1,283 @@
+package sentryotel
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	sentryattribute "github.com/getsentry/sentry-go/attribute"
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var errExporterShutdown = errors.New("sentryotel: metric exporter is shut down")
+
+// units maps the UCUM units of OpenTelemetry instruments to Sentry units.
+var units = map[string]string{
+	"ns":   sentry.UnitNanosecond,
+	"us":   sentry.UnitMicrosecond,
+	"ms":   sentry.UnitMillisecond,
+	"s":    sentry.UnitSecond,
+	"min":  sentry.UnitMinute,
+	"h":    sentry.UnitHour,
+	"d":    sentry.UnitDay,
+	"bit":  sentry.UnitBit,
+	"By":   sentry.UnitByte,
+	"kBy":  sentry.UnitKilobyte,
+	"KiBy": sentry.UnitKibibyte,
+	"MBy":  sentry.UnitMegabyte,
+	"MiBy": sentry.UnitMebibyte,
+	"GBy":  sentry.UnitGigabyte,
+	"GiBy": sentry.UnitGibibyte,
+	"TBy":  sentry.UnitTerabyte,
+	"TiBy": sentry.UnitTebibyte,
+	"%":    sentry.UnitPercent,
+	"1":    sentry.UnitRatio,
+}
+
+type sentryMetricExporter struct {
+	shutdown atomic.Bool
+}
+
+// NewSentryMetricExporter returns an OpenTelemetry metric exporter that
+// records the metrics of a meter provider as Sentry trace metrics, with the
+// Meter of the hub found in the export context, or of the current hub. The
+// metrics are sent by the metric batch processor of the client, like the
+// metrics of sentry.NewMeter.
+//
+// Monotonic sums, from counters, are recorded as counters of their change
+// since the previous export. Float values of counters are rounded, as Sentry
+// counters are integers. Gauges and non-monotonic sums, from up-down counters,
+// are recorded as gauges of their current value. Each measurement of a
+// histogram is recorded as a distribution sample: exemplars keep their value,
+// the other measurements are approximated by the middle of their bucket.
+//
+// The trace and span of a metric are taken from the exemplars of its data
+// point, recorded for the measurements made within sampled spans.
+//
+//	reader := sdkmetric.NewPeriodicReader(sentryotel.NewSentryMetricExporter())
+//	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+func NewSentryMetricExporter() sdkmetric.Exporter {
+	return &sentryMetricExporter{}
+}
+
+// Temporality returns the delta temporality for counters and histograms,
+// whose changes are recorded, and the cumulative temporality for up-down
+// counters and gauges, whose values are recorded.
+func (e *sentryMetricExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case sdkmetric.InstrumentKindUpDownCounter, sdkmetric.InstrumentKindObservableUpDownCounter,
+		sdkmetric.InstrumentKindGauge, sdkmetric.InstrumentKindObservableGauge:
+		return metricdata.CumulativeTemporality
+	default:
+		return metricdata.DeltaTemporality
+	}
+}
+
+func (e *sentryMetricExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(kind)
+}
+
+func (e *sentryMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	if e.shutdown.Load() {
+		return errExporterShutdown
+	}
+	meter := sentry.NewMeter(ctx)
+	resource := convertAttributes(rm.Resource.Attributes())
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			recordMetric(meter, m, resource)
+		}
+	}
+	return nil
+}
+
+func (e *sentryMetricExporter) ForceFlush(ctx context.Context) error {
+	return flush(ctx)
+}
+
+func (e *sentryMetricExporter) Shutdown(ctx context.Context) error {
+	if e.shutdown.Swap(true) {
+		return nil
+	}
+	return flush(ctx)
+}
+
+// recordMetric records the data points of an OpenTelemetry metric with the
+// Sentry meter. Resource attributes have a lower precedence than the
+// attributes of the data points.
+func recordMetric(meter sentry.Meter, m metricdata.Metrics, resource []sentryattribute.Builder) {
+	unit := convertUnit(m.Unit)
+	switch data := m.Data.(type) {
+	case metricdata.Sum[int64]:
+		recordSum(meter, m.Name, unit, data, resource)
+	case metricdata.Sum[float64]:
+		recordSum(meter, m.Name, unit, data, resource)
+	case metricdata.Gauge[int64]:
+		recordGauge(meter, m.Name, unit, data.DataPoints, resource)
+	case metricdata.Gauge[float64]:
+		recordGauge(meter, m.Name, unit, data.DataPoints, resource)
+	case metricdata.Histogram[int64]:
+		recordHistogram(meter, m.Name, unit, data, resource)
+	case metricdata.Histogram[float64]:
+		recordHistogram(meter, m.Name, unit, data, resource)
+	default:
+		debuglog.Printf("Dropping metric %q: unsupported aggregation %T", m.Name, m.Data)
+	}
+}
+
+func recordSum[N int64 | float64](meter sentry.Meter, name, unit string, sum metricdata.Sum[N], resource []sentryattribute.Builder) {
+	if !sum.IsMonotonic || sum.Temporality != metricdata.DeltaTemporality {
+		recordGauge(meter, name, unit, sum.DataPoints, resource)
+		return
+	}
+	for _, dp := range sum.DataPoints {
+		if dp.Value == 0 {
+			continue
+		}
+		meter.Count(name, int64(math.Round(float64(dp.Value))),
+			meterOptions(unit, resource, dp.Attributes, dp.Time, dp.Exemplars)...)
+	}
+}
+
+func recordGauge[N int64 | float64](meter sentry.Meter, name, unit string, dataPoints []metricdata.DataPoint[N], resource []sentryattribute.Builder) {
+	for _, dp := range dataPoints {
+		meter.Gauge(name, float64(dp.Value),
+			meterOptions(unit, resource, dp.Attributes, dp.Time, dp.Exemplars)...)
+	}
+}
+
+func recordHistogram[N int64 | float64](meter sentry.Meter, name, unit string, histogram metricdata.Histogram[N], resource []sentryattribute.Builder) {
+	for _, dp := range histogram.DataPoints {
+		// The options are clipped, so that the exemplars do not share the
+		// trace option appended to them.
+		options := slices.Clip(meterOptions[N](unit, resource, dp.Attributes, dp.Time, nil))
+		counts := append([]uint64(nil), dp.BucketCounts...)
+		for _, exemplar := range dp.Exemplars {
+			value := float64(exemplar.Value)
+			if bucket := bucketIndex(dp.Bounds, value); bucket < len(counts) && counts[bucket] > 0 {
+				counts[bucket]--
+			}
+			meter.Distribution(name, value, append(options, exemplarTrace(exemplar))...)
+		}
+		for bucket, count := range counts {
+			value := bucketValue(dp, bucket)
+			for range count {
+				meter.Distribution(name, value, options...)
+			}
+		}
+	}
+}
+
+// bucketIndex returns the index of the histogram bucket of value. Buckets
+// include their upper bound.
+func bucketIndex(bounds []float64, value float64) int {
+	for i, bound := range bounds {
+		if value <= bound {
+			return i
+		}
+	}
+	return len(bounds)
+}
+
+// bucketValue returns the middle of a histogram bucket, within the minimum
+// and maximum of the data point if recorded. The first and last buckets are
+// unbounded, their value is their bound.
+func bucketValue[N int64 | float64](dp metricdata.HistogramDataPoint[N], bucket int) float64 {
+	var value float64
+	switch {
+	case len(dp.Bounds) == 0:
+		value = float64(dp.Sum) / float64(dp.Count)
+	case bucket == 0:
+		value = dp.Bounds[0]
+	case bucket == len(dp.Bounds):
+		value = dp.Bounds[bucket-1]
+	default:
+		value = (dp.Bounds[bucket-1] + dp.Bounds[bucket]) / 2
+	}
+	if minimum, ok := dp.Min.Value(); ok {
+		value = math.Max(value, float64(minimum))
+	}
+	if maximum, ok := dp.Max.Value(); ok {
+		value = math.Min(value, float64(maximum))
+	}
+	return value
+}
+
+// meterOptions returns the options of the metrics of a data point, linked to
+// the trace of its last exemplar with a trace.
+func meterOptions[N int64 | float64](unit string, resource []sentryattribute.Builder, attributes attribute.Set, timestamp time.Time, exemplars []metricdata.Exemplar[N]) []sentry.MeterOption {
+	attrs := append(append([]sentryattribute.Builder(nil), resource...), convertAttributes(attributes.ToSlice())...)
+	options := []sentry.MeterOption{sentry.WithUnit(unit), sentry.WithAttributes(attrs...)}
+	if !timestamp.IsZero() {
+		options = append(options, sentry.WithTimestamp(timestamp))
+	}
+	for i := len(exemplars) - 1; i >= 0; i-- {
+		if len(exemplars[i].TraceID) == len(sentry.TraceID{}) {
+			options = append(options, exemplarTrace(exemplars[i]))
+			break
+		}
+	}
+	return options
+}
+
+// exemplarTrace links a metric to the trace and span of an exemplar. The
+// option is ignored by the meter if the exemplar has no trace.
+func exemplarTrace[N int64 | float64](exemplar metricdata.Exemplar[N]) sentry.MeterOption {
+	var traceID sentry.TraceID
+	var spanID sentry.SpanID
+	if len(exemplar.TraceID) == len(traceID) && len(exemplar.SpanID) == len(spanID) {
+		copy(traceID[:], exemplar.TraceID)
+		copy(spanID[:], exemplar.SpanID)
+	}
+	return sentry.WithTrace(traceID, spanID)
+}
+
+// convertAttributes converts OpenTelemetry attributes to Sentry attributes.
+// Values of types without a Sentry equivalent are converted to strings.
+func convertAttributes(kvs []attribute.KeyValue) []sentryattribute.Builder {
+	attrs := make([]sentryattribute.Builder, 0, len(kvs))
+	for _, kv := range kvs {
+		key := string(kv.Key)
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			attrs = append(attrs, sentryattribute.Bool(key, kv.Value.AsBool()))
+		case attribute.INT64:
+			attrs = append(attrs, sentryattribute.Int64(key, kv.Value.AsInt64()))
+		case attribute.FLOAT64:
+			attrs = append(attrs, sentryattribute.Float64(key, kv.Value.AsFloat64()))
+		case attribute.STRING:
+			attrs = append(attrs, sentryattribute.String(key, kv.Value.AsString()))
+		case attribute.BOOLSLICE:
+			attrs = append(attrs, sentryattribute.BoolSlice(key, kv.Value.AsBoolSlice()))
+		case attribute.INT64SLICE:
+			attrs = append(attrs, sentryattribute.Int64Slice(key, kv.Value.AsInt64Slice()))
+		case attribute.FLOAT64SLICE:
+			attrs = append(attrs, sentryattribute.Float64Slice(key, kv.Value.AsFloat64Slice()))
+		case attribute.STRINGSLICE:
+			attrs = append(attrs, sentryattribute.StringSlice(key, kv.Value.AsStringSlice()))
+		default:
+			attrs = append(attrs, sentryattribute.String(key, kv.Value.Emit()))
+		}
+	}
+	return attrs
+}
+
+// convertUnit returns the Sentry unit of an OpenTelemetry unit. Annotations,
+// such as {request}, are dropped, unknown units are kept as they are.
+func convertUnit(unit string) string {
+	if u, ok := units[unit]; ok {
+		return u
+	}
+	if strings.HasPrefix(unit, "{") && strings.HasSuffix(unit, "}") {
+		return ""
+	}
+	return unit
+}
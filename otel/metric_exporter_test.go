@@ -0,0 +1,192 @@
+package sentryotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	sentryattribute "github.com/getsentry/sentry-go/attribute"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// setupMetricExporter binds a client to the current hub, which receives the
+// metrics exported by the periodic reader, and returns a meter provider that
+// exports when flushed.
+func setupMetricExporter(t *testing.T) (*sdkmetric.MeterProvider, *sentry.MockTransport) {
+	t.Helper()
+	transport := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Transport: transport,
+		Release:   "1.0.0",
+	})
+	require.NoError(t, err)
+	hub := sentry.CurrentHub()
+	previous := hub.Client()
+	hub.BindClient(client)
+	t.Cleanup(func() { hub.BindClient(previous) })
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(NewSentryMetricExporter())),
+		sdkmetric.WithResource(sdkresource.NewSchemaless(attribute.String("service.name", "users"))),
+	)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return provider, transport
+}
+
+func exportedMetrics(t *testing.T, provider *sdkmetric.MeterProvider, transport *sentry.MockTransport) map[string][]sentry.Metric {
+	t.Helper()
+	require.NoError(t, provider.ForceFlush(context.Background()))
+	metrics := make(map[string][]sentry.Metric)
+	for _, event := range transport.Events() {
+		for _, m := range event.Metrics {
+			metrics[m.Name] = append(metrics[m.Name], m)
+		}
+	}
+	return metrics
+}
+
+func TestMetricExporter(t *testing.T) {
+	provider, transport := setupMetricExporter(t)
+	meter := provider.Meter("test")
+	ctx := context.Background()
+
+	requests, err := meter.Int64Counter("http.requests", metric.WithUnit("{request}"))
+	require.NoError(t, err)
+	requests.Add(ctx, 2, metric.WithAttributes(attribute.String("http.route", "/users")))
+	requests.Add(ctx, 3, metric.WithAttributes(attribute.String("http.route", "/users")))
+
+	inFlight, err := meter.Int64UpDownCounter("http.in_flight")
+	require.NoError(t, err)
+	inFlight.Add(ctx, 4)
+	inFlight.Add(ctx, -1)
+
+	temperature, err := meter.Float64Gauge("temperature", metric.WithUnit("1"))
+	require.NoError(t, err)
+	temperature.Record(ctx, 0.75)
+
+	duration, err := meter.Float64Histogram("http.duration", metric.WithUnit("ms"))
+	require.NoError(t, err)
+	duration.Record(ctx, 3)
+	duration.Record(ctx, 7)
+
+	metrics := exportedMetrics(t, provider, transport)
+
+	require.Len(t, metrics["http.requests"], 1)
+	counter := metrics["http.requests"][0]
+	assert.Equal(t, sentry.MetricTypeCounter, counter.Type)
+	assert.Equal(t, sentry.Int64MetricValue(5), counter.Value)
+	assert.Empty(t, counter.Unit)
+	assert.Equal(t, "/users", counter.Attributes["http.route"].AsString())
+	assert.Equal(t, "users", counter.Attributes["service.name"].AsString())
+	assert.Equal(t, "1.0.0", counter.Attributes["sentry.release"].AsString())
+
+	require.Len(t, metrics["http.in_flight"], 1)
+	assert.Equal(t, sentry.MetricTypeGauge, metrics["http.in_flight"][0].Type)
+	assert.Equal(t, sentry.Float64MetricValue(3), metrics["http.in_flight"][0].Value)
+
+	require.Len(t, metrics["temperature"], 1)
+	assert.Equal(t, sentry.MetricTypeGauge, metrics["temperature"][0].Type)
+	assert.Equal(t, sentry.Float64MetricValue(0.75), metrics["temperature"][0].Value)
+	assert.Equal(t, sentry.UnitRatio, metrics["temperature"][0].Unit)
+
+	// The measurements are the only ones of their buckets, the middle of the
+	// buckets is bounded by the minimum and maximum of the histogram.
+	require.Len(t, metrics["http.duration"], 2)
+	var samples []sentry.MetricValue
+	for _, m := range metrics["http.duration"] {
+		assert.Equal(t, sentry.MetricTypeDistribution, m.Type)
+		assert.Equal(t, sentry.UnitMillisecond, m.Unit)
+		samples = append(samples, m.Value)
+	}
+	assert.ElementsMatch(t, []sentry.MetricValue{sentry.Float64MetricValue(3), sentry.Float64MetricValue(7)}, samples)
+
+	// Counters are exported as deltas.
+	requests.Add(ctx, 1)
+	metrics = exportedMetrics(t, provider, transport)
+	require.Len(t, metrics["http.requests"], 2)
+	assert.Equal(t, sentry.Int64MetricValue(1), metrics["http.requests"][1].Value)
+}
+
+func TestMetricExporter_ExemplarTrace(t *testing.T) {
+	provider, transport := setupMetricExporter(t)
+	tp := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "job")
+	jobs, err := provider.Meter("test").Int64Counter("jobs")
+	require.NoError(t, err)
+	jobs.Add(ctx, 1)
+	span.End()
+
+	metrics := exportedMetrics(t, provider, transport)
+	require.Len(t, metrics["jobs"], 1)
+	assert.Equal(t, sentry.TraceID(span.SpanContext().TraceID()), metrics["jobs"][0].TraceID)
+	assert.Equal(t, sentry.SpanID(span.SpanContext().SpanID()), metrics["jobs"][0].SpanID)
+}
+
+func TestMetricExporter_Shutdown(t *testing.T) {
+	exporter := NewSentryMetricExporter()
+	require.NoError(t, exporter.Shutdown(context.Background()))
+	assert.ErrorIs(t, exporter.Export(context.Background(), &metricdata.ResourceMetrics{}), errExporterShutdown)
+}
+
+func TestMetricExporter_Temporality(t *testing.T) {
+	exporter := NewSentryMetricExporter()
+	assert.Equal(t, metricdata.DeltaTemporality, exporter.Temporality(sdkmetric.InstrumentKindCounter))
+	assert.Equal(t, metricdata.DeltaTemporality, exporter.Temporality(sdkmetric.InstrumentKindHistogram))
+	assert.Equal(t, metricdata.CumulativeTemporality, exporter.Temporality(sdkmetric.InstrumentKindUpDownCounter))
+	assert.Equal(t, metricdata.CumulativeTemporality, exporter.Temporality(sdkmetric.InstrumentKindObservableGauge))
+}
+
+func TestBucketValue(t *testing.T) {
+	dp := metricdata.HistogramDataPoint[float64]{
+		Bounds: []float64{0, 10, 100},
+		Min:    metricdata.NewExtrema(-5.0),
+		Max:    metricdata.NewExtrema(500.0),
+	}
+	assert.Equal(t, 0.0, bucketValue(dp, 0))
+	assert.Equal(t, 5.0, bucketValue(dp, 1))
+	assert.Equal(t, 55.0, bucketValue(dp, 2))
+	assert.Equal(t, 100.0, bucketValue(dp, 3))
+
+	assert.Equal(t, 1, bucketIndex(dp.Bounds, 10))
+	assert.Equal(t, 3, bucketIndex(dp.Bounds, 101))
+}
+
+func TestConvertAttributes(t *testing.T) {
+	attrs := convertAttributes([]attribute.KeyValue{
+		attribute.Bool("bool", true),
+		attribute.Int("int", 42),
+		attribute.Float64("float", 1.5),
+		attribute.String("string", "value"),
+		attribute.StringSlice("strings", []string{"a", "b"}),
+	})
+	assert.Equal(t, []sentryattribute.Builder{
+		sentryattribute.Bool("bool", true),
+		sentryattribute.Int64("int", 42),
+		sentryattribute.Float64("float", 1.5),
+		sentryattribute.String("string", "value"),
+		sentryattribute.StringSlice("strings", []string{"a", "b"}),
+	}, attrs)
+}
+
+func TestConvertUnit(t *testing.T) {
+	tests := map[string]string{
+		"ms":        sentry.UnitMillisecond,
+		"By":        sentry.UnitByte,
+		"%":         sentry.UnitPercent,
+		"{request}": "",
+		"furlong":   "furlong",
+		"":          "",
+	}
+	for unit, want := range tests {
+		assert.Equal(t, want, convertUnit(unit), unit)
+	}
+}
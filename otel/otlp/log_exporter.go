@@ -0,0 +1,72 @@
+package sentryotlp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type sentryOTLPLogExporter struct {
+	inner sdklog.Exporter
+}
+
+// NewLogExporter creates a new log Exporter that sends the logs of the
+// OpenTelemetry logs SDK to Sentry via the OTLP HTTP protocol.
+//
+// The endpoint, URL path, headers, and HTTP/HTTPS mode are derived from the DSN.
+func NewLogExporter(ctx context.Context, dsn string, opts ...Option) (sdklog.Exporter, error) {
+	cfg := newConfig(opts)
+	otlpOpts, err := buildLogOptions(dsn, cfg.logOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err := otlploghttp.New(ctx, otlpOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("sentryotlp: failed to create OTLP log exporter: %w", err)
+	}
+
+	return &sentryOTLPLogExporter{inner: inner}, nil
+}
+
+func buildLogOptions(dsn string, opts ...otlploghttp.Option) ([]otlploghttp.Option, error) {
+	parsedDSN, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	logsURL := otlpLogsURL(parsedDSN)
+	otlpOpts := []otlploghttp.Option{
+		otlploghttp.WithEndpoint(logsURL.Host),
+		otlploghttp.WithURLPath(logsURL.EscapedPath()),
+		otlploghttp.WithHeaders(sentryAuthHeaders(parsedDSN)),
+	}
+	if logsURL.Scheme == "http" {
+		otlpOpts = append(otlpOpts, otlploghttp.WithInsecure())
+	}
+	otlpOpts = append(otlpOpts, opts...)
+	return otlpOpts, nil
+}
+
+func otlpLogsURL(dsn *sentry.Dsn) *url.URL {
+	return otlpURL(dsn, "logs")
+}
+
+// Export exports a batch of log records to Sentry via OTLP HTTP.
+func (e *sentryOTLPLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	return e.inner.Export(ctx, records)
+}
+
+// Shutdown shuts down the exporter. Export fails after it is called.
+func (e *sentryOTLPLogExporter) Shutdown(ctx context.Context) error {
+	return e.inner.Shutdown(ctx)
+}
+
+// ForceFlush flushes the log records held by the exporter.
+func (e *sentryOTLPLogExporter) ForceFlush(ctx context.Context) error {
+	return e.inner.ForceFlush(ctx)
+}
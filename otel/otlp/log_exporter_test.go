@@ -0,0 +1,205 @@
+package sentryotlp
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpLogsReceiver is a local OTLP HTTP logs endpoint that records the
+// requests it receives.
+type otlpLogsReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	logs     []*collogspb.ExportLogsServiceRequest
+	// failures is the number of requests answered with 503 before the
+	// receiver accepts the logs.
+	failures int
+}
+
+func (r *otlpLogsReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		body = gz
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logs := &collogspb.ExportLogsServiceRequest{}
+	if err := proto.Unmarshal(data, logs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.logs = append(r.logs, logs)
+
+	response, _ := proto.Marshal(&collogspb.ExportLogsServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(response)
+}
+
+func (r *otlpLogsReceiver) bodies() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var bodies []string
+	for _, logs := range r.logs {
+		for _, resourceLogs := range logs.GetResourceLogs() {
+			for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+				for _, record := range scopeLogs.GetLogRecords() {
+					bodies = append(bodies, record.GetBody().GetStringValue())
+				}
+			}
+		}
+	}
+	return bodies
+}
+
+func emitLog(t *testing.T, exporter sdklog.Exporter, body string) {
+	t.Helper()
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	var record otellog.Record
+	record.SetTimestamp(time.Now())
+	record.SetSeverity(otellog.SeverityInfo)
+	record.SetBody(otellog.StringValue(body))
+	provider.Logger("test").Emit(context.Background(), record)
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestOTLPLogsURL(t *testing.T) {
+	t.Parallel()
+
+	dsn, err := sentry.NewDsn("https://key@sentry.example.com/prefix/123")
+	if err != nil {
+		t.Fatalf("failed to parse DSN: %v", err)
+	}
+	want := "https://sentry.example.com/prefix/api/123/integration/otlp/v1/logs/"
+	if got := otlpLogsURL(dsn).String(); got != want {
+		t.Errorf("otlpLogsURL() = %q, want %q", got, want)
+	}
+}
+
+func TestNewLogExporter_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewLogExporter(context.Background(), ""); err == nil || !strings.Contains(err.Error(), "dsn must be provided") {
+		t.Errorf("NewLogExporter() error = %v, want a missing DSN error", err)
+	}
+	if _, err := NewLogExporter(context.Background(), "not-a-valid-dsn"); err == nil || !strings.Contains(err.Error(), "invalid DSN") {
+		t.Errorf("NewLogExporter() error = %v, want an invalid DSN error", err)
+	}
+}
+
+func TestLogExporter_Export(t *testing.T) {
+	t.Parallel()
+
+	receiver := &otlpLogsReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://mykey@", 1) + "/42"
+	exporter, err := NewLogExporter(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("NewLogExporter() error = %v", err)
+	}
+	emitLog(t, exporter, "user signed in")
+
+	if got := receiver.bodies(); len(got) != 1 || got[0] != "user signed in" {
+		t.Fatalf("bodies = %q, want [\"user signed in\"]", got)
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	req := receiver.requests[0]
+	if req.URL.Path != "/api/42/integration/otlp/v1/logs/" {
+		t.Errorf("path = %q, want the OTLP logs endpoint of the project", req.URL.Path)
+	}
+	if auth := req.Header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=mykey") {
+		t.Errorf("X-Sentry-Auth = %q, want the public key of the DSN", auth)
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/x-protobuf" {
+		t.Errorf("Content-Type = %q, want application/x-protobuf", contentType)
+	}
+}
+
+func TestLogExporter_Options(t *testing.T) {
+	t.Parallel()
+
+	receiver := &otlpLogsReceiver{failures: 1}
+	srv := httptest.NewTLSServer(receiver)
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "https://", "https://mykey@", 1) + "/42"
+	exporter, err := NewLogExporter(context.Background(), dsn,
+		WithTLSClientConfig(srv.Client().Transport.(*http.Transport).TLSClientConfig),
+		WithCompression(otlptracehttp.GzipCompression),
+		WithTimeout(5*time.Second),
+		WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         true,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+			MaxElapsedTime:  time.Second,
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewLogExporter() error = %v", err)
+	}
+	emitLog(t, exporter, "retried")
+
+	if got := receiver.bodies(); len(got) != 1 || got[0] != "retried" {
+		t.Fatalf("bodies = %q, want [\"retried\"]", got)
+	}
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if len(receiver.requests) != 2 {
+		t.Fatalf("got %d requests, want a retry after the failed one", len(receiver.requests))
+	}
+	if encoding := receiver.requests[1].Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", encoding)
+	}
+}
+
+func TestBuildLogOptions_WithAdditionalClientOptions(t *testing.T) {
+	t.Parallel()
+
+	cfg := newConfig([]Option{WithTimeout(3 * time.Second), WithCompression(otlptracehttp.GzipCompression)})
+	if len(cfg.logOptions) != 2 {
+		t.Fatalf("expected 2 log options, got %d", len(cfg.logOptions))
+	}
+
+	opts, err := buildLogOptions("https://testkey@o123.ingest.sentry.io/789", cfg.logOptions...)
+	if err != nil {
+		t.Fatalf("buildLogOptions() error = %v", err)
+	}
+	if len(opts) != 5 {
+		t.Fatalf("expected defaults plus custom options, got %d", len(opts))
+	}
+}
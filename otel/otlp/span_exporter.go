@@ -10,24 +10,35 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 const apiVersion = "7"
 
-// Option configures safe OTLP HTTP client behavior for the Sentry exporter.
+// Option configures safe OTLP HTTP client behavior for the Sentry exporters.
 // Target selection and auth remain derived from the DSN.
 type Option func(*config)
 
 type config struct {
 	otlpOptions []otlptracehttp.Option
+	logOptions  []otlploghttp.Option
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
 }
 
 // WithCompression configures OTLP payload compression.
 func WithCompression(compression otlptracehttp.Compression) Option {
 	return func(c *config) {
 		c.otlpOptions = append(c.otlpOptions, otlptracehttp.WithCompression(compression))
+		c.logOptions = append(c.logOptions, otlploghttp.WithCompression(otlploghttp.Compression(compression)))
 	}
 }
 
@@ -35,6 +46,7 @@ func WithCompression(compression otlptracehttp.Compression) Option {
 func WithTLSClientConfig(tlsCfg *tls.Config) Option {
 	return func(c *config) {
 		c.otlpOptions = append(c.otlpOptions, otlptracehttp.WithTLSClientConfig(tlsCfg))
+		c.logOptions = append(c.logOptions, otlploghttp.WithTLSClientConfig(tlsCfg))
 	}
 }
 
@@ -42,6 +54,7 @@ func WithTLSClientConfig(tlsCfg *tls.Config) Option {
 func WithTimeout(duration time.Duration) Option {
 	return func(c *config) {
 		c.otlpOptions = append(c.otlpOptions, otlptracehttp.WithTimeout(duration))
+		c.logOptions = append(c.logOptions, otlploghttp.WithTimeout(duration))
 	}
 }
 
@@ -49,6 +62,7 @@ func WithTimeout(duration time.Duration) Option {
 func WithRetry(rc otlptracehttp.RetryConfig) Option {
 	return func(c *config) {
 		c.otlpOptions = append(c.otlpOptions, otlptracehttp.WithRetry(rc))
+		c.logOptions = append(c.logOptions, otlploghttp.WithRetry(otlploghttp.RetryConfig(rc)))
 	}
 }
 
@@ -60,11 +74,7 @@ type sentryOTLPExporter struct {
 //
 // The endpoint, URL path, headers, and HTTP/HTTPS mode are derived from the DSN.
 func NewTraceExporter(ctx context.Context, dsn string, opts ...Option) (sdktrace.SpanExporter, error) {
-	cfg := &config{}
-	for _, opt := range opts {
-		opt(cfg)
-	}
-
+	cfg := newConfig(opts)
 	otlpOpts, err := buildOTLPOptions(dsn, cfg.otlpOptions...)
 	if err != nil {
 		return nil, err
@@ -79,31 +89,44 @@ func NewTraceExporter(ctx context.Context, dsn string, opts ...Option) (sdktrace
 }
 
 func buildOTLPOptions(dsn string, opts ...otlptracehttp.Option) ([]otlptracehttp.Option, error) {
-	if dsn == "" {
-		return nil, errors.New("sentryotlp: dsn must be provided")
-	}
-
-	parsedDSN, err := sentry.NewDsn(dsn)
+	parsedDSN, err := parseDSN(dsn)
 	if err != nil {
-		return nil, fmt.Errorf("sentryotlp: invalid DSN: %w", err)
+		return nil, err
 	}
 
-	otlpURL := otlpTracesURL(parsedDSN)
+	tracesURL := otlpTracesURL(parsedDSN)
 	otlpOpts := []otlptracehttp.Option{
-		otlptracehttp.WithEndpoint(otlpURL.Host),
-		otlptracehttp.WithURLPath(otlpURL.EscapedPath()),
+		otlptracehttp.WithEndpoint(tracesURL.Host),
+		otlptracehttp.WithURLPath(tracesURL.EscapedPath()),
 		otlptracehttp.WithHeaders(sentryAuthHeaders(parsedDSN)),
 	}
-	if otlpURL.Scheme == "http" {
+	if tracesURL.Scheme == "http" {
 		otlpOpts = append(otlpOpts, otlptracehttp.WithInsecure())
 	}
 	otlpOpts = append(otlpOpts, opts...)
 	return otlpOpts, nil
 }
 
+func parseDSN(dsn string) (*sentry.Dsn, error) {
+	if dsn == "" {
+		return nil, errors.New("sentryotlp: dsn must be provided")
+	}
+	parsedDSN, err := sentry.NewDsn(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sentryotlp: invalid DSN: %w", err)
+	}
+	return parsedDSN, nil
+}
+
 func otlpTracesURL(dsn *sentry.Dsn) *url.URL {
+	return otlpURL(dsn, "traces")
+}
+
+// otlpURL returns the URL of the OTLP endpoint of a signal, such as traces or
+// logs, of the project of the DSN.
+func otlpURL(dsn *sentry.Dsn, signal string) *url.URL {
 	apiURL := dsn.GetAPIURL()
-	apiURL.Path = strings.TrimSuffix(apiURL.Path, "/envelope/") + "/integration/otlp/v1/traces/"
+	apiURL.Path = strings.TrimSuffix(apiURL.Path, "/envelope/") + "/integration/otlp/v1/" + signal + "/"
 	return apiURL
 }
 
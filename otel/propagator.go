@@ -0,0 +1,141 @@
+package sentryotel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sentryBaggagePrefix is the prefix of the baggage members of the dynamic
+// sampling context.
+const sentryBaggagePrefix = "sentry-"
+
+// sentryTraceContextKey and dynamicSamplingContextKey store the trace
+// extracted by the propagator, to be continued by the span processor.
+type (
+	sentryTraceContextKey     struct{}
+	dynamicSamplingContextKey struct{}
+)
+
+type sentryPropagator struct{}
+
+// NewSentryPropagator returns an OpenTelemetry propagator that reads and
+// writes the sentry-trace and baggage headers.
+//
+// Extract continues the trace of the sentry-trace header as the remote span
+// context, and keeps the dynamic sampling context of the baggage header for
+// the transactions of NewSentrySpanProcessor. The other baggage members are
+// available with baggage.FromContext.
+//
+// Inject writes the trace and the dynamic sampling context of the Sentry span
+// of the OpenTelemetry span of the context, along with the baggage of the
+// context.
+func NewSentryPropagator() propagation.TextMapPropagator {
+	return sentryPropagator{}
+}
+
+func (sentryPropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
+	}
+
+	var sentryTrace, sentryBaggage string
+	if span, ok := sentrySpans.Get(spanContext.SpanID()); ok {
+		sentryTrace = span.ToSentryTrace()
+		sentryBaggage = span.ToBaggage()
+	} else {
+		// The span is remote or was not converted, its trace is propagated
+		// as received.
+		sentryTrace, sentryBaggage = traceHeaders(ctx, spanContext)
+	}
+	carrier.Set(sentry.SentryTraceHeader, sentryTrace)
+
+	var members []string
+	if sentryBaggage != "" {
+		members = append(members, sentryBaggage)
+	}
+	for _, member := range baggage.FromContext(ctx).Members() {
+		if !strings.HasPrefix(member.Key(), sentryBaggagePrefix) {
+			members = append(members, member.String())
+		}
+	}
+	if len(members) > 0 {
+		carrier.Set(sentry.SentryBaggageHeader, strings.Join(members, ","))
+	}
+}
+
+func (sentryPropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	if sentryTrace := carrier.Get(sentry.SentryTraceHeader); sentryTrace != "" {
+		if traceParentContext, valid := sentry.ParseTraceParentContext([]byte(sentryTrace)); valid {
+			ctx = context.WithValue(ctx, sentryTraceContextKey{}, traceParentContext)
+			traceFlags := trace.FlagsSampled
+			if traceParentContext.Sampled == sentry.SampledFalse {
+				traceFlags = 0
+			}
+			ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID(traceParentContext.TraceID),
+				SpanID:     trace.SpanID(traceParentContext.ParentSpanID),
+				TraceFlags: traceFlags,
+				Remote:     true,
+			}))
+		}
+	}
+
+	header := carrier.Get(sentry.SentryBaggageHeader)
+	if header == "" {
+		return ctx
+	}
+	if b, err := baggage.Parse(header); err == nil {
+		ctx = baggage.ContextWithBaggage(ctx, b)
+	}
+	if dsc, err := sentry.DynamicSamplingContextFromHeader([]byte(header)); err == nil {
+		ctx = context.WithValue(ctx, dynamicSamplingContextKey{}, dsc)
+	}
+	return ctx
+}
+
+func (sentryPropagator) Fields() []string {
+	return []string{sentry.SentryTraceHeader, sentry.SentryBaggageHeader}
+}
+
+// traceHeaders returns the sentry-trace and baggage headers that continue the
+// trace of the remote span context of ctx. They are the headers extracted by
+// the propagator, or are derived from the span context if it was extracted
+// by another propagator, without a dynamic sampling context.
+func traceHeaders(ctx context.Context, spanContext trace.SpanContext) (sentryTrace, sentryBaggage string) {
+	traceParentContext, ok := ctx.Value(sentryTraceContextKey{}).(sentry.TraceParentContext)
+	if !ok || traceParentContext.TraceID != sentry.TraceID(spanContext.TraceID()) ||
+		traceParentContext.ParentSpanID != sentry.SpanID(spanContext.SpanID()) {
+		traceParentContext = sentry.TraceParentContext{
+			TraceID:      sentry.TraceID(spanContext.TraceID()),
+			ParentSpanID: sentry.SpanID(spanContext.SpanID()),
+			Sampled:      sentry.SampledFalse,
+		}
+		if spanContext.IsSampled() {
+			traceParentContext.Sampled = sentry.SampledTrue
+		}
+		return formatSentryTrace(traceParentContext), ""
+	}
+
+	if dsc, ok := ctx.Value(dynamicSamplingContextKey{}).(sentry.DynamicSamplingContext); ok {
+		sentryBaggage = dsc.String()
+	}
+	return formatSentryTrace(traceParentContext), sentryBaggage
+}
+
+// formatSentryTrace formats a sentry-trace header, as Span.ToSentryTrace.
+func formatSentryTrace(t sentry.TraceParentContext) string {
+	sentryTrace := t.TraceID.String() + "-" + t.ParentSpanID.String()
+	switch t.Sampled {
+	case sentry.SampledTrue:
+		sentryTrace += "-1"
+	case sentry.SampledFalse:
+		sentryTrace += "-0"
+	}
+	return sentryTrace
+}
@@ -0,0 +1,119 @@
+package sentryotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testSentryTrace = "d4cda95b652f4a1592b449d5929fda1b-6e0c63257de34c92-1"
+	testBaggage     = "sentry-trace_id=d4cda95b652f4a1592b449d5929fda1b,sentry-sample_rate=0.5,sentry-sample_rand=0.25,vendor=acme"
+)
+
+func TestSentryPropagator_Extract(t *testing.T) {
+	carrier := propagation.MapCarrier{
+		sentry.SentryTraceHeader:   testSentryTrace,
+		sentry.SentryBaggageHeader: testBaggage,
+	}
+	ctx := NewSentryPropagator().Extract(context.Background(), carrier)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	assert.True(t, spanContext.IsRemote())
+	assert.True(t, spanContext.IsSampled())
+	assert.Equal(t, "d4cda95b652f4a1592b449d5929fda1b", spanContext.TraceID().String())
+	assert.Equal(t, "6e0c63257de34c92", spanContext.SpanID().String())
+	assert.Equal(t, "acme", baggage.FromContext(ctx).Member("vendor").Value())
+
+	dsc, ok := ctx.Value(dynamicSamplingContextKey{}).(sentry.DynamicSamplingContext)
+	require.True(t, ok)
+	assert.Equal(t, "0.5", dsc.Entries["sample_rate"])
+}
+
+func TestSentryPropagator_ExtractNotSampled(t *testing.T) {
+	carrier := propagation.MapCarrier{sentry.SentryTraceHeader: "d4cda95b652f4a1592b449d5929fda1b-6e0c63257de34c92-0"}
+	ctx := NewSentryPropagator().Extract(context.Background(), carrier)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	assert.True(t, spanContext.IsValid())
+	assert.False(t, spanContext.IsSampled())
+}
+
+func TestSentryPropagator_ExtractInvalid(t *testing.T) {
+	carrier := propagation.MapCarrier{sentry.SentryTraceHeader: "invalid"}
+	ctx := NewSentryPropagator().Extract(context.Background(), carrier)
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestSentryPropagator_InjectRemote(t *testing.T) {
+	propagator := NewSentryPropagator()
+	ctx := propagator.Extract(context.Background(), propagation.MapCarrier{
+		sentry.SentryTraceHeader:   testSentryTrace,
+		sentry.SentryBaggageHeader: testBaggage,
+	})
+
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	assert.Equal(t, testSentryTrace, carrier.Get(sentry.SentryTraceHeader))
+	assert.ElementsMatch(t, []string{
+		"sentry-trace_id=d4cda95b652f4a1592b449d5929fda1b",
+		"sentry-sample_rate=0.5",
+		"sentry-sample_rand=0.25",
+		"vendor=acme",
+	}, splitBaggage(carrier.Get(sentry.SentryBaggageHeader)))
+}
+
+func TestSentryPropagator_InjectSpan(t *testing.T) {
+	ctx, tracer, _ := setupSpanProcessor(t, sentry.ClientOptions{TracesSampleRate: 1.0})
+	propagator := NewSentryPropagator()
+	ctx = propagator.Extract(ctx, propagation.MapCarrier{
+		sentry.SentryTraceHeader:   testSentryTrace,
+		sentry.SentryBaggageHeader: testBaggage,
+	})
+
+	ctx, span := tracer.Start(ctx, "handler", trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	transaction, ok := sentrySpans.Get(span.SpanContext().SpanID())
+	require.True(t, ok)
+	assert.Equal(t, "6e0c63257de34c92", transaction.ParentSpanID.String())
+	assert.Equal(t, sentry.SampledTrue, transaction.Sampled)
+
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	assert.Equal(t, "d4cda95b652f4a1592b449d5929fda1b-"+span.SpanContext().SpanID().String()+"-1", carrier.Get(sentry.SentryTraceHeader))
+	// The dynamic sampling context of the incoming trace is frozen.
+	assert.ElementsMatch(t, []string{
+		"sentry-trace_id=d4cda95b652f4a1592b449d5929fda1b",
+		"sentry-sample_rate=0.5",
+		"sentry-sample_rand=0.25",
+		"vendor=acme",
+	}, splitBaggage(carrier.Get(sentry.SentryBaggageHeader)))
+}
+
+func TestSentryPropagator_InjectWithoutSpan(t *testing.T) {
+	carrier := propagation.MapCarrier{}
+	NewSentryPropagator().Inject(context.Background(), carrier)
+	assert.Empty(t, carrier.Keys())
+}
+
+func TestSentryPropagator_Fields(t *testing.T) {
+	assert.Equal(t, []string{sentry.SentryTraceHeader, sentry.SentryBaggageHeader}, NewSentryPropagator().Fields())
+}
+
+func splitBaggage(header string) []string {
+	b, err := baggage.Parse(header)
+	if err != nil {
+		return nil
+	}
+	members := make([]string, 0, b.Len())
+	for _, member := range b.Members() {
+		members = append(members, member.String())
+	}
+	return members
+}
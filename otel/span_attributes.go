@@ -0,0 +1,167 @@
+package sentryotel
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The semantic convention attributes used to describe the Sentry spans, in the
+// current and in the deprecated form when they were renamed.
+const (
+	attrHTTPMethod        = "http.request.method"
+	attrHTTPMethodOld     = "http.method"
+	attrHTTPRoute         = "http.route"
+	attrHTTPStatusCode    = "http.response.status_code"
+	attrHTTPStatusCodeOld = "http.status_code"
+	attrURLFull           = "url.full"
+	attrURLFullOld        = "http.url"
+	attrURLPath           = "url.path"
+	attrURLPathOld        = "http.target"
+	attrDBSystem          = "db.system.name"
+	attrDBSystemOld       = "db.system"
+	attrDBQueryText       = "db.query.text"
+	attrDBQueryTextOld    = "db.statement"
+	attrRPCSystem         = "rpc.system"
+	attrRPCGRPCStatusCode = "rpc.grpc.status_code"
+	attrMessagingSystem   = "messaging.system"
+	attrFaaSTrigger       = "faas.trigger"
+)
+
+// defaultSpanName is the description of the spans without a name.
+const defaultSpanName = "<unlabeled span>"
+
+// maxGRPCStatusCode is the last gRPC status code, Unauthenticated.
+const maxGRPCStatusCode = 16
+
+// spanAttributes are the Sentry properties derived from the attributes of an
+// OpenTelemetry span.
+type spanAttributes struct {
+	Op          string
+	Description string
+	Source      sentry.TransactionSource
+}
+
+// parseSpanAttributes derives the op, description and transaction source of
+// a span from its semantic convention attributes. Spans without known
+// attributes keep their name as description.
+func parseSpanAttributes(s sdktrace.ReadOnlySpan) spanAttributes {
+	attrs := make(map[attribute.Key]attribute.Value, len(s.Attributes()))
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	get := func(keys ...attribute.Key) string {
+		for _, key := range keys {
+			if value, ok := attrs[key]; ok {
+				return value.Emit()
+			}
+		}
+		return ""
+	}
+
+	name := s.Name()
+	if name == "" {
+		name = defaultSpanName
+	}
+	if method := get(attrHTTPMethod, attrHTTPMethodOld); method != "" {
+		return parseHTTPAttributes(s.SpanKind(), method, get)
+	}
+	if get(attrDBSystem, attrDBSystemOld) != "" {
+		description := get(attrDBQueryText, attrDBQueryTextOld)
+		if description == "" {
+			description = name
+		}
+		return spanAttributes{Op: "db", Description: description, Source: sentry.SourceTask}
+	}
+	if get(attrRPCSystem) != "" {
+		return spanAttributes{Op: "rpc", Description: name, Source: sentry.SourceRoute}
+	}
+	if get(attrMessagingSystem) != "" {
+		return spanAttributes{Op: "message", Description: name, Source: sentry.SourceRoute}
+	}
+	if trigger := get(attrFaaSTrigger); trigger != "" {
+		return spanAttributes{Op: trigger, Description: name, Source: sentry.SourceRoute}
+	}
+	return spanAttributes{Description: name, Source: sentry.SourceCustom}
+}
+
+// parseHTTPAttributes describes an HTTP span as "METHOD route", or
+// "METHOD path" if the route is unknown.
+func parseHTTPAttributes(kind trace.SpanKind, method string, get func(keys ...attribute.Key) string) spanAttributes {
+	op := "http"
+	switch kind {
+	case trace.SpanKindServer:
+		op = "http.server"
+	case trace.SpanKindClient:
+		op = "http.client"
+	}
+
+	if route := get(attrHTTPRoute); route != "" {
+		return spanAttributes{Op: op, Description: method + " " + route, Source: sentry.SourceRoute}
+	}
+	path := get(attrURLPath, attrURLPathOld)
+	if rawURL := get(attrURLFull, attrURLFullOld); rawURL != "" {
+		if u, err := url.Parse(rawURL); err == nil {
+			// The query and fragment can hold sensitive data.
+			u.RawQuery, u.Fragment = "", ""
+			path = u.String()
+		}
+	}
+	if path == "" {
+		return spanAttributes{Op: op, Description: method, Source: sentry.SourceCustom}
+	}
+	path, _, _ = strings.Cut(path, "?")
+	return spanAttributes{Op: op, Description: method + " " + path, Source: sentry.SourceURL}
+}
+
+// spanStatus returns the Sentry status of a span, from its HTTP or gRPC
+// status code if known, or else from its OpenTelemetry status.
+func spanStatus(s sdktrace.ReadOnlySpan) sentry.SpanStatus {
+	for _, kv := range s.Attributes() {
+		switch kv.Key {
+		case attrHTTPStatusCode, attrHTTPStatusCodeOld:
+			if kv.Value.Type() == attribute.INT64 {
+				return sentry.HTTPtoSpanStatus(int(kv.Value.AsInt64()))
+			}
+		case attrRPCGRPCStatusCode:
+			if code := kv.Value.AsInt64(); kv.Value.Type() == attribute.INT64 && code >= 0 && code <= maxGRPCStatusCode {
+				// The Sentry span statuses follow the gRPC status codes,
+				// after SpanStatusUndefined.
+				return sentry.SpanStatus(code + 1)
+			}
+		}
+	}
+	if s.Status().Code == codes.Error {
+		return sentry.SpanStatusUnknown
+	}
+	return sentry.SpanStatusOK
+}
+
+// isSentryRequest reports whether a span traces a request of the Sentry
+// transport of client, to the host of its DSN.
+func isSentryRequest(client *sentry.Client, s sdktrace.ReadWriteSpan) bool {
+	if client == nil || client.Options().Dsn == "" || s.SpanKind() != trace.SpanKindClient {
+		return false
+	}
+	dsn, err := sentry.NewDsn(client.Options().Dsn)
+	if err != nil {
+		return false
+	}
+	for _, kv := range s.Attributes() {
+		if kv.Key != attrURLFull && kv.Key != attrURLFullOld {
+			continue
+		}
+		u, err := url.Parse(kv.Value.Emit())
+		if err != nil {
+			return false
+		}
+		apiURL := dsn.GetAPIURL()
+		return u.Host == apiURL.Host && strings.HasPrefix(u.Path, strings.TrimSuffix(apiURL.Path, "envelope/"))
+	}
+	return false
+}
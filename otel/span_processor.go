@@ -0,0 +1,204 @@
+package sentryotel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanOriginOtel is the origin of the Sentry spans converted from
+// OpenTelemetry spans.
+const SpanOriginOtel sentry.SpanOrigin = "auto.otel"
+
+// flushTimeout is the timeout of Shutdown and ForceFlush when the context has
+// no deadline.
+const flushTimeout = 2 * time.Second
+
+// spanMap holds the Sentry spans of the OpenTelemetry spans that have not
+// ended yet. It is shared by the span processor, which creates the Sentry
+// spans, and the propagator, which reads their trace headers.
+type spanMap struct {
+	mu    sync.RWMutex
+	spans map[trace.SpanID]*sentry.Span
+}
+
+func (m *spanMap) Get(spanID trace.SpanID) (*sentry.Span, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	span, ok := m.spans[spanID]
+	return span, ok
+}
+
+func (m *spanMap) Set(spanID trace.SpanID, span *sentry.Span) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spans[spanID] = span
+}
+
+func (m *spanMap) Delete(spanID trace.SpanID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.spans, spanID)
+}
+
+func (m *spanMap) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spans = make(map[trace.SpanID]*sentry.Span)
+}
+
+func (m *spanMap) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.spans)
+}
+
+var sentrySpans = &spanMap{spans: make(map[trace.SpanID]*sentry.Span)}
+
+type sentrySpanProcessor struct{}
+
+// NewSentrySpanProcessor returns an OpenTelemetry span processor that converts
+// the spans of the tracer provider into Sentry spans. Local root spans and
+// spans with a remote parent become transactions, the other spans become
+// children of the transaction of their parent. The transactions are sent
+// through the client of the hub found in the context of the span, or the
+// current hub, when their root span ends.
+//
+// The sampling of the transactions follows ClientOptions.TracesSampleRate
+// and ClientOptions.TracesSampler, or the sampling decision of the incoming
+// trace. Use it together with NewSentryPropagator to continue and propagate
+// Sentry traces:
+//
+//	tp := sdktrace.NewTracerProvider(
+//		sdktrace.WithSpanProcessor(sentryotel.NewSentrySpanProcessor()),
+//	)
+//	otel.SetTracerProvider(tp)
+//	otel.SetTextMapPropagator(sentryotel.NewSentryPropagator())
+func NewSentrySpanProcessor() sdktrace.SpanProcessor {
+	return sentrySpanProcessor{}
+}
+
+func (sentrySpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	hub := sentry.GetHubFromContext(parent)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	if isSentryRequest(hub.Client(), s) {
+		// Requests of the Sentry transport traced by an instrumented HTTP
+		// client are not converted, they would trace themselves forever.
+		return
+	}
+
+	spanContext := s.SpanContext()
+	parentSpanContext := s.Parent()
+	setIDs := func(span *sentry.Span) {
+		span.TraceID = sentry.TraceID(spanContext.TraceID())
+		span.SpanID = sentry.SpanID(spanContext.SpanID())
+		span.StartTime = s.StartTime()
+	}
+
+	if parentSpan, ok := sentrySpans.Get(parentSpanContext.SpanID()); ok {
+		span := parentSpan.StartChild(s.Name(), setIDs, sentry.WithDescription(s.Name()))
+		sentrySpans.Set(spanContext.SpanID(), span)
+		return
+	}
+
+	// The transaction starts from a new context, so that it does not become a
+	// child of a Sentry span of the parent context.
+	ctx := sentry.SetHubOnContext(context.Background(), hub)
+	options := []sentry.SpanOption{
+		sentry.WithSpanOrigin(SpanOriginOtel),
+		sentry.WithDescription(s.Name()),
+	}
+	if parentSpanContext.IsValid() {
+		sentryTrace, baggage := traceHeaders(parent, parentSpanContext)
+		options = append(options, sentry.ContinueFromHeaders(sentryTrace, baggage))
+	}
+	// The IDs are set last, so that they are used to sample and profile the
+	// transaction.
+	options = append(options, setIDs)
+	transaction := sentry.StartTransaction(ctx, s.Name(), options...)
+	sentrySpans.Set(spanContext.SpanID(), transaction)
+}
+
+func (sentrySpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	spanID := s.SpanContext().SpanID()
+	span, ok := sentrySpans.Get(spanID)
+	if !ok {
+		return
+	}
+	sentrySpans.Delete(spanID)
+
+	updateSpan(span, s)
+	span.EndTime = s.EndTime()
+	span.Finish()
+}
+
+func (sentrySpanProcessor) Shutdown(ctx context.Context) error {
+	sentrySpans.Clear()
+	return flush(ctx)
+}
+
+func (sentrySpanProcessor) ForceFlush(ctx context.Context) error {
+	return flush(ctx)
+}
+
+// flush flushes the hub of ctx, or the current hub, until the deadline of
+// ctx.
+func flush(ctx context.Context) error {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	if hub.Client() == nil {
+		return nil
+	}
+	timeout := flushTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	if !hub.Flush(timeout) {
+		return context.DeadlineExceeded
+	}
+	return ctx.Err()
+}
+
+// updateSpan sets the op, description, status and data of a Sentry span from
+// the attributes of its OpenTelemetry span.
+func updateSpan(span *sentry.Span, s sdktrace.ReadOnlySpan) {
+	attributes := parseSpanAttributes(s)
+	if attributes.Op != "" {
+		span.Op = attributes.Op
+	}
+	span.Description = attributes.Description
+	span.Status = spanStatus(s)
+
+	data := make(map[string]interface{}, len(s.Attributes()))
+	for _, kv := range s.Attributes() {
+		value := kv.Value.AsInterface()
+		data[string(kv.Key)] = value
+		span.SetData(string(kv.Key), value)
+	}
+	span.SetData("otel.kind", s.SpanKind().String())
+
+	if !span.IsTransaction() {
+		return
+	}
+	span.Name = attributes.Description
+	span.Source = attributes.Source
+
+	resource := make(map[string]interface{})
+	if r := s.Resource(); r != nil {
+		for _, kv := range r.Attributes() {
+			resource[string(kv.Key)] = kv.Value.AsInterface()
+		}
+	}
+	span.SetContext("otel", sentry.Context{
+		"attributes": data,
+		"resource":   resource,
+	})
+}
@@ -0,0 +1,212 @@
+package sentryotel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupSpanProcessor(t *testing.T, options sentry.ClientOptions) (context.Context, trace.Tracer, *sentry.MockTransport) {
+	t.Helper()
+	transport := &sentry.MockTransport{}
+	options.Transport = transport
+	options.EnableTracing = true
+	client, err := sentry.NewClient(options)
+	require.NoError(t, err)
+	ctx := sentry.SetHubOnContext(context.Background(), sentry.NewHub(client, sentry.NewScope()))
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(NewSentrySpanProcessor()),
+		sdktrace.WithResource(sdkresource.NewSchemaless(attribute.String("service.name", "users"))),
+	)
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return ctx, tp.Tracer("test"), transport
+}
+
+func TestSpanProcessor_Transaction(t *testing.T) {
+	ctx, tracer, transport := setupSpanProcessor(t, sentry.ClientOptions{TracesSampleRate: 1.0})
+
+	ctx, root := tracer.Start(ctx, "users", trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+		attribute.String("http.request.method", "GET"),
+		attribute.String("http.route", "/users/{id}"),
+	))
+	_, query := tracer.Start(ctx, "query", trace.WithAttributes(
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.query.text", "SELECT * FROM users WHERE id = $1"),
+	))
+	query.End()
+	root.SetAttributes(attribute.Int("http.response.status_code", 404))
+	root.End()
+
+	require.Len(t, transport.Events(), 1)
+	event := transport.Events()[0]
+	assert.Equal(t, "transaction", event.Type)
+	assert.Equal(t, "GET /users/{id}", event.Transaction)
+	assert.Equal(t, sentry.SourceRoute, event.TransactionInfo.Source)
+
+	rootContext := root.SpanContext()
+	traceContext := event.Contexts["trace"]
+	assert.Equal(t, sentry.TraceID(rootContext.TraceID()), traceContext["trace_id"])
+	assert.Equal(t, sentry.SpanID(rootContext.SpanID()), traceContext["span_id"])
+	assert.Equal(t, "http.server", traceContext["op"])
+	assert.Equal(t, sentry.SpanStatusNotFound, traceContext["status"])
+	assert.Equal(t, "/users/{id}", traceContext["data"].(map[string]interface{})["http.route"])
+	assert.Equal(t, map[string]interface{}{"service.name": "users"}, event.Contexts["otel"]["resource"])
+
+	require.Len(t, event.Spans, 1)
+	span := event.Spans[0]
+	queryContext := query.SpanContext()
+	assert.Equal(t, sentry.TraceID(queryContext.TraceID()), span.TraceID)
+	assert.Equal(t, sentry.SpanID(queryContext.SpanID()), span.SpanID)
+	assert.Equal(t, sentry.SpanID(rootContext.SpanID()), span.ParentSpanID)
+	assert.Equal(t, "db", span.Op)
+	assert.Equal(t, "SELECT * FROM users WHERE id = $1", span.Description)
+	assert.Equal(t, sentry.SpanStatusOK, span.Status)
+	assert.Equal(t, SpanOriginOtel, span.Origin)
+	assert.Equal(t, "postgresql", span.Data["db.system.name"])
+	assert.Equal(t, "internal", span.Data["otel.kind"])
+	assert.Zero(t, sentrySpans.Len())
+}
+
+func TestSpanProcessor_Timestamps(t *testing.T) {
+	ctx, tracer, transport := setupSpanProcessor(t, sentry.ClientOptions{TracesSampleRate: 1.0})
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, root := tracer.Start(ctx, "job", trace.WithTimestamp(start))
+	root.End(trace.WithTimestamp(start.Add(time.Second)))
+
+	require.Len(t, transport.Events(), 1)
+	event := transport.Events()[0]
+	assert.Equal(t, "job", event.Transaction)
+	assert.Equal(t, sentry.SourceCustom, event.TransactionInfo.Source)
+	assert.True(t, start.Equal(event.StartTime))
+	assert.True(t, start.Add(time.Second).Equal(event.Timestamp))
+}
+
+func TestSpanProcessor_NotSampled(t *testing.T) {
+	ctx, tracer, transport := setupSpanProcessor(t, sentry.ClientOptions{TracesSampleRate: 0.0})
+
+	ctx, root := tracer.Start(ctx, "root")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End()
+
+	assert.Empty(t, transport.Events())
+	assert.Zero(t, sentrySpans.Len())
+}
+
+func TestSpanProcessor_IgnoresSentryRequests(t *testing.T) {
+	ctx, tracer, transport := setupSpanProcessor(t, sentry.ClientOptions{
+		Dsn:              "https://public@o1.ingest.sentry.io/1",
+		TracesSampleRate: 1.0,
+	})
+
+	_, span := tracer.Start(ctx, "POST", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.request.method", "POST"),
+		attribute.String("url.full", "https://o1.ingest.sentry.io/api/1/envelope/"),
+	))
+	span.End()
+
+	assert.Empty(t, transport.Events())
+	assert.Zero(t, sentrySpans.Len())
+}
+
+func TestParseSpanAttributes(t *testing.T) {
+	tests := map[string]struct {
+		span tracetest.SpanStub
+		want spanAttributes
+	}{
+		"http server": {
+			span: tracetest.SpanStub{Name: "handler", SpanKind: trace.SpanKindServer, Attributes: []attribute.KeyValue{
+				attribute.String("http.method", "POST"),
+				attribute.String("http.target", "/users?page=2"),
+			}},
+			want: spanAttributes{Op: "http.server", Description: "POST /users", Source: sentry.SourceURL},
+		},
+		"http client": {
+			span: tracetest.SpanStub{Name: "GET", SpanKind: trace.SpanKindClient, Attributes: []attribute.KeyValue{
+				attribute.String("http.request.method", "GET"),
+				attribute.String("url.full", "https://example.com/users?token=secret#top"),
+			}},
+			want: spanAttributes{Op: "http.client", Description: "GET https://example.com/users", Source: sentry.SourceURL},
+		},
+		"db without query": {
+			span: tracetest.SpanStub{Name: "connect", Attributes: []attribute.KeyValue{
+				attribute.String("db.system", "redis"),
+			}},
+			want: spanAttributes{Op: "db", Description: "connect", Source: sentry.SourceTask},
+		},
+		"rpc": {
+			span: tracetest.SpanStub{Name: "users.Users/Get", Attributes: []attribute.KeyValue{
+				attribute.String("rpc.system", "grpc"),
+			}},
+			want: spanAttributes{Op: "rpc", Description: "users.Users/Get", Source: sentry.SourceRoute},
+		},
+		"messaging": {
+			span: tracetest.SpanStub{Name: "users publish", Attributes: []attribute.KeyValue{
+				attribute.String("messaging.system", "kafka"),
+			}},
+			want: spanAttributes{Op: "message", Description: "users publish", Source: sentry.SourceRoute},
+		},
+		"faas": {
+			span: tracetest.SpanStub{Name: "cleanup", Attributes: []attribute.KeyValue{
+				attribute.String("faas.trigger", "timer"),
+			}},
+			want: spanAttributes{Op: "timer", Description: "cleanup", Source: sentry.SourceRoute},
+		},
+		"unknown": {
+			span: tracetest.SpanStub{Name: "compute"},
+			want: spanAttributes{Description: "compute", Source: sentry.SourceCustom},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseSpanAttributes(tt.span.Snapshot()))
+		})
+	}
+}
+
+func TestSpanStatus(t *testing.T) {
+	tests := map[string]struct {
+		span tracetest.SpanStub
+		want sentry.SpanStatus
+	}{
+		"unset": {
+			want: sentry.SpanStatusOK,
+		},
+		"error": {
+			span: tracetest.SpanStub{Status: sdktrace.Status{Code: codes.Error}},
+			want: sentry.SpanStatusUnknown,
+		},
+		"http status code": {
+			span: tracetest.SpanStub{
+				Status:     sdktrace.Status{Code: codes.Error},
+				Attributes: []attribute.KeyValue{attribute.Int("http.response.status_code", 503)},
+			},
+			want: sentry.SpanStatusUnavailable,
+		},
+		"grpc status code": {
+			span: tracetest.SpanStub{Attributes: []attribute.KeyValue{attribute.Int("rpc.grpc.status_code", 16)}},
+			want: sentry.SpanStatusUnauthenticated,
+		},
+		"invalid grpc status code": {
+			span: tracetest.SpanStub{Attributes: []attribute.KeyValue{attribute.Int("rpc.grpc.status_code", 17)}},
+			want: sentry.SpanStatusOK,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, spanStatus(tt.span.Snapshot()))
+		})
+	}
+}
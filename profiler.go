@@ -0,0 +1,412 @@
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/profile"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+)
+
+// defaultProfileChunkDuration is the default of
+// ClientOptions.ProfileChunkDuration.
+const defaultProfileChunkDuration = 60 * time.Second
+
+// pprof labels set on the goroutine that starts a sampled transaction, and
+// inherited by the goroutines it starts, to link CPU samples to the
+// transaction.
+const (
+	profileLabelTraceID = "sentry.trace_id"
+	profileLabelSpanID  = "sentry.span_id"
+)
+
+// profileUnattributedThreadID is the thread of the samples that are not
+// linked to a transaction. The samples of a transaction are put on a thread
+// of their own, identified by the span ID of the transaction.
+const profileUnattributedThreadID = "0"
+
+// A profiler continuously samples the CPU usage of the program with
+// runtime/pprof and sends the samples in profile chunks. See
+// ClientOptions.ContinuousProfiling.
+type profiler struct {
+	client        *Client
+	id            string
+	chunkDuration time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu sync.Mutex
+	// transactions are the sampled transactions started while the profiler
+	// runs, by span ID. They are removed once no longer part of a chunk.
+	transactions map[string]*profiledTransaction
+}
+
+// A profiledTransaction is a transaction whose goroutine is labeled for the
+// profiler.
+type profiledTransaction struct {
+	traceID TraceID
+	name    string
+	start   time.Time
+	// end is zero while the transaction runs.
+	end time.Time
+}
+
+func newProfiler(client *Client) *profiler {
+	chunkDuration := client.options.ProfileChunkDuration
+	if chunkDuration <= 0 {
+		chunkDuration = defaultProfileChunkDuration
+	}
+	return &profiler{
+		client:        client,
+		id:            uuid(),
+		chunkDuration: chunkDuration,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		transactions:  make(map[string]*profiledTransaction),
+	}
+}
+
+// Start starts profiling in the background.
+func (p *profiler) Start() {
+	go p.run()
+}
+
+// Stop stops profiling and sends the last chunk.
+func (p *profiler) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	<-p.done
+}
+
+func (p *profiler) run() {
+	defer close(p.done)
+
+	for {
+		var buf bytes.Buffer
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			// Only one CPU profile can run at a time, for example the
+			// program may be profiled with the net/http/pprof handlers.
+			debuglog.Printf("Profiler: cannot start CPU profile, skipping chunk: %v", err)
+			select {
+			case <-p.stop:
+				return
+			case <-time.After(p.chunkDuration):
+				continue
+			}
+		}
+
+		var stopped bool
+		timer := time.NewTimer(p.chunkDuration)
+		select {
+		case <-timer.C:
+		case <-p.stop:
+			timer.Stop()
+			stopped = true
+		}
+		pprof.StopCPUProfile()
+
+		p.sendChunk(buf.Bytes())
+		if stopped {
+			return
+		}
+	}
+}
+
+// sendChunk converts a CPU profile to a profile chunk and sends it.
+func (p *profiler) sendChunk(data []byte) {
+	prof, err := profile.Parse(data)
+	if err != nil {
+		debuglog.Printf("Profiler: cannot parse CPU profile: %v", err)
+		return
+	}
+	chunk := p.newChunk(prof)
+	p.removeFinishedTransactions(prof.Start.Add(prof.Duration))
+	if chunk == nil {
+		return
+	}
+	if !p.client.telemetryProcessor.Add(chunk) {
+		debuglog.Println("Dropping profile chunk: telemetry buffer full or category missing")
+	}
+}
+
+// startTransaction registers a sampled transaction, whose goroutine is
+// labeled, so that its CPU samples are linked to the transaction.
+func (p *profiler) startTransaction(s *Span) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.transactions[s.SpanID.String()] = &profiledTransaction{
+		traceID: s.TraceID,
+		name:    s.Name,
+		start:   s.StartTime,
+	}
+}
+
+// finishTransaction records the end of a transaction.
+func (p *profiler) finishTransaction(s *Span) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if t, ok := p.transactions[s.SpanID.String()]; ok {
+		t.name = s.Name
+		t.end = s.EndTime
+	}
+}
+
+// removeFinishedTransactions forgets the transactions that finished before
+// the given time.
+func (p *profiler) removeFinishedTransactions(before time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for spanID, t := range p.transactions {
+		if !t.end.IsZero() && t.end.Before(before) {
+			delete(p.transactions, spanID)
+		}
+	}
+}
+
+// newChunk converts a CPU profile to a profile chunk. It returns nil if the
+// profile has no samples.
+//
+// pprof aggregates the samples of identical stacks, so the time of each sample
+// is not known. The samples of a transaction are spread evenly over the time
+// the transaction ran, and the other samples over the duration of the
+// profile.
+func (p *profiler) newChunk(prof *profile.Profile) *profileChunk {
+	start, end := prof.Start, prof.Start.Add(prof.Duration)
+
+	p.mu.Lock()
+	transactions := make(map[string]profiledTransaction, len(p.transactions))
+	for spanID, t := range p.transactions {
+		transactions[spanID] = *t
+	}
+	p.mu.Unlock()
+
+	b := newProfileBuilder()
+	for _, sample := range prof.Samples {
+		if len(sample.Values) == 0 || sample.Values[0] <= 0 {
+			continue
+		}
+		threadID, threadStart, threadEnd := profileUnattributedThreadID, start, end
+		if t, ok := transactions[sample.Labels[profileLabelSpanID]]; ok {
+			tStart, tEnd := t.start, t.end
+			if tEnd.IsZero() || tEnd.After(end) {
+				tEnd = end
+			}
+			if tStart.Before(start) {
+				tStart = start
+			}
+			if tStart.Before(tEnd) {
+				threadID, threadStart, threadEnd = sample.Labels[profileLabelSpanID], tStart, tEnd
+				b.setThreadName(threadID, t.name)
+			}
+		}
+		b.addSample(threadID, threadStart, threadEnd, sample.Stack, sample.Values[0])
+	}
+	if b.empty() {
+		return nil
+	}
+
+	options := p.client.options
+	return &profileChunk{
+		ChunkID:     uuid(),
+		ProfilerID:  p.id,
+		Platform:    "go",
+		Version:     "2",
+		Release:     options.Release,
+		Environment: options.Environment,
+		ClientSDK: profileClientSDK{
+			Name:    p.client.sdkIdentifier,
+			Version: p.client.sdkVersion,
+		},
+		Profile: b.build(),
+	}
+}
+
+// profileStacks deduplicates the frames and stacks of profile samples.
+type profileStacks struct {
+	frames     []Frame
+	frameIndex map[profile.Frame]int
+	stacks     [][]int
+	stackIndex map[string]int
+}
+
+func newProfileStacks() *profileStacks {
+	return &profileStacks{
+		frameIndex: make(map[profile.Frame]int),
+		stackIndex: make(map[string]int),
+	}
+}
+
+// stackID returns the index of a stack, the innermost frame first.
+func (ps *profileStacks) stackID(stack []profile.Frame) int {
+	ids := make([]int, len(stack))
+	key := make([]byte, 0, len(stack)*4)
+	for i, f := range stack {
+		id, ok := ps.frameIndex[f]
+		if !ok {
+			id = len(ps.frames)
+			ps.frameIndex[f] = id
+			pkg, function := splitQualifiedFunctionName(f.Function)
+			ps.frames = append(ps.frames, newFrame(pkg, function, f.File, f.Line))
+		}
+		ids[i] = id
+		key = append(key, byte(id>>24), byte(id>>16), byte(id>>8), byte(id))
+	}
+	id, ok := ps.stackIndex[string(key)]
+	if !ok {
+		id = len(ps.stacks)
+		ps.stackIndex[string(key)] = id
+		ps.stacks = append(ps.stacks, ids)
+	}
+	return id
+}
+
+// A profileBuilder collects the samples of a profile chunk.
+type profileBuilder struct {
+	*profileStacks
+	threads     map[string]*profileThreadSamples
+	threadNames map[string]string
+}
+
+// profileThreadSamples are the samples of a thread, before they get their
+// timestamps.
+type profileThreadSamples struct {
+	start, end time.Time
+	stackIDs   []int
+}
+
+func newProfileBuilder() *profileBuilder {
+	return &profileBuilder{
+		profileStacks: newProfileStacks(),
+		threads:       make(map[string]*profileThreadSamples),
+		threadNames:   make(map[string]string),
+	}
+}
+
+func (b *profileBuilder) empty() bool {
+	return len(b.threads) == 0
+}
+
+func (b *profileBuilder) setThreadName(threadID, name string) {
+	b.threadNames[threadID] = name
+}
+
+// addSample adds count samples of a stack, taken between start and end on
+// the given thread.
+func (b *profileBuilder) addSample(threadID string, start, end time.Time, stack []profile.Frame, count int64) {
+	stackID := b.stackID(stack)
+	thread, ok := b.threads[threadID]
+	if !ok {
+		thread = &profileThreadSamples{start: start, end: end}
+		b.threads[threadID] = thread
+	}
+	for range count {
+		thread.stackIDs = append(thread.stackIDs, stackID)
+	}
+}
+
+// build spreads the samples of each thread evenly over the time the thread
+// was sampled, and returns the profile sorted by time.
+func (b *profileBuilder) build() profileData {
+	data := profileData{
+		Frames:         b.frames,
+		Stacks:         b.stacks,
+		ThreadMetadata: make(map[string]profileThread, len(b.threads)),
+	}
+	for threadID, thread := range b.threads {
+		data.ThreadMetadata[threadID] = profileThread{Name: b.threadNames[threadID]}
+		interval := thread.end.Sub(thread.start) / time.Duration(len(thread.stackIDs))
+		for i, stackID := range thread.stackIDs {
+			timestamp := thread.start.Add(interval*time.Duration(i) + interval/2)
+			data.Samples = append(data.Samples, profileSample{
+				Timestamp: float64(timestamp.UnixNano()) / 1e9,
+				ThreadID:  threadID,
+				StackID:   stackID,
+			})
+		}
+	}
+	sort.SliceStable(data.Samples, func(i, j int) bool {
+		return data.Samples[i].Timestamp < data.Samples[j].Timestamp
+	})
+	return data
+}
+
+// profileChunk is a chunk of a continuous profile, in the format of the
+// profile_chunk envelope item.
+type profileChunk struct {
+	ChunkID     string           `json:"chunk_id"`
+	ProfilerID  string           `json:"profiler_id"`
+	Platform    string           `json:"platform"`
+	Version     string           `json:"version"`
+	Release     string           `json:"release,omitempty"`
+	Environment string           `json:"environment,omitempty"`
+	ClientSDK   profileClientSDK `json:"client_sdk"`
+	Profile     profileData      `json:"profile"`
+}
+
+type profileClientSDK struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// profileData is the sampled stacks of a profile.
+type profileData struct {
+	Samples        []profileSample          `json:"samples"`
+	Stacks         [][]int                  `json:"stacks"`
+	Frames         []Frame                  `json:"frames"`
+	ThreadMetadata map[string]profileThread `json:"thread_metadata"`
+}
+
+type profileSample struct {
+	// Timestamp is the time of the sample in seconds since the Unix epoch.
+	Timestamp float64 `json:"timestamp"`
+	ThreadID  string  `json:"thread_id"`
+	StackID   int     `json:"stack_id"`
+}
+
+type profileThread struct {
+	Name string `json:"name,omitempty"`
+}
+
+// MakeSerializationSafe is a no-op, profile chunks are not shared.
+func (c *profileChunk) MakeSerializationSafe() {}
+
+// GetCategory returns the rate limit category for profile chunks.
+func (c *profileChunk) GetCategory() ratelimit.Category {
+	return ratelimit.CategoryProfileChunk
+}
+
+// GetEventID returns the ID of the chunk.
+func (c *profileChunk) GetEventID() string {
+	return c.ChunkID
+}
+
+// GetSdkInfo returns nil, so the SDK info of the client is used.
+func (c *profileChunk) GetSdkInfo() *protocol.SdkInfo {
+	return nil
+}
+
+// GetDynamicSamplingContext returns nil, chunks are not part of a trace.
+func (c *profileChunk) GetDynamicSamplingContext() map[string]string {
+	return nil
+}
+
+// ToEnvelope converts the chunk to an envelope with a profile_chunk item.
+func (c *profileChunk) ToEnvelope(header *protocol.EnvelopeHeader) (*protocol.Envelope, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.NewEnvelope(header, protocol.NewProfileChunkItem(c.Platform, payload)), nil
+}
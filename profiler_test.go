@@ -0,0 +1,182 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProfiler(t *testing.T) *profiler {
+	t.Helper()
+	client, err := NewClient(ClientOptions{
+		Dsn:               "https://public@example.com/1",
+		EnvelopeTransport: &recordingEnvelopeTransport{},
+		Release:           "1.0.0",
+	})
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	return newProfiler(client)
+}
+
+func TestProfiler_NewChunk(t *testing.T) {
+	p := newTestProfiler(t)
+
+	start := time.Unix(1700000000, 0)
+	p.transactions["b0e6f15b45c36b12"] = &profiledTransaction{
+		name:  "GET /users",
+		start: start.Add(4 * time.Second),
+		end:   start.Add(6 * time.Second),
+	}
+	handler := profile.Frame{Function: "example.com/app/users.(*Handler).ServeHTTP", File: "/src/app/users/handler.go", Line: 42}
+	query := profile.Frame{Function: "example.com/app/db.Query", File: "/src/app/db/query.go", Line: 7}
+	prof := &profile.Profile{
+		Start:    start,
+		Duration: 10 * time.Second,
+		Samples: []profile.Sample{
+			{Stack: []profile.Frame{query, handler}, Values: []int64{2, 20000000}, Labels: map[string]string{profileLabelSpanID: "b0e6f15b45c36b12"}},
+			{Stack: []profile.Frame{handler}, Values: []int64{2, 20000000}},
+			{Stack: []profile.Frame{query, handler}, Values: []int64{0, 0}},
+		},
+	}
+
+	chunk := p.newChunk(prof)
+	require.NotNil(t, chunk)
+	assert.Equal(t, p.id, chunk.ProfilerID)
+	assert.Equal(t, "go", chunk.Platform)
+	assert.Equal(t, "2", chunk.Version)
+	assert.Equal(t, "1.0.0", chunk.Release)
+
+	data := chunk.Profile
+	require.Len(t, data.Frames, 2)
+	assert.Equal(t, "Query", data.Frames[0].Function)
+	assert.Equal(t, "example.com/app/db", data.Frames[0].Module)
+	assert.Equal(t, "/src/app/db/query.go", data.Frames[0].AbsPath)
+	assert.Equal(t, 7, data.Frames[0].Lineno)
+	assert.True(t, data.Frames[0].InApp)
+	assert.Equal(t, [][]int{{0, 1}, {1}}, data.Stacks)
+	assert.Equal(t, map[string]profileThread{
+		"b0e6f15b45c36b12":          {Name: "GET /users"},
+		profileUnattributedThreadID: {},
+	}, data.ThreadMetadata)
+
+	// The samples of the transaction are spread over the time it ran, the
+	// other samples over the duration of the profile.
+	assert.Equal(t, []profileSample{
+		{Timestamp: 1700000002.5, ThreadID: profileUnattributedThreadID, StackID: 1},
+		{Timestamp: 1700000004.5, ThreadID: "b0e6f15b45c36b12", StackID: 0},
+		{Timestamp: 1700000005.5, ThreadID: "b0e6f15b45c36b12", StackID: 0},
+		{Timestamp: 1700000007.5, ThreadID: profileUnattributedThreadID, StackID: 1},
+	}, data.Samples)
+}
+
+func TestProfiler_NewChunkWithoutSamples(t *testing.T) {
+	p := newTestProfiler(t)
+	assert.Nil(t, p.newChunk(&profile.Profile{Start: time.Now(), Duration: time.Second}))
+}
+
+func TestProfiler_RemoveFinishedTransactions(t *testing.T) {
+	p := newTestProfiler(t)
+	now := time.Now()
+	p.transactions["finished"] = &profiledTransaction{start: now.Add(-2 * time.Second), end: now.Add(-time.Second)}
+	p.transactions["running"] = &profiledTransaction{start: now.Add(-2 * time.Second)}
+	p.transactions["finished later"] = &profiledTransaction{start: now.Add(-2 * time.Second), end: now.Add(time.Second)}
+
+	p.removeFinishedTransactions(now)
+	assert.Len(t, p.transactions, 2)
+	assert.NotContains(t, p.transactions, "finished")
+}
+
+// burnCPU keeps the CPU busy, so that it is sampled by the profiler.
+func burnCPU(d time.Duration) int {
+	var n int
+	for deadline := time.Now().Add(d); time.Now().Before(deadline); {
+		for i := 0; i < 100000; i++ {
+			n += i % 7
+		}
+	}
+	return n
+}
+
+func TestClient_ContinuousProfiling(t *testing.T) {
+	transport := &recordingEnvelopeTransport{}
+	client, err := NewClient(ClientOptions{
+		Dsn:                  "https://public@example.com/1",
+		EnvelopeTransport:    transport,
+		EnableTracing:        true,
+		TracesSampleRate:     1.0,
+		ContinuousProfiling:  true,
+		ProfileChunkDuration: time.Hour,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, client.profiler)
+
+	ctx := SetHubOnContext(context.Background(), NewHub(client, NewScope()))
+	transaction := StartTransaction(ctx, "profiled")
+	var labels []string
+	pprof.ForLabels(transaction.Context(), func(key, value string) bool {
+		labels = append(labels, key+"="+value)
+		return true
+	})
+	assert.ElementsMatch(t, []string{
+		profileLabelTraceID + "=" + transaction.TraceID.String(),
+		profileLabelSpanID + "=" + transaction.SpanID.String(),
+	}, labels)
+	burnCPU(300 * time.Millisecond)
+	transaction.Finish()
+
+	// Closing the client stops the profiler, which sends the last chunk.
+	client.Close()
+
+	var chunk profileChunk
+	var event struct {
+		Contexts map[string]map[string]interface{} `json:"contexts"`
+	}
+	transport.mu.Lock()
+	for _, envelope := range transport.envelopes {
+		for _, item := range envelope.Items {
+			switch item.Header.Type {
+			case EnvelopeItemTypeProfileChunk:
+				assert.Equal(t, "go", item.Header.Platform)
+				require.NoError(t, json.Unmarshal(item.Payload, &chunk))
+			case EnvelopeItemTypeTransaction:
+				require.NoError(t, json.Unmarshal(item.Payload, &event))
+			}
+		}
+	}
+	transport.mu.Unlock()
+
+	require.NotEmpty(t, chunk.Profile.Samples, "no profile chunk sent")
+	assert.Equal(t, client.profiler.id, chunk.ProfilerID)
+	assert.Equal(t, client.profiler.id, event.Contexts["profile"]["profiler_id"])
+	spanID := transaction.SpanID.String()
+	assert.Equal(t, spanID, event.Contexts["trace"]["data"].(map[string]interface{})["thread.id"])
+	assert.Equal(t, "profiled", chunk.Profile.ThreadMetadata[spanID].Name)
+
+	var inTransaction int
+	start := float64(transaction.StartTime.UnixNano()) / 1e9
+	end := float64(transaction.EndTime.UnixNano()) / 1e9
+	for _, sample := range chunk.Profile.Samples {
+		if sample.ThreadID == spanID {
+			inTransaction++
+			assert.GreaterOrEqual(t, sample.Timestamp, start)
+			assert.LessOrEqual(t, sample.Timestamp, end)
+		}
+	}
+	assert.Positive(t, inTransaction, "no samples linked to the transaction")
+}
+
+func TestClient_ContinuousProfilingRequiresTelemetryBuffer(t *testing.T) {
+	client, err := NewClient(ClientOptions{
+		Transport:           &MockTransport{},
+		ContinuousProfiling: true,
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	assert.Nil(t, client.profiler)
+}
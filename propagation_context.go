@@ -9,6 +9,16 @@ type PropagationContext struct {
 	SpanID                 SpanID                 `json:"span_id"`
 	ParentSpanID           SpanID                 `json:"parent_span_id,omitzero"`
 	DynamicSamplingContext DynamicSamplingContext `json:"-"`
+	// SampleRand is the random value in [0, 1) that the sampling decisions
+	// of the trace are based on. It is propagated as sample_rand in baggage.
+	SampleRand float64 `json:"-"`
+	// Sampled is the sampling decision of the continued trace, as received in
+	// its trace header. It is SampledUndefined for a new trace, or if the
+	// decision was deferred.
+	Sampled Sampled `json:"-"`
+	// Tracestate is the W3C tracestate header received with the continued
+	// trace, propagated downstream along with the traceparent header.
+	Tracestate string `json:"-"`
 }
 
 func (p PropagationContext) Map() map[string]interface{} {
@@ -35,6 +45,8 @@ func NewPropagationContext() PropagationContext {
 		panic(err)
 	}
 
+	p.SampleRand = newSampleRand(SampledUndefined, 0, false)
+
 	return p
 }
 
@@ -46,11 +58,14 @@ func PropagationContextFromHeaders(trace, baggage string) (PropagationContext, e
 	}
 
 	hasTrace := false
+	parentSampled := SampledUndefined
 	if trace != "" {
 		if tpc, valid := ParseTraceParentContext([]byte(trace)); valid {
 			hasTrace = true
 			p.TraceID = tpc.TraceID
 			p.ParentSpanID = tpc.ParentSpanID
+			parentSampled = tpc.Sampled
+			p.Sampled = tpc.Sampled
 		}
 	}
 
@@ -62,6 +77,18 @@ func PropagationContextFromHeaders(trace, baggage string) (PropagationContext, e
 		p.DynamicSamplingContext = dsc
 	}
 
+	// Continue the sample_rand of the trace, or generate one that is
+	// consistent with the upstream sampling decision.
+	if sampleRand, ok := p.DynamicSamplingContext.sampleRand(); ok {
+		p.SampleRand = sampleRand
+	} else if hasTrace {
+		sampleRate, ok := p.DynamicSamplingContext.sampleRate()
+		p.SampleRand = newSampleRand(parentSampled, sampleRate, ok)
+		if p.DynamicSamplingContext.HasEntries() {
+			p.DynamicSamplingContext.Entries["sample_rand"] = formatSampleRand(p.SampleRand)
+		}
+	}
+
 	// In case a sentry-trace header is present but there are no sentry-related
 	// values in the baggage, create an empty, frozen DynamicSamplingContext.
 	if hasTrace && !p.DynamicSamplingContext.HasEntries() {
@@ -90,7 +90,7 @@ func TestPropagationContextFromHeaders(t *testing.T) {
 		},
 		{
 			traceStr:   "bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-1",
-			baggageStr: "sentry-trace_id=d49d9bf66f13450b81f65bc51cf49c03,sentry-public_key=public,sentry-sample_rate=1",
+			baggageStr: "sentry-trace_id=d49d9bf66f13450b81f65bc51cf49c03,sentry-public_key=public,sentry-sample_rate=1,sentry-sample_rand=0.250000",
 			want: PropagationContext{
 				TraceID:      TraceIDFromHex("bc6d53f15eb88f4320054569b8c553d4"),
 				ParentSpanID: SpanIDFromHex("b72fa28504b07285"),
@@ -99,6 +99,7 @@ func TestPropagationContextFromHeaders(t *testing.T) {
 					Entries: map[string]string{
 						"public_key":  "public",
 						"sample_rate": "1",
+						"sample_rand": "0.250000",
 						"trace_id":    "d49d9bf66f13450b81f65bc51cf49c03",
 					},
 				},
@@ -142,4 +143,36 @@ func TestNewPropagationContext(t *testing.T) {
 	if context.SpanID == zeroSpanID {
 		t.Errorf("SpanID should not be zero")
 	}
+
+	if context.SampleRand < 0 || context.SampleRand >= 1 {
+		t.Errorf("SampleRand = %f, want in [0, 1)", context.SampleRand)
+	}
+}
+
+func TestPropagationContextFromHeaders_SampleRand(t *testing.T) {
+	p, err := PropagationContextFromHeaders("bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-1", "sentry-sample_rand=0.123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, p.SampleRand, 0.123456)
+
+	for i := 0; i < 100; i++ {
+		p, err = PropagationContextFromHeaders("bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-0", "sentry-sample_rate=0.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.SampleRand < 0.1 || p.SampleRand >= 1 {
+			t.Fatalf("SampleRand = %f, want in [0.1, 1) for an unsampled parent", p.SampleRand)
+		}
+		assertEqual(t, p.DynamicSamplingContext.Entries["sample_rand"], formatSampleRand(p.SampleRand))
+	}
+
+	// An invalid sample_rand is replaced.
+	p, err = PropagationContextFromHeaders("bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-1", "sentry-sample_rand=1.5,sentry-sample_rate=0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SampleRand >= 0.5 {
+		t.Errorf("SampleRand = %f, want < 0.5 for a sampled parent", p.SampleRand)
+	}
 }
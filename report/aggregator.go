@@ -15,16 +15,28 @@ import (
 type Aggregator struct {
 	mu       sync.Mutex
 	outcomes map[OutcomeKey]*atomic.Int64
+	// totals are never reset by TakeReport.
+	totals    map[OutcomeKey]int64
+	onDiscard func(DiscardedEvent)
 }
 
 // NewAggregator creates a new client report Aggregator.
 func NewAggregator() *Aggregator {
 	a := &Aggregator{
 		outcomes: make(map[OutcomeKey]*atomic.Int64),
+		totals:   make(map[OutcomeKey]int64),
 	}
 	return a
 }
 
+// SetDiscardCallback sets a callback that is called with every outcome that
+// is recorded. It is called synchronously on the goroutine that discarded the
+// items, so it must return quickly. SetDiscardCallback must be called before
+// the Aggregator is used.
+func (a *Aggregator) SetDiscardCallback(callback func(DiscardedEvent)) {
+	a.onDiscard = callback
+}
+
 // Record records a discarded event outcome.
 func (a *Aggregator) Record(reason DiscardReason, category ratelimit.Category, quantity int64) {
 	if a == nil || quantity <= 0 {
@@ -34,13 +46,34 @@ func (a *Aggregator) Record(reason DiscardReason, category ratelimit.Category, q
 	key := OutcomeKey{Reason: reason, Category: category}
 
 	a.mu.Lock()
-	defer a.mu.Unlock()
 	counter, exists := a.outcomes[key]
 	if !exists {
 		counter = &atomic.Int64{}
 		a.outcomes[key] = counter
 	}
 	counter.Add(quantity)
+	a.totals[key] += quantity
+	a.mu.Unlock()
+
+	if a.onDiscard != nil {
+		a.onDiscard(DiscardedEvent{Reason: reason, Category: category, Quantity: quantity})
+	}
+}
+
+// Totals returns all outcomes recorded since the Aggregator was created,
+// including those already taken by TakeReport.
+func (a *Aggregator) Totals() []DiscardedEvent {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	events := make([]DiscardedEvent, 0, len(a.totals))
+	for key, quantity := range a.totals {
+		events = append(events, DiscardedEvent{Reason: key.Reason, Category: key.Category, Quantity: quantity})
+	}
+	return events
 }
 
 // RecordOne is a helper method to record one discarded event outcome.
@@ -116,8 +149,16 @@ func (a *Aggregator) RecordForEnvelope(reason DiscardReason, envelope *protocol.
 			}
 		case protocol.EnvelopeItemTypeTraceMetric:
 			a.RecordOne(reason, ratelimit.CategoryTraceMetric)
+		case protocol.EnvelopeItemTypeSpan:
+			if item.Header.ItemCount != nil {
+				a.Record(reason, ratelimit.CategorySpan, int64(*item.Header.ItemCount))
+			}
 		case protocol.EnvelopeItemTypeCheckIn:
 			a.RecordOne(reason, ratelimit.CategoryMonitor)
+		case protocol.EnvelopeItemTypeProfile:
+			a.RecordOne(reason, ratelimit.CategoryProfile)
+		case protocol.EnvelopeItemTypeProfileChunk:
+			a.RecordOne(reason, ratelimit.CategoryProfileChunk)
 		case protocol.EnvelopeItemTypeAttachment, protocol.EnvelopeItemTypeClientReport:
 			// Skip — not reportable categories
 		}
@@ -10,6 +10,10 @@ const (
 	// ReasonBufferOverflow indicates that an internal buffer was full.
 	ReasonBufferOverflow DiscardReason = "buffer_overflow"
 
+	// ReasonCacheOverflow indicates the item was evicted from the offline cache, either because the cache was full
+	// or because the item was stored for longer than the configured maximum age.
+	ReasonCacheOverflow DiscardReason = "cache_overflow"
+
 	// ReasonRateLimitBackoff indicates the item was dropped due to rate limiting.
 	ReasonRateLimitBackoff DiscardReason = "ratelimit_backoff"
 
@@ -22,6 +26,10 @@ const (
 	// ReasonSampleRate indicates the item was dropped due to sampling.
 	ReasonSampleRate DiscardReason = "sample_rate"
 
+	// ReasonBackpressure indicates the item was dropped because its sample rate was lowered while the SDK could not
+	// keep up with the telemetry it received.
+	ReasonBackpressure DiscardReason = "backpressure"
+
 	// ReasonNetworkError indicates an HTTP request failed (connection error).
 	ReasonNetworkError DiscardReason = "network_error"
 
@@ -33,6 +41,14 @@ const (
 	// For more details https://develop.sentry.dev/sdk/expected-features/#dealing-with-network-failures
 	ReasonSendError DiscardReason = "send_error"
 
+	// ReasonRetriesExhausted indicates the item could not be delivered after it was retried as often as the configured
+	// retry budget allows, or when waiting for the next retry would have taken too long.
+	ReasonRetriesExhausted DiscardReason = "retries_exhausted"
+
+	// ReasonTooLarge indicates the item exceeded a size limit enforced by Sentry during ingestion and was dropped by
+	// the SDK, which would otherwise have lost the whole envelope.
+	ReasonTooLarge DiscardReason = "too_large"
+
 	// ReasonInternalError indicates an internal SDK error.
 	ReasonInternalError DiscardReason = "internal_sdk_error"
 )
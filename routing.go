@@ -0,0 +1,205 @@
+package sentry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/protocol"
+)
+
+// EventRoute sends events that match it to Dsn instead of ClientOptions.Dsn,
+// for example to let every team that owns a package of a shared binary
+// receive its errors in its own project.
+//
+// A route matches by Module, by tag or by both, in which case both have to
+// match. Routes without any condition are ignored.
+type EventRoute struct {
+	// Module matches events whose most recent in-app stack frame belongs to
+	// this package or one of its subpackages, such as
+	// "github.com/acme/monorepo/billing".
+	Module string
+	// TagKey matches events that have this tag, usually set on the scope.
+	TagKey string
+	// TagValue restricts TagKey to events whose tag has this value. An empty
+	// TagValue matches any value.
+	TagValue string
+	// Dsn is the DSN matching events are sent to.
+	Dsn string
+}
+
+func (r EventRoute) matches(event *Event, module string) bool {
+	if r.Module != "" && module != r.Module && !strings.HasPrefix(module, r.Module+"/") {
+		return false
+	}
+	if r.TagKey != "" {
+		value, ok := event.Tags[r.TagKey]
+		if !ok || (r.TagValue != "" && value != r.TagValue) {
+			return false
+		}
+	}
+	return true
+}
+
+type eventRoute struct {
+	EventRoute
+	dsn *protocol.Dsn
+}
+
+// eventRouter picks the DSN of an event from ClientOptions.EventRoutes.
+type eventRouter struct {
+	routes []eventRoute
+}
+
+// newEventRouter returns an eventRouter for all valid routes, or nil if there
+// are none.
+func newEventRouter(routes []EventRoute) *eventRouter {
+	var router eventRouter
+	for _, route := range routes {
+		if route.Module == "" && route.TagKey == "" {
+			debuglog.Println("Ignoring event route without Module or TagKey")
+			continue
+		}
+		dsn, err := protocol.NewDsn(route.Dsn)
+		if err != nil {
+			debuglog.Printf("Ignoring event route: %v", err)
+			continue
+		}
+		router.routes = append(router.routes, eventRoute{EventRoute: route, dsn: dsn})
+	}
+	if len(router.routes) == 0 {
+		return nil
+	}
+	return &router
+}
+
+// route returns the DSN of the first route that matches the event, or nil if
+// none does.
+func (r *eventRouter) route(event *Event) *protocol.Dsn {
+	if r == nil {
+		return nil
+	}
+	module := topInAppModule(event)
+	for _, route := range r.routes {
+		if route.matches(event, module) {
+			return route.dsn
+		}
+	}
+	return nil
+}
+
+// rawDsns returns the distinct DSNs of all routes.
+func (r *eventRouter) rawDsns() []string {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(r.routes))
+	var dsns []string
+	for _, route := range r.routes {
+		key := route.dsn.String()
+		if !seen[key] {
+			seen[key] = true
+			dsns = append(dsns, route.Dsn)
+		}
+	}
+	return dsns
+}
+
+// topInAppModule returns the module of the most recent in-app frame of the
+// event, looking at the outermost exception first and at threads last.
+func topInAppModule(event *Event) string {
+	var stacktraces []*Stacktrace
+	for i := len(event.Exception) - 1; i >= 0; i-- {
+		stacktraces = append(stacktraces, event.Exception[i].Stacktrace)
+	}
+	for _, thread := range event.Threads {
+		stacktraces = append(stacktraces, thread.Stacktrace)
+	}
+
+	for _, stacktrace := range stacktraces {
+		if stacktrace == nil {
+			continue
+		}
+		for i := len(stacktrace.Frames) - 1; i >= 0; i-- {
+			if frame := stacktrace.Frames[i]; frame.InApp {
+				return frame.Module
+			}
+		}
+	}
+	return ""
+}
+
+// readdressEvent returns a shallow copy of the event whose dynamic sampling
+// context is readdressed with protocol.ReaddressTrace.
+func readdressEvent(event *Event, from, to *protocol.Dsn) *Event {
+	readdressed := *event
+	readdressed.sdkMetaData.dsc.Entries = protocol.ReaddressTrace(event.sdkMetaData.dsc.Entries, from, to)
+	return &readdressed
+}
+
+// routingTransport sends events routed by ClientOptions.EventRoutes to an
+// HTTPTransport for their DSN, and all other events to a primary transport.
+// Each route transport has its own rate limits and client reports.
+type routingTransport struct {
+	primary Transport
+	routes  []destinationTransport
+}
+
+// newRoutingTransport wraps primary in a routingTransport if router has
+// routes, or returns primary otherwise.
+func newRoutingTransport(primary Transport, router *eventRouter, options ClientOptions) Transport {
+	routes := newDestinationTransports(router.rawDsns(), options)
+	if len(routes) == 0 {
+		return primary
+	}
+	return &routingTransport{primary: primary, routes: routes}
+}
+
+func (t *routingTransport) Configure(options ClientOptions) {
+	t.primary.Configure(options)
+	for _, route := range t.routes {
+		route.configure(options)
+	}
+}
+
+func (t *routingTransport) SendEvent(event *Event) {
+	if event.sdkMetaData.dsn != nil {
+		key := event.sdkMetaData.dsn.String()
+		for _, route := range t.routes {
+			if route.dsn.String() == key {
+				route.transport.SendEvent(event)
+				return
+			}
+		}
+	}
+	t.primary.SendEvent(event)
+}
+
+func (t *routingTransport) Flush(timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.FlushWithContext(ctx)
+}
+
+func (t *routingTransport) FlushWithContext(ctx context.Context) bool {
+	ok := t.primary.FlushWithContext(ctx)
+	for _, route := range t.routes {
+		if !route.transport.FlushWithContext(ctx) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// stats returns the stats of the primary transport.
+func (t *routingTransport) stats() protocol.TransportStats {
+	return transportStats(t.primary)
+}
+
+func (t *routingTransport) Close() {
+	t.primary.Close()
+	for _, route := range t.routes {
+		route.transport.Close()
+	}
+}
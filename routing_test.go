@@ -0,0 +1,129 @@
+package sentry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getsentry/sentry-go/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventRouter(t *testing.T) {
+	router := newEventRouter([]EventRoute{
+		{Module: "github.com/acme/mono/billing", Dsn: "https://billing@example.com/1"},
+		{TagKey: "team", TagValue: "search", Dsn: "https://search@example.com/2"},
+		{Module: "github.com/acme/mono/auth", TagKey: "region", Dsn: "https://auth@example.com/3"},
+		{Dsn: "https://ignored@example.com/4"},
+		{Module: "github.com/acme/mono/invalid", Dsn: "invalid"},
+	})
+	require.NotNil(t, router)
+	assert.Len(t, router.routes, 3)
+
+	frames := func(modules ...string) *Stacktrace {
+		stacktrace := &Stacktrace{}
+		for _, module := range modules {
+			stacktrace.Frames = append(stacktrace.Frames, Frame{Module: module, InApp: !strings.HasPrefix(module, "net/")})
+		}
+		return stacktrace
+	}
+
+	tests := []struct {
+		name  string
+		event *Event
+		want  string
+	}{
+		{
+			name:  "top in-app frame",
+			event: &Event{Exception: []Exception{{Stacktrace: frames("main", "github.com/acme/mono/billing/invoice", "net/http")}}},
+			want:  "billing",
+		},
+		{
+			name:  "only the top in-app frame counts",
+			event: &Event{Exception: []Exception{{Stacktrace: frames("github.com/acme/mono/billing", "main")}}},
+		},
+		{
+			name:  "module prefix is not a package",
+			event: &Event{Exception: []Exception{{Stacktrace: frames("github.com/acme/mono/billingv2")}}},
+		},
+		{
+			name: "outermost exception first",
+			event: &Event{Exception: []Exception{
+				{Stacktrace: frames("main")},
+				{Stacktrace: frames("github.com/acme/mono/billing")},
+			}},
+			want: "billing",
+		},
+		{
+			name:  "thread stacktrace",
+			event: &Event{Threads: []Thread{{Stacktrace: frames("github.com/acme/mono/billing")}}},
+			want:  "billing",
+		},
+		{
+			name:  "tag",
+			event: &Event{Tags: map[string]string{"team": "search"}},
+			want:  "search",
+		},
+		{
+			name:  "tag with other value",
+			event: &Event{Tags: map[string]string{"team": "billing"}},
+		},
+		{
+			name: "module and tag",
+			event: &Event{
+				Tags:      map[string]string{"region": "eu"},
+				Exception: []Exception{{Stacktrace: frames("github.com/acme/mono/auth")}},
+			},
+			want: "auth",
+		},
+		{
+			name:  "module without tag",
+			event: &Event{Exception: []Exception{{Stacktrace: frames("github.com/acme/mono/auth")}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn := router.route(tt.event)
+			if tt.want == "" {
+				assert.Nil(t, dsn)
+				return
+			}
+			require.NotNil(t, dsn)
+			assert.Equal(t, tt.want, dsn.GetPublicKey())
+		})
+	}
+
+	assert.Nil(t, newEventRouter(nil))
+	assert.Nil(t, (*eventRouter)(nil).route(&Event{}))
+}
+
+func TestClient_EventRoutes(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		primary := newEnvelopeServer(t, "primary", "1", nil)
+		search := newEnvelopeServer(t, "search", "2", nil)
+
+		client, err := NewClient(ClientOptions{
+			Dsn:                    primary.dsn,
+			EventRoutes:            []EventRoute{{TagKey: "team", TagValue: "search", Dsn: search.dsn}},
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+
+		capture := func(message string, tags map[string]string) {
+			event := client.EventFromMessage(message, LevelError)
+			event.Tags = tags
+			event.sdkMetaData.dsc = DynamicSamplingContext{
+				Entries: map[string]string{"public_key": "primary", "trace_id": "abc"},
+				Frozen:  true,
+			}
+			client.CaptureEvent(event, nil, &MockScope{})
+		}
+		capture("routed", map[string]string{"team": "search"})
+		capture("default", map[string]string{"team": "billing"})
+		require.True(t, client.Flush(testutils.FlushTimeout()), "flush timed out")
+
+		assert.Equal(t, []receivedEvent{{"default", primary.dsn, "primary"}}, primary.received())
+		assert.Equal(t, []receivedEvent{{"routed", search.dsn, "search"}}, search.received())
+	})
+}
@@ -314,10 +314,40 @@ func (scope *Scope) AddEventProcessor(processor EventProcessor) {
 }
 
 // ApplyToEvent takes the data from the current scope and attaches it to the event.
-func (scope *Scope) ApplyToEvent(event *Event, hint *EventHint, client *Client) *Event { //nolint:gocyclo
+func (scope *Scope) ApplyToEvent(event *Event, hint *EventHint, client *Client) *Event {
 	scope.mu.RLock()
 	defer scope.mu.RUnlock()
 
+	scope.applyDataToEvent(event, hint, client)
+
+	for _, processor := range scope.eventProcessors {
+		id := event.EventID
+		category := event.toCategory()
+		spanCountBefore := event.GetSpanCount()
+		event = processor(event, hint)
+		if event == nil {
+			debuglog.Printf("Event dropped by one of the Scope EventProcessors: %s\n", id)
+			if client != nil {
+				client.reportRecorder.RecordOne(report.ReasonEventProcessor, category)
+				if category == ratelimit.CategoryTransaction {
+					client.reportRecorder.Record(report.ReasonEventProcessor, ratelimit.CategorySpan, int64(spanCountBefore))
+				}
+			}
+			return nil
+		}
+		if droppedSpans := spanCountBefore - event.GetSpanCount(); droppedSpans > 0 {
+			if client != nil {
+				client.reportRecorder.Record(report.ReasonEventProcessor, ratelimit.CategorySpan, int64(droppedSpans))
+			}
+		}
+	}
+
+	return event
+}
+
+// applyDataToEvent attaches the data of the scope to the event, without
+// running its event processors. Must be called with scope.mu held.
+func (scope *Scope) applyDataToEvent(event *Event, hint *EventHint, client *Client) { //nolint:gocyclo
 	if len(scope.breadcrumbs) > 0 {
 		event.Breadcrumbs = append(event.Breadcrumbs, scope.breadcrumbs...)
 	}
@@ -425,30 +455,6 @@ func (scope *Scope) ApplyToEvent(event *Event, hint *EventHint, client *Client)
 			event.Request.Data = string(scope.requestBody.Bytes())
 		}
 	}
-
-	for _, processor := range scope.eventProcessors {
-		id := event.EventID
-		category := event.toCategory()
-		spanCountBefore := event.GetSpanCount()
-		event = processor(event, hint)
-		if event == nil {
-			debuglog.Printf("Event dropped by one of the Scope EventProcessors: %s\n", id)
-			if client != nil {
-				client.reportRecorder.RecordOne(report.ReasonEventProcessor, category)
-				if category == ratelimit.CategoryTransaction {
-					client.reportRecorder.Record(report.ReasonEventProcessor, ratelimit.CategorySpan, int64(spanCountBefore))
-				}
-			}
-			return nil
-		}
-		if droppedSpans := spanCountBefore - event.GetSpanCount(); droppedSpans > 0 {
-			if client != nil {
-				client.reportRecorder.Record(report.ReasonEventProcessor, ratelimit.CategorySpan, int64(droppedSpans))
-			}
-		}
-	}
-
-	return event
 }
 
 // cloneContext returns a new context with keys and values copied from the passed one.
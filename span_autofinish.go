@@ -0,0 +1,172 @@
+package sentry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+)
+
+// unfinishedSpanDataKey marks the span data of child spans that were still
+// open when their transaction finished.
+const unfinishedSpanDataKey = "sentry.unfinished"
+
+// WithIdleTimeout finishes a transaction once it had no open child spans for
+// the given duration, in case the code that started it never finishes it. The
+// transaction then ends when its last child span finished, and gets the
+// SpanStatusDeadlineExceeded status unless a status was set.
+//
+// The option only has an effect on transactions.
+func WithIdleTimeout(timeout time.Duration) SpanOption {
+	return func(s *Span) {
+		if timeout <= 0 || !s.IsTransaction() {
+			return
+		}
+		s.autoFinisher().idleTimeout = timeout
+	}
+}
+
+// WithDeadline finishes a transaction at the given time, in case the code that
+// started it did not finish it earlier. The transaction then gets the
+// SpanStatusDeadlineExceeded status unless a status was set.
+//
+// The option only has an effect on transactions.
+func WithDeadline(deadline time.Time) SpanOption {
+	return func(s *Span) {
+		if !s.IsTransaction() {
+			return
+		}
+		s.autoFinisher().deadline = deadline
+	}
+}
+
+// An autoFinisher finishes a transaction after a deadline or once it has been
+// idle for too long.
+type autoFinisher struct {
+	span        *Span
+	idleTimeout time.Duration
+	deadline    time.Time
+
+	mu            sync.Mutex
+	stopped       bool
+	openChildren  int
+	idleSince     time.Time
+	idleTimer     *time.Timer
+	deadlineTimer *time.Timer
+}
+
+func (s *Span) autoFinisher() *autoFinisher {
+	if s.autoFinish == nil {
+		s.autoFinish = &autoFinisher{span: s}
+	}
+	return s.autoFinish
+}
+
+// start arms the timers of the transaction.
+func (a *autoFinisher) start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.deadline.IsZero() {
+		a.deadlineTimer = time.AfterFunc(time.Until(a.deadline), func() {
+			a.finish(time.Time{})
+		})
+	}
+	a.armIdleTimer()
+}
+
+// armIdleTimer starts the idle timeout. It must be called with a.mu held.
+func (a *autoFinisher) armIdleTimer() {
+	if a.idleTimeout == 0 || a.stopped {
+		return
+	}
+	a.idleSince = time.Now()
+	idleSince := a.idleSince
+	a.idleTimer = time.AfterFunc(a.idleTimeout, func() {
+		a.finish(idleSince)
+	})
+}
+
+// childStarted pauses the idle timeout while a child span is open.
+func (a *autoFinisher) childStarted() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.openChildren++
+	a.idleSince = time.Time{}
+	if a.idleTimer != nil {
+		a.idleTimer.Stop()
+		a.idleTimer = nil
+	}
+}
+
+// childFinished restarts the idle timeout once no child span is open.
+func (a *autoFinisher) childFinished() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.openChildren--
+	if a.openChildren == 0 {
+		a.armIdleTimer()
+	}
+}
+
+// finish finishes the transaction because of a timeout, at the given end time
+// or now if it is zero.
+func (a *autoFinisher) finish(endTime time.Time) {
+	a.mu.Lock()
+	if a.stopped {
+		a.mu.Unlock()
+		return
+	}
+	// Another child may have started and finished since the idle timer fired.
+	if !endTime.IsZero() && !endTime.Equal(a.idleSince) {
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+
+	s := a.span
+	s.finishOnce.Do(func() {
+		s.mu.Lock()
+		if s.Status == SpanStatusUndefined {
+			s.Status = SpanStatusDeadlineExceeded
+		}
+		s.mu.Unlock()
+		s.EndTime = endTime
+		s.doFinish()
+	})
+}
+
+// stop disarms the timers once the transaction finished.
+func (a *autoFinisher) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stopped = true
+	if a.idleTimer != nil {
+		a.idleTimer.Stop()
+	}
+	if a.deadlineTimer != nil {
+		a.deadlineTimer.Stop()
+	}
+}
+
+// finishOpenChildren closes the child spans of a transaction that were not
+// finished when the transaction finished, so that they are not dropped. They
+// get the SpanStatusCanceled status and are marked in their data.
+func (s *Span) finishOpenChildren() {
+	for _, child := range s.recorder.children() {
+		child.finishOnce.Do(func() {
+			debuglog.Printf("Finishing unfinished span with the transaction: Op=%q TraceID=%s SpanID=%s", child.Op, child.TraceID, child.SpanID)
+			child.mu.Lock()
+			child.Status = SpanStatusCanceled
+			if child.Data == nil {
+				child.Data = make(map[string]interface{})
+			}
+			child.Data[unfinishedSpanDataKey] = true
+			child.mu.Unlock()
+			child.EndTime = s.EndTime
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeadline(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	transaction := StartTransaction(ctx, "transaction", WithDeadline(time.Now().Add(10*time.Millisecond)))
+	child := transaction.StartChild("child")
+
+	require.Eventually(t, func() bool {
+		return len(transport.Events()) == 1
+	}, time.Second, time.Millisecond)
+
+	event := transport.Events()[0]
+	assert.Equal(t, SpanStatusDeadlineExceeded, event.Contexts["trace"]["status"])
+	require.Len(t, event.Spans, 1)
+	assert.Equal(t, SpanStatusCanceled, event.Spans[0].Status)
+	assert.Equal(t, true, event.Spans[0].Data[unfinishedSpanDataKey])
+	assert.Equal(t, event.Timestamp, event.Spans[0].EndTime)
+
+	// Finishing afterwards must not send the transaction again.
+	child.Finish()
+	transaction.Finish()
+	assert.Len(t, transport.Events(), 1)
+}
+
+func TestWithDeadline_FinishedInTime(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	transaction := StartTransaction(ctx, "transaction", WithDeadline(time.Now().Add(20*time.Millisecond)))
+	transaction.Status = SpanStatusOK
+	transaction.Finish()
+	time.Sleep(40 * time.Millisecond)
+
+	require.Len(t, transport.Events(), 1)
+	assert.Equal(t, SpanStatusOK, transport.Events()[0].Contexts["trace"]["status"])
+}
+
+func TestWithIdleTimeout(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	transaction := StartTransaction(ctx, "transaction", WithIdleTimeout(20*time.Millisecond))
+	child := transaction.StartChild("child")
+	// An open child span keeps the transaction from being idle.
+	time.Sleep(40 * time.Millisecond)
+	assert.Empty(t, transport.Events())
+	child.Finish()
+
+	require.Eventually(t, func() bool {
+		return len(transport.Events()) == 1
+	}, time.Second, time.Millisecond)
+
+	event := transport.Events()[0]
+	assert.Equal(t, SpanStatusDeadlineExceeded, event.Contexts["trace"]["status"])
+	require.Len(t, event.Spans, 1)
+	assert.Equal(t, SpanStatusUndefined, event.Spans[0].Status)
+	assert.NotContains(t, event.Spans[0].Data, unfinishedSpanDataKey)
+	// The transaction ends when it became idle, not when the timeout expired.
+	assert.False(t, event.Timestamp.Before(child.EndTime))
+	assert.Less(t, event.Timestamp.Sub(child.EndTime), 20*time.Millisecond)
+}
+
+func TestWithIdleTimeout_ChildSpan(t *testing.T) {
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+	})
+
+	transaction := StartTransaction(ctx, "transaction")
+	child := transaction.StartChild("child", WithIdleTimeout(time.Millisecond), WithDeadline(time.Now()))
+	assert.Nil(t, child.autoFinish)
+	child.Finish()
+	transaction.Finish()
+}
+
+func TestSpan_FinishOpenChildren(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	transaction := StartTransaction(ctx, "transaction")
+	finished := transaction.StartChild("finished")
+	finished.Status = SpanStatusOK
+	finished.Finish()
+	open := transaction.StartChild("open")
+	open.StartChild("open grandchild")
+	transaction.Finish()
+	open.Finish()
+
+	require.Len(t, transport.Events(), 1)
+	event := transport.Events()[0]
+	require.Len(t, event.Spans, 3)
+	for _, span := range event.Spans {
+		assert.False(t, span.EndTime.IsZero(), span.Op)
+		if span.Op == "finished" {
+			assert.Equal(t, SpanStatusOK, span.Status)
+			assert.NotContains(t, span.Data, unfinishedSpanDataKey)
+			continue
+		}
+		assert.Equal(t, SpanStatusCanceled, span.Status, span.Op)
+		assert.Equal(t, true, span.Data[unfinishedSpanDataKey], span.Op)
+		assert.Equal(t, event.Timestamp, span.EndTime, span.Op)
+	}
+}
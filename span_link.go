@@ -0,0 +1,99 @@
+package sentry
+
+import (
+	"encoding/json"
+	"maps"
+)
+
+// A SpanLink relates a span to a span of another trace, or of the same trace
+// outside of its parent-child tree. For example, a span that consumes a batch
+// of queue messages can link to the spans that produced every message.
+type SpanLink struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled is the sampling decision of the linked span, if known.
+	Sampled Sampled
+	// Attributes describe the relation to the linked span.
+	Attributes map[string]interface{}
+}
+
+// MarshalJSON encodes the link in the format of the Sentry span protocol.
+func (l SpanLink) MarshalJSON() ([]byte, error) {
+	link := struct {
+		TraceID    TraceID                `json:"trace_id"`
+		SpanID     SpanID                 `json:"span_id"`
+		Sampled    *bool                  `json:"sampled,omitempty"`
+		Attributes map[string]interface{} `json:"attributes,omitempty"`
+	}{
+		TraceID:    l.TraceID,
+		SpanID:     l.SpanID,
+		Attributes: l.Attributes,
+	}
+	if l.Sampled != SampledUndefined {
+		sampled := l.Sampled.Bool()
+		link.Sampled = &sampled
+	}
+	return json.Marshal(link)
+}
+
+// SpanLinkFromSentryTrace returns a link to the span of a sentry-trace header
+// value, as returned by Span.ToSentryTrace. The returned value indicates
+// whether the header was valid.
+func SpanLinkFromSentryTrace(header string, attributes map[string]interface{}) (SpanLink, bool) {
+	tpc, valid := parseSentryTrace([]byte(header))
+	if !valid {
+		return SpanLink{}, false
+	}
+	return SpanLink{
+		TraceID:    tpc.TraceID,
+		SpanID:     tpc.ParentSpanID,
+		Sampled:    tpc.Sampled,
+		Attributes: attributes,
+	}, true
+}
+
+// SpanLinkFromTraceparent returns a link to the span of a W3C traceparent
+// header value, as returned by Span.ToTraceparent. The returned value
+// indicates whether the header was valid.
+func SpanLinkFromTraceparent(header string, attributes map[string]interface{}) (SpanLink, bool) {
+	tpc, valid := parseW3CTraceparent([]byte(header))
+	if !valid {
+		return SpanLink{}, false
+	}
+	return SpanLink{
+		TraceID:    tpc.TraceID,
+		SpanID:     tpc.ParentSpanID,
+		Sampled:    tpc.Sampled,
+		Attributes: attributes,
+	}, true
+}
+
+// SpanLinkFromHeaders returns a link to the span that sent the given
+// sentry-trace and traceparent header values, as found on a received request
+// or message. The sentry-trace value is preferred, the traceparent value is
+// used if it is missing or invalid. The returned value indicates whether a
+// link could be extracted.
+func SpanLinkFromHeaders(sentryTrace, traceparent string, attributes map[string]interface{}) (SpanLink, bool) {
+	if link, ok := SpanLinkFromSentryTrace(sentryTrace, attributes); ok {
+		return link, true
+	}
+	return SpanLinkFromTraceparent(traceparent, attributes)
+}
+
+// AddLink links the span to another span. Links are sent with the span, and
+// with the trace context of a transaction.
+func (s *Span) AddLink(link SpanLink) {
+	link.Attributes = maps.Clone(link.Attributes)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Links = append(s.Links, link)
+}
+
+// WithSpanLink option links the span to another span when it is started.
+func WithSpanLink(link SpanLink) SpanOption {
+	return func(s *Span) {
+		s.AddLink(link)
+	}
+}
@@ -0,0 +1,319 @@
+package sentry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpanLinkMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		link SpanLink
+		want string
+	}{
+		{
+			name: "sampled with attributes",
+			link: SpanLink{
+				TraceID:    TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:     SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled:    SampledTrue,
+				Attributes: map[string]interface{}{"messaging.message.id": "42"},
+			},
+			want: `{"trace_id":"d49d9bf66f13450b81f65bc51cf49c03","span_id":"1cc4b26ab9094ef0","sampled":true,"attributes":{"messaging.message.id":"42"}}`,
+		},
+		{
+			name: "not sampled",
+			link: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled: SampledFalse,
+			},
+			want: `{"trace_id":"d49d9bf66f13450b81f65bc51cf49c03","span_id":"1cc4b26ab9094ef0","sampled":false}`,
+		},
+		{
+			name: "sampling decision unknown",
+			link: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+			},
+			want: `{"trace_id":"d49d9bf66f13450b81f65bc51cf49c03","span_id":"1cc4b26ab9094ef0"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.link)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(b))
+		})
+	}
+}
+
+func TestSpanLinkFromSentryTrace(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantLink  SpanLink
+		wantValid bool
+	}{
+		{
+			name:   "sampled",
+			header: "d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-1",
+			wantLink: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled: SampledTrue,
+			},
+			wantValid: true,
+		},
+		{
+			name:   "sampling decision deferred",
+			header: "d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0",
+			wantLink: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+			},
+			wantValid: true,
+		},
+		{
+			name:   "malformed",
+			header: "xxx-malformed",
+		},
+		{
+			name:   "invalid sampled flag",
+			header: "d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0--",
+		},
+		{
+			name:   "empty",
+			header: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, valid := SpanLinkFromSentryTrace(tt.header, nil)
+			assert.Equal(t, tt.wantValid, valid)
+			assert.Equal(t, tt.wantLink, link)
+		})
+	}
+}
+
+func TestSpanLinkFromTraceparent(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantLink  SpanLink
+		wantValid bool
+	}{
+		{
+			name:   "sampled",
+			header: "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01",
+			wantLink: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled: SampledTrue,
+			},
+			wantValid: true,
+		},
+		{
+			name:   "not sampled, other flags set",
+			header: "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-02",
+			wantLink: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled: SampledFalse,
+			},
+			wantValid: true,
+		},
+		{
+			name:   "upper case",
+			header: "00-D49D9BF66F13450B81F65BC51CF49C03-1CC4B26AB9094EF0-01",
+			wantLink: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled: SampledTrue,
+			},
+			wantValid: true,
+		},
+		{
+			name:   "future version with extra fields",
+			header: "01-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01-extra",
+			wantLink: SpanLink{
+				TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled: SampledTrue,
+			},
+			wantValid: true,
+		},
+		{
+			name:   "malformed",
+			header: "00-xxx-malformed-01",
+		},
+		{
+			name:   "sentry-trace format",
+			header: "d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-1",
+		},
+		{
+			name:   "sampled flag -",
+			header: "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0--",
+		},
+		{
+			name:   "version 00 with extra fields",
+			header: "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01-extra",
+		},
+		{
+			name:   "invalid version",
+			header: "ff-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01",
+		},
+		{
+			name:   "zero trace ID",
+			header: "00-00000000000000000000000000000000-1cc4b26ab9094ef0-01",
+		},
+		{
+			name:   "zero span ID",
+			header: "00-d49d9bf66f13450b81f65bc51cf49c03-0000000000000000-01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, valid := SpanLinkFromTraceparent(tt.header, nil)
+			assert.Equal(t, tt.wantValid, valid)
+			assert.Equal(t, tt.wantLink, link)
+		})
+	}
+}
+
+func TestSpanLinkFromHeaders(t *testing.T) {
+	attributes := map[string]interface{}{"messaging.message.id": "42"}
+
+	link, valid := SpanLinkFromHeaders(
+		"d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-0",
+		"00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01",
+		attributes,
+	)
+	assert.True(t, valid)
+	assert.Equal(t, SpanLink{
+		TraceID:    TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+		SpanID:     SpanIDFromHex("1cc4b26ab9094ef0"),
+		Sampled:    SampledFalse,
+		Attributes: attributes,
+	}, link)
+
+	link, valid = SpanLinkFromHeaders("invalid", "00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01", nil)
+	assert.True(t, valid)
+	assert.Equal(t, TraceIDFromHex("bc6d53f15eb88f4320054569b8c553d4"), link.TraceID)
+
+	_, valid = SpanLinkFromHeaders("", "", nil)
+	assert.False(t, valid)
+}
+
+func TestSpanLinks(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	producer := SpanLink{
+		TraceID:    TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+		SpanID:     SpanIDFromHex("1cc4b26ab9094ef0"),
+		Sampled:    SampledTrue,
+		Attributes: map[string]interface{}{"messaging.message.id": "1"},
+	}
+	attributes := map[string]interface{}{"messaging.message.id": "2"}
+	other := SpanLink{
+		TraceID:    TraceIDFromHex("bc6d53f15eb88f4320054569b8c553d4"),
+		SpanID:     SpanIDFromHex("b72fa28504b07285"),
+		Attributes: attributes,
+	}
+
+	transaction := StartTransaction(ctx, "consume", WithSpanLink(producer))
+	transaction.AddLink(other)
+	child := transaction.StartChild("process", WithSpanLink(other))
+	// Links keep a copy of their attributes.
+	attributes["messaging.message.id"] = "3"
+	child.Finish()
+	transaction.Finish()
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	b, err := json.Marshal(events[0])
+	require.NoError(t, err)
+
+	var event struct {
+		Contexts struct {
+			Trace struct {
+				Links []json.RawMessage `json:"links"`
+			} `json:"trace"`
+		} `json:"contexts"`
+		Spans []struct {
+			Links []json.RawMessage `json:"links"`
+		} `json:"spans"`
+	}
+	require.NoError(t, json.Unmarshal(b, &event))
+
+	wantProducer := `{"trace_id":"d49d9bf66f13450b81f65bc51cf49c03","span_id":"1cc4b26ab9094ef0","sampled":true,"attributes":{"messaging.message.id":"1"}}`
+	wantOther := `{"trace_id":"bc6d53f15eb88f4320054569b8c553d4","span_id":"b72fa28504b07285","attributes":{"messaging.message.id":"2"}}`
+	require.Len(t, event.Contexts.Trace.Links, 2)
+	assert.JSONEq(t, wantProducer, string(event.Contexts.Trace.Links[0]))
+	assert.JSONEq(t, wantOther, string(event.Contexts.Trace.Links[1]))
+	require.Len(t, event.Spans, 1)
+	require.Len(t, event.Spans[0].Links, 1)
+	assert.JSONEq(t, wantOther, string(event.Spans[0].Links[0]))
+}
+
+func TestSpanLinks_SerializationSafe(t *testing.T) {
+	span := &Span{}
+	span.AddLink(SpanLink{
+		TraceID: TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+		SpanID:  SpanIDFromHex("1cc4b26ab9094ef0"),
+	})
+	span.makeSerializationSafe()
+	// Links added after the span was made serialization safe are not sent.
+	span.AddLink(SpanLink{
+		TraceID: TraceIDFromHex("bc6d53f15eb88f4320054569b8c553d4"),
+		SpanID:  SpanIDFromHex("b72fa28504b07285"),
+	})
+
+	b, err := json.Marshal(span)
+	require.NoError(t, err)
+	var got struct {
+		Links []json.RawMessage `json:"links"`
+	}
+	require.NoError(t, json.Unmarshal(b, &got))
+	require.Len(t, got.Links, 1)
+	assert.JSONEq(t, `{"trace_id":"d49d9bf66f13450b81f65bc51cf49c03","span_id":"1cc4b26ab9094ef0"}`, string(got.Links[0]))
+}
+
+func TestStreamSpans_Links(t *testing.T) {
+	client, transport, ctx := newStreamingTestClient(t, ClientOptions{
+		TracesSampleRate: 1.0,
+	})
+
+	transaction := StartTransaction(ctx, "consume")
+	child := transaction.StartChild("process", WithSpanLink(SpanLink{
+		TraceID:    TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+		SpanID:     SpanIDFromHex("1cc4b26ab9094ef0"),
+		Sampled:    SampledFalse,
+		Attributes: map[string]interface{}{"messaging.batch.size": 3},
+	}))
+	child.Finish()
+	client.Flush(time.Second)
+	require.Equal(t, []EnvelopeItemType{EnvelopeItemTypeSpan}, transport.itemTypes())
+
+	var payload struct {
+		Items []struct {
+			Links []json.RawMessage `json:"links"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(transport.envelopes[0].Items[0].Payload, &payload))
+	require.Len(t, payload.Items, 1)
+	require.Len(t, payload.Items[0].Links, 1)
+	assert.JSONEq(t,
+		`{"trace_id":"d49d9bf66f13450b81f65bc51cf49c03","span_id":"1cc4b26ab9094ef0","sampled":false,"attributes":{"messaging.batch.size":{"type":"integer","value":3}}}`,
+		string(payload.Items[0].Links[0]),
+	)
+}
@@ -1,6 +1,7 @@
 package sentry
 
 import (
+	"slices"
 	"sync"
 
 	"github.com/getsentry/sentry-go/internal/debuglog"
@@ -56,3 +57,15 @@ func (r *spanRecorder) children() []*Span {
 	}
 	return r.spans[1:]
 }
+
+// remove removes a child span that was discarded.
+func (r *spanRecorder) remove(s *Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, span := range r.spans {
+		if i > 0 && span == s {
+			r.spans = slices.Delete(r.spans, i, i+1)
+			return
+		}
+	}
+}
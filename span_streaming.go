@@ -0,0 +1,190 @@
+package sentry
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/getsentry/sentry-go/attribute"
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+)
+
+// streamedSpan is a finished span sent on its own in a span envelope item,
+// when ClientOptions.StreamSpans is set. It is a snapshot of the span taken
+// when it finished, so it can be serialized in the background.
+type streamedSpan struct {
+	TraceID        TraceID                    `json:"trace_id"`
+	SpanID         SpanID                     `json:"span_id"`
+	ParentSpanID   SpanID                     `json:"parent_span_id,omitzero"`
+	Name           string                     `json:"name"`
+	Status         string                     `json:"status"`
+	IsSegment      bool                       `json:"is_segment"`
+	StartTimestamp float64                    `json:"start_timestamp"`
+	EndTimestamp   float64                    `json:"end_timestamp"`
+	Attributes     map[string]attribute.Value `json:"attributes,omitempty"`
+	Links          []streamedSpanLink         `json:"links,omitempty"`
+
+	dsc map[string]string
+}
+
+// streamedSpanLink is a SpanLink of a streamed span, with typed attributes.
+type streamedSpanLink struct {
+	TraceID    TraceID                    `json:"trace_id"`
+	SpanID     SpanID                     `json:"span_id"`
+	Sampled    *bool                      `json:"sampled,omitempty"`
+	Attributes map[string]attribute.Value `json:"attributes,omitempty"`
+}
+
+// MakeSerializationSafe is a no-op, streamed spans are snapshots.
+func (s *streamedSpan) MakeSerializationSafe() {}
+
+// GetCategory returns the rate limit category for spans.
+func (s *streamedSpan) GetCategory() ratelimit.Category {
+	return ratelimit.CategorySpan
+}
+
+// GetTraceID returns the trace ID of the span.
+func (s *streamedSpan) GetTraceID() (string, bool) {
+	return s.TraceID.String(), s.TraceID != zeroTraceID
+}
+
+// GetDynamicSamplingContext returns the dynamic sampling context of the
+// transaction the span belongs to.
+func (s *streamedSpan) GetDynamicSamplingContext() map[string]string {
+	return s.dsc
+}
+
+// streamsSpans reports whether child spans are sent as they finish instead
+// of with their transaction.
+func (client *Client) streamsSpans() bool {
+	return client.options.StreamSpans && client.telemetryProcessor != nil
+}
+
+// captureSpan sends a finished child span to the telemetry buffer.
+func (client *Client) captureSpan(span *Span) {
+	if client.telemetryProcessor == nil {
+		return
+	}
+	if !client.telemetryProcessor.Add(client.newStreamedSpan(span)) {
+		debuglog.Println("Dropping span: telemetry buffer full or category missing")
+		// Note: processor tracks client report
+	}
+}
+
+// newStreamedSpan takes a snapshot of a finished span, including the
+// attributes of the transaction and client it belongs to.
+func (client *Client) newStreamedSpan(span *Span) *streamedSpan {
+	attrs := make(map[string]attribute.Value)
+	defaults := map[string]string{
+		"sentry.release":     client.options.Release,
+		"sentry.environment": client.options.Environment,
+		"sentry.sdk.name":    client.sdkIdentifier,
+		"sentry.sdk.version": client.sdkVersion,
+	}
+
+	var dsc DynamicSamplingContext
+	if root := span.GetTransaction(); root != nil {
+		root.mu.RLock()
+		dsc = root.dynamicSamplingContext
+		defaults["sentry.segment.id"] = root.SpanID.String()
+		defaults["sentry.segment.name"] = root.Name
+		root.mu.RUnlock()
+		if !dsc.IsFrozen() {
+			dsc = DynamicSamplingContextFromTransaction(root)
+		}
+	}
+
+	span.mu.RLock()
+	defer span.mu.RUnlock()
+
+	defaults["sentry.op"] = span.Op
+	defaults["sentry.origin"] = string(span.Origin)
+	for k, v := range defaults {
+		if v != "" {
+			attrs[k] = attribute.StringValue(v)
+		}
+	}
+	for k, v := range span.Tags {
+		attrs[k] = attribute.StringValue(v)
+	}
+	for k, v := range span.Data {
+		attrs[k] = spanAttributeValue(v)
+	}
+
+	var links []streamedSpanLink
+	for _, link := range span.Links {
+		streamed := streamedSpanLink{TraceID: link.TraceID, SpanID: link.SpanID}
+		if link.Sampled != SampledUndefined {
+			sampled := link.Sampled.Bool()
+			streamed.Sampled = &sampled
+		}
+		if len(link.Attributes) > 0 {
+			streamed.Attributes = make(map[string]attribute.Value, len(link.Attributes))
+			for k, v := range link.Attributes {
+				streamed.Attributes[k] = spanAttributeValue(v)
+			}
+		}
+		links = append(links, streamed)
+	}
+
+	name := span.Description
+	if name == "" {
+		name = span.Op
+	}
+	status := "ok"
+	if span.Status != SpanStatusUndefined && span.Status != SpanStatusOK {
+		status = "error"
+	}
+
+	return &streamedSpan{
+		TraceID:        span.TraceID,
+		SpanID:         span.SpanID,
+		ParentSpanID:   span.ParentSpanID,
+		Name:           name,
+		Status:         status,
+		StartTimestamp: unixSeconds(span.StartTime),
+		EndTimestamp:   unixSeconds(span.EndTime),
+		Attributes:     attrs,
+		Links:          links,
+		dsc:            maps.Clone(dsc.Entries),
+	}
+}
+
+// spanAttributeValue converts span data to an attribute. Values of types
+// that attributes do not support are sent as JSON, or formatted strings if
+// they cannot be encoded.
+func spanAttributeValue(v interface{}) attribute.Value {
+	switch v := v.(type) {
+	case string:
+		return attribute.StringValue(v)
+	case bool:
+		return attribute.BoolValue(v)
+	case int:
+		return attribute.IntValue(v)
+	case int64:
+		return attribute.Int64Value(v)
+	case float64:
+		return attribute.Float64Value(v)
+	case []string:
+		return attribute.StringSliceValue(v)
+	case []bool:
+		return attribute.BoolSliceValue(v)
+	case []int:
+		return attribute.IntSliceValue(v)
+	case []int64:
+		return attribute.Int64SliceValue(v)
+	case []float64:
+		return attribute.Float64SliceValue(v)
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return attribute.StringValue(string(b))
+	}
+	return attribute.StringValue(fmt.Sprint(v))
+}
+
+// unixSeconds returns t as fractional seconds since the Unix epoch.
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
@@ -0,0 +1,127 @@
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamingTestClient(t *testing.T, options ClientOptions) (*Client, *recordingEnvelopeTransport, context.Context) {
+	t.Helper()
+	transport := &recordingEnvelopeTransport{}
+	options.Dsn = "https://public@example.com/1"
+	options.EnvelopeTransport = transport
+	options.EnableTracing = true
+	options.StreamSpans = true
+	options.Integrations = func([]Integration) []Integration {
+		return nil
+	}
+	client, err := NewClient(options)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	return client, transport, SetHubOnContext(context.Background(), NewHub(client, NewScope()))
+}
+
+func TestStreamSpans(t *testing.T) {
+	client, transport, ctx := newStreamingTestClient(t, ClientOptions{
+		TracesSampleRate: 1.0,
+		Release:          "1.0.0",
+	})
+
+	transaction := StartTransaction(ctx, "job")
+	child := transaction.StartChild("db.query", WithDescription("SELECT 1"))
+	child.SetData("db.rows", 3)
+	child.SetTag("shard", "a")
+	child.Status = SpanStatusInternalError
+	child.Finish()
+
+	assert.Empty(t, transaction.recorder.children())
+	client.Flush(time.Second)
+	require.Equal(t, []EnvelopeItemType{EnvelopeItemTypeSpan}, transport.itemTypes())
+
+	envelope := transport.envelopes[0]
+	assert.Equal(t, transaction.TraceID.String(), envelope.Header.Trace["trace_id"])
+	item := envelope.Items[0]
+	require.NotNil(t, item.Header.ItemCount)
+	assert.Equal(t, 1, *item.Header.ItemCount)
+
+	var payload struct {
+		Items []struct {
+			TraceID      string `json:"trace_id"`
+			SpanID       string `json:"span_id"`
+			ParentSpanID string `json:"parent_span_id"`
+			Name         string `json:"name"`
+			Status       string `json:"status"`
+			Attributes   map[string]struct {
+				Type  string      `json:"type"`
+				Value interface{} `json:"value"`
+			} `json:"attributes"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(item.Payload, &payload))
+	require.Len(t, payload.Items, 1)
+	span := payload.Items[0]
+	assert.Equal(t, child.SpanID.String(), span.SpanID)
+	assert.Equal(t, transaction.SpanID.String(), span.ParentSpanID)
+	assert.Equal(t, "SELECT 1", span.Name)
+	assert.Equal(t, "error", span.Status)
+	assert.Equal(t, "db.query", span.Attributes["sentry.op"].Value)
+	assert.Equal(t, transaction.SpanID.String(), span.Attributes["sentry.segment.id"].Value)
+	assert.Equal(t, "job", span.Attributes["sentry.segment.name"].Value)
+	assert.Equal(t, "1.0.0", span.Attributes["sentry.release"].Value)
+	assert.Equal(t, "a", span.Attributes["shard"].Value)
+	assert.Equal(t, "integer", span.Attributes["db.rows"].Type)
+
+	transaction.Finish()
+	client.Flush(time.Second)
+	assert.Equal(t, []EnvelopeItemType{EnvelopeItemTypeSpan, EnvelopeItemTypeTransaction}, transport.itemTypes())
+
+	var event struct {
+		Spans []json.RawMessage `json:"spans"`
+	}
+	require.NoError(t, json.Unmarshal(transport.envelopes[1].Items[0].Payload, &event))
+	assert.Empty(t, event.Spans)
+}
+
+func TestStreamSpans_Unsampled(t *testing.T) {
+	client, transport, ctx := newStreamingTestClient(t, ClientOptions{
+		TracesSampleRate: 0.0,
+	})
+
+	transaction := StartTransaction(ctx, "job")
+	transaction.StartChild("a").Finish()
+	transaction.StartChild("b").Finish()
+	transaction.Finish()
+	client.Flush(time.Second)
+
+	assert.Empty(t, transport.itemTypes())
+	discarded := client.Stats().Discarded
+	assert.Contains(t, discarded, report.DiscardedEvent{Reason: report.ReasonSampleRate, Category: ratelimit.CategoryTransaction, Quantity: 1})
+	assert.Contains(t, discarded, report.DiscardedEvent{Reason: report.ReasonSampleRate, Category: ratelimit.CategorySpan, Quantity: 3})
+}
+
+func TestStreamSpans_IgnoredWithoutTelemetryBuffer(t *testing.T) {
+	transport := &MockTransport{}
+	client, err := NewClient(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		StreamSpans:      true,
+		Transport:        transport,
+	})
+	require.NoError(t, err)
+	ctx := SetHubOnContext(context.Background(), NewHub(client, NewScope()))
+
+	transaction := StartTransaction(ctx, "job")
+	transaction.StartChild("a").Finish()
+	transaction.Finish()
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	assert.Len(t, events[0].Spans, 1)
+}
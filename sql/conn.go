@@ -63,7 +63,7 @@ func (c *sentryConn) QueryContext(ctx context.Context, query string, args []driv
 func (c *sentryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (res driver.Result, err error) {
 	if ec, ok := c.conn.(driver.ExecerContext); ok {
 		span := startQuerySpan(ctx, c, c.cfg, opExec, query)
-		defer func() { finishSpan(span, err) }()
+		defer func() { finishExecSpan(span, res, err) }()
 		return ec.ExecContext(ctx, query, args)
 	}
 	ex, ok := c.conn.(driver.Execer) //nolint:staticcheck // legacy driver.Execer fallback is intentional.
@@ -80,7 +80,7 @@ func (c *sentryConn) ExecContext(ctx context.Context, query string, args []drive
 		return nil, ctx.Err()
 	}
 	span := startQuerySpan(ctx, c, c.cfg, opExec, query)
-	defer func() { finishSpan(span, err) }()
+	defer func() { finishExecSpan(span, res, err) }()
 	return ex.Exec(query, values)
 }
 
@@ -14,6 +14,10 @@ const (
 	opTransaction = "db.sql.transaction"
 )
 
+// measurementRowsAffected is the transaction measurement of the rows affected
+// by all statements executed in the transaction.
+const measurementRowsAffected = "db.rows_affected"
+
 // startQuerySpan creates a child span for a SQL operation only
 // when a parent span exists in the passed ctx.
 func startQuerySpan(ctx context.Context, conn *sentryConn, cfg *config, op, query string) *sentry.Span {
@@ -106,11 +110,14 @@ func sendDefaultPII(ctx context.Context) bool {
 }
 
 func finishSpan(span *sentry.Span, err error) {
+	if span == nil {
+		return
+	}
 	// driver.ErrSkip instructs the driver to fallback to the next available method. In these cases
-	// we get two or more spans for the same transaction, so to avoid duplication we don't finish
-	// these spans. Currently this works because the spanRecorder skips unfinished spans and doesn't add
-	// them to the transactions. We might need a span.Discard in the future if this behavior changes.
-	if span == nil || errors.Is(err, driver.ErrSkip) {
+	// we get two or more spans for the same transaction, so to avoid duplication we discard the
+	// span of the skipped method.
+	if errors.Is(err, driver.ErrSkip) {
+		span.Discard()
 		return
 	}
 	if err != nil {
@@ -120,3 +127,14 @@ func finishSpan(span *sentry.Span, err error) {
 	}
 	span.Finish()
 }
+
+// finishExecSpan finishes the span of an executed statement and adds the rows
+// it affected to the measurements of the enclosing transaction.
+func finishExecSpan(span *sentry.Span, res driver.Result, err error) {
+	if span != nil && err == nil && res != nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			span.AddMeasurement(measurementRowsAffected, float64(n), "")
+		}
+	}
+	finishSpan(span, err)
+}
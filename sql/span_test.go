@@ -102,6 +102,32 @@ func TestFinishSpan_StatusMapping(t *testing.T) {
 	})
 }
 
+type rowsResult int64
+
+func (rowsResult) LastInsertId() (int64, error)   { return 0, nil }
+func (r rowsResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestFinishExecSpan_AddsRowsAffected(t *testing.T) {
+	sentrytest.Run(t, func(t *testing.T, f *sentrytest.Fixture) {
+		ctx := f.NewContext(context.Background())
+		transaction := sentry.StartSpan(ctx, "root", sentry.WithTransactionName("root"))
+
+		cfg := &config{system: SystemPostgreSQL}
+		finishExecSpan(startQuerySpan(transaction.Context(), nil, cfg, opExec, "UPDATE t SET a = 1"), rowsResult(3), nil)
+		finishExecSpan(startQuerySpan(transaction.Context(), nil, cfg, opExec, "DELETE FROM t"), rowsResult(4), nil)
+		finishExecSpan(startQuerySpan(transaction.Context(), nil, cfg, opExec, "DELETE FROM t"), nil, errors.New("boom"))
+		transaction.Finish()
+
+		f.Flush()
+		events := f.Events()
+		require.Len(t, events, 1)
+		assert.Equal(t, map[string]sentry.Measurement{measurementRowsAffected: {Value: 7}}, events[0].Measurements)
+	}, sentrytest.WithClientOptions(sentry.ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+	}))
+}
+
 func TestStartQuerySpan_UsesTransactionParent(t *testing.T) {
 	sentrytest.Run(t, func(t *testing.T, f *sentrytest.Fixture) {
 		ctx := f.NewContext(context.Background())
@@ -36,7 +36,7 @@ func (s *sentryStmt) Query(args []driver.Value) (driver.Rows, error) {
 func (s *sentryStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (res driver.Result, err error) {
 	if ec, ok := s.stmt.(driver.StmtExecContext); ok {
 		span := startQuerySpan(ctx, s.conn, s.conn.cfg, opExec, s.query)
-		defer func() { finishSpan(span, err) }()
+		defer func() { finishExecSpan(span, res, err) }()
 		return ec.ExecContext(ctx, args)
 	}
 	values, cerr := namedValuesToValues(args)
@@ -49,7 +49,7 @@ func (s *sentryStmt) ExecContext(ctx context.Context, args []driver.NamedValue)
 		return nil, ctx.Err()
 	}
 	span := startQuerySpan(ctx, s.conn, s.conn.cfg, opExec, s.query)
-	defer func() { finishSpan(span, err) }()
+	defer func() { finishExecSpan(span, res, err) }()
 	return s.stmt.Exec(values) //nolint:staticcheck // legacy driver.Stmt.Exec fallback is intentional.
 }
 
@@ -0,0 +1,92 @@
+package sentry
+
+import (
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
+)
+
+// Stats is a snapshot of the state of a Client, to monitor whether the SDK
+// keeps up with the telemetry it receives.
+type Stats struct {
+	// Buffers holds the state of the telemetry buffer of every category. It
+	// is empty if DisableTelemetryBuffer is set.
+	Buffers map[ratelimit.Category]BufferStats
+	// Discarded counts all telemetry discarded since the Client was created,
+	// by reason and category. Attachments are counted in bytes.
+	Discarded []report.DiscardedEvent
+	// RateLimits maps the categories that Sentry currently rate limits to the
+	// time their limit expires. The empty category limits all categories.
+	RateLimits map[ratelimit.Category]time.Time
+	// QueueSize is the number of envelopes, or events for HTTPTransport,
+	// waiting to be sent by the transport.
+	QueueSize int
+	// QueueCapacity is the number of envelopes, or events for HTTPTransport,
+	// the transport queue can hold.
+	QueueCapacity int
+}
+
+// BufferStats is the state of the telemetry buffer of a category.
+type BufferStats struct {
+	// Size is the number of items in the buffer.
+	Size int
+	// Capacity is the number of items the buffer can hold.
+	Capacity int
+	// Utilization is Size divided by Capacity.
+	Utilization float64
+	// Offered is the number of items offered to the buffer.
+	Offered int64
+	// Dropped is the number of items dropped because the buffer was full.
+	Dropped int64
+	// DropRate is Dropped divided by Offered.
+	DropRate float64
+}
+
+// Stats returns a snapshot of the state of the client's buffers and
+// transport, and of the telemetry it discarded. Only the transport of the
+// client's own DSN is included, and custom transports report no queue or rate
+// limits.
+func (client *Client) Stats() Stats {
+	stats := Stats{
+		Buffers:   make(map[ratelimit.Category]BufferStats),
+		Discarded: client.discards.Totals(),
+	}
+	if client.telemetryProcessor != nil {
+		for category, metrics := range client.telemetryProcessor.BufferMetrics() {
+			stats.Buffers[category] = BufferStats{
+				Size:        metrics.Size,
+				Capacity:    metrics.Capacity,
+				Utilization: metrics.Utilization,
+				Offered:     metrics.OfferedCount,
+				Dropped:     metrics.DroppedCount,
+				DropRate:    metrics.DropRate,
+			}
+		}
+	}
+
+	transport := transportStats(client.Transport)
+	stats.RateLimits = transport.RateLimits
+	if stats.RateLimits == nil {
+		stats.RateLimits = make(map[ratelimit.Category]time.Time)
+	}
+	stats.QueueSize = transport.QueueSize
+	stats.QueueCapacity = transport.QueueCapacity
+	return stats
+}
+
+// statsProvider is implemented by the transports of the SDK that can report
+// their state.
+type statsProvider interface {
+	stats() protocol.TransportStats
+}
+
+// transportStats returns the stats of transport, or empty stats if it cannot
+// report them.
+func transportStats(transport Transport) protocol.TransportStats {
+	if provider, ok := transport.(statsProvider); ok {
+		return provider.stats()
+	}
+	return protocol.TransportStats{}
+}
@@ -0,0 +1,88 @@
+package sentry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Stats(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Sentry-Rate-Limits", "60:error:organization")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer srv.Close()
+
+		var mu sync.Mutex
+		var discards []report.DiscardedEvent
+		client, err := NewClient(ClientOptions{
+			Dsn:                    strings.Replace(srv.URL, "//", "//public@", 1) + "/1",
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+			DisableClientReports:   true,
+			BeforeSend: func(event *Event, _ *EventHint) *Event {
+				if event.Message == "drop" {
+					return nil
+				}
+				return event
+			},
+			OnDiscard: func(discard report.DiscardedEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				discards = append(discards, discard)
+			},
+			Integrations: func([]Integration) []Integration {
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		defer client.Close()
+
+		client.CaptureMessage("drop", nil, nil)
+		client.CaptureMessage("send", nil, nil)
+		client.Flush(time.Second)
+
+		stats := client.Stats()
+		beforeSend := report.DiscardedEvent{Reason: report.ReasonBeforeSend, Category: ratelimit.CategoryError, Quantity: 1}
+		assert.Contains(t, stats.Discarded, beforeSend)
+		mu.Lock()
+		assert.Contains(t, discards, beforeSend)
+		mu.Unlock()
+
+		require.Contains(t, stats.RateLimits, ratelimit.CategoryError)
+		assert.True(t, stats.RateLimits[ratelimit.CategoryError].After(time.Now()))
+		assert.Equal(t, 0, stats.QueueSize)
+		assert.Positive(t, stats.QueueCapacity)
+
+		if disableTelemetryBuffer {
+			assert.Empty(t, stats.Buffers)
+		} else {
+			buffer := stats.Buffers[ratelimit.CategoryError]
+			assert.Equal(t, int64(1), buffer.Offered)
+			assert.Positive(t, buffer.Capacity)
+		}
+
+		// Discards taken by a client report are still counted.
+		client.reportRecorder.RecordOne(report.ReasonQueueOverflow, ratelimit.CategoryError)
+		client.discards.TakeReport()
+		assert.Contains(t, client.Stats().Discarded, report.DiscardedEvent{Reason: report.ReasonQueueOverflow, Category: ratelimit.CategoryError, Quantity: 1})
+	})
+}
+
+func TestClient_StatsWithCustomTransport(t *testing.T) {
+	client, err := NewClient(ClientOptions{Transport: &MockTransport{}})
+	require.NoError(t, err)
+
+	stats := client.Stats()
+	assert.Empty(t, stats.RateLimits)
+	assert.Zero(t, stats.QueueSize)
+	assert.Zero(t, stats.QueueCapacity)
+}
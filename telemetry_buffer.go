@@ -0,0 +1,182 @@
+package sentry
+
+import (
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/telemetry"
+	"github.com/getsentry/sentry-go/report"
+)
+
+// OverflowPolicy selects which items a full telemetry buffer drops to make
+// room for a new one. Dropped items are reported in client reports with the
+// "buffer_overflow" reason.
+//
+// The buffers of transactions, logs, metrics and spans group items by trace,
+// so that the items of a trace are kept or dropped together.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest drops the oldest item in the buffer, or all items of
+	// the oldest trace in buffers grouped by trace. It is the default.
+	OverflowDropOldest OverflowPolicy = iota
+	// OverflowDropNewest drops the new item and keeps the buffer as it is.
+	OverflowDropNewest
+)
+
+func (p OverflowPolicy) internal() telemetry.OverflowPolicy {
+	if p == OverflowDropNewest {
+		return telemetry.OverflowPolicyDropNewest
+	}
+	return telemetry.OverflowPolicyDropOldest
+}
+
+// BufferOptions configures the telemetry buffer of a category. Zero values
+// use the defaults of the category.
+type BufferOptions struct {
+	// Capacity is the maximum number of items in the buffer.
+	Capacity int
+	// BatchSize is the number of items that are sent together once they are
+	// available. It is capped at Capacity.
+	BatchSize int
+	// FlushInterval is the maximum time items wait for a batch to fill up
+	// before they are sent. Defaults to 5 seconds if BatchSize is above 1.
+	FlushInterval time.Duration
+	// OverflowPolicy selects which item is dropped when the buffer is full.
+	OverflowPolicy OverflowPolicy
+}
+
+// PriorityWeights sets how often the telemetry buffer scheduler visits the
+// buffers of each priority, relative to each other. Errors have the critical
+// priority, check-ins the high priority, transactions and streamed spans the
+// medium priority, logs and metrics the low priority and profile chunks the
+// lowest priority. Zero values use the defaults: 5 for critical, 4 for high,
+// 3 for medium, 2 for low and 1 for lowest.
+type PriorityWeights struct {
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	Lowest   int
+}
+
+func (w PriorityWeights) internal() map[ratelimit.Priority]int {
+	weights := map[ratelimit.Priority]int{
+		ratelimit.PriorityCritical: w.Critical,
+		ratelimit.PriorityHigh:     w.High,
+		ratelimit.PriorityMedium:   w.Medium,
+		ratelimit.PriorityLow:      w.Low,
+		ratelimit.PriorityLowest:   w.Lowest,
+	}
+	for priority, weight := range weights {
+		if weight < 0 {
+			debuglog.Printf("Ignoring negative %s priority weight: %d", priority, weight)
+		}
+	}
+	return weights
+}
+
+// TelemetryBufferOptions configures the buffers that hold telemetry until it
+// is sent, unless DisableTelemetryBuffer is set.
+type TelemetryBufferOptions struct {
+	// Errors configures the buffer of error events. Defaults to a capacity
+	// of 100, sending every event immediately.
+	Errors BufferOptions
+	// Transactions configures the buffer of transactions. Defaults to a
+	// capacity of 1000, sending every transaction immediately.
+	Transactions BufferOptions
+	// Logs configures the buffer of logs. Defaults to a capacity of 1000,
+	// sending batches of 100 logs at least every 5 seconds.
+	Logs BufferOptions
+	// CheckIns configures the buffer of check-ins. Defaults to a capacity of
+	// 100, sending every check-in immediately.
+	CheckIns BufferOptions
+	// Metrics configures the buffer of metrics. Defaults to a capacity of
+	// 1000, sending batches of 100 metrics at least every 5 seconds.
+	Metrics BufferOptions
+	// Spans configures the buffer of spans sent with StreamSpans. Defaults to
+	// a capacity of 1000, sending batches of 100 spans at least every 5
+	// seconds.
+	Spans BufferOptions
+	// ProfileChunks configures the buffer of profile chunks sent with
+	// ContinuousProfiling. Defaults to a capacity of 10, sending every chunk
+	// immediately.
+	ProfileChunks BufferOptions
+	// PriorityWeights configures how the buffers are scheduled.
+	PriorityWeights PriorityWeights
+}
+
+// defaultBatchFlushInterval is the flush interval of categories that are sent
+// one by one by default, when a batch size is configured for them.
+const defaultBatchFlushInterval = 5 * time.Second
+
+// defaultBufferOptions are the defaults of the telemetry buffers by category.
+var defaultBufferOptions = map[ratelimit.Category]BufferOptions{
+	ratelimit.CategoryError:        {Capacity: 100, BatchSize: 1},
+	ratelimit.CategoryTransaction:  {Capacity: 1000, BatchSize: 1},
+	ratelimit.CategoryLog:          {Capacity: 1000, BatchSize: 100, FlushInterval: 5 * time.Second},
+	ratelimit.CategoryMonitor:      {Capacity: 100, BatchSize: 1},
+	ratelimit.CategoryTraceMetric:  {Capacity: 1000, BatchSize: 100, FlushInterval: 5 * time.Second},
+	ratelimit.CategorySpan:         {Capacity: 1000, BatchSize: 100, FlushInterval: 5 * time.Second},
+	ratelimit.CategoryProfileChunk: {Capacity: 10, BatchSize: 1},
+}
+
+// buffers returns a telemetry buffer for every category.
+func (o TelemetryBufferOptions) buffers(recorder report.ClientReportRecorder) map[ratelimit.Category]telemetry.Buffer[protocol.TelemetryItem] {
+	options := map[ratelimit.Category]BufferOptions{
+		ratelimit.CategoryError:        o.Errors,
+		ratelimit.CategoryTransaction:  o.Transactions,
+		ratelimit.CategoryLog:          o.Logs,
+		ratelimit.CategoryMonitor:      o.CheckIns,
+		ratelimit.CategoryTraceMetric:  o.Metrics,
+		ratelimit.CategorySpan:         o.Spans,
+		ratelimit.CategoryProfileChunk: o.ProfileChunks,
+	}
+	buffers := make(map[ratelimit.Category]telemetry.Buffer[protocol.TelemetryItem], len(options))
+	for category, opts := range options {
+		opts = opts.validate(category)
+		switch category {
+		case ratelimit.CategoryTransaction, ratelimit.CategoryLog, ratelimit.CategoryTraceMetric, ratelimit.CategorySpan:
+			// Keep or drop the items of a trace together, so that an overflow
+			// does not leave incomplete traces behind.
+			buffers[category] = telemetry.NewBucketedBuffer[protocol.TelemetryItem](category, opts.Capacity, opts.OverflowPolicy.internal(), opts.BatchSize, opts.FlushInterval, recorder)
+		default:
+			buffers[category] = telemetry.NewRingBuffer[protocol.TelemetryItem](category, opts.Capacity, opts.OverflowPolicy.internal(), opts.BatchSize, opts.FlushInterval, recorder)
+		}
+	}
+	return buffers
+}
+
+// validate replaces unset and invalid values with the defaults of the
+// category.
+func (o BufferOptions) validate(category ratelimit.Category) BufferOptions {
+	defaults := defaultBufferOptions[category]
+	if o.Capacity < 0 || o.BatchSize < 0 || o.FlushInterval < 0 {
+		debuglog.Printf("Ignoring negative %s buffer options: %+v", category, o)
+	}
+	if o.Capacity <= 0 {
+		o.Capacity = defaults.Capacity
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = min(defaults.BatchSize, o.Capacity)
+	}
+	if o.BatchSize > o.Capacity {
+		debuglog.Printf("Capping %s buffer batch size %d at its capacity %d", category, o.BatchSize, o.Capacity)
+		o.BatchSize = o.Capacity
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = defaults.FlushInterval
+	}
+	if o.FlushInterval == 0 && o.BatchSize > 1 {
+		// Without an interval, the last items would wait for a batch that
+		// never fills up.
+		o.FlushInterval = defaultBatchFlushInterval
+	}
+	if o.OverflowPolicy != OverflowDropOldest && o.OverflowPolicy != OverflowDropNewest {
+		debuglog.Printf("Ignoring unknown %s buffer overflow policy: %d", category, o.OverflowPolicy)
+		o.OverflowPolicy = OverflowDropOldest
+	}
+	return o
+}
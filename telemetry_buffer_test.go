@@ -0,0 +1,139 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
+	"github.com/getsentry/sentry-go/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		category ratelimit.Category
+		options  BufferOptions
+		want     BufferOptions
+	}{
+		{
+			name:     "defaults",
+			category: ratelimit.CategoryLog,
+			want:     BufferOptions{Capacity: 1000, BatchSize: 100, FlushInterval: 5 * time.Second},
+		},
+		{
+			name:     "custom",
+			category: ratelimit.CategoryLog,
+			options:  BufferOptions{Capacity: 5000, BatchSize: 500, FlushInterval: time.Second, OverflowPolicy: OverflowDropNewest},
+			want:     BufferOptions{Capacity: 5000, BatchSize: 500, FlushInterval: time.Second, OverflowPolicy: OverflowDropNewest},
+		},
+		{
+			name:     "negative values",
+			category: ratelimit.CategoryError,
+			options:  BufferOptions{Capacity: -1, BatchSize: -1, FlushInterval: -time.Second},
+			want:     BufferOptions{Capacity: 100, BatchSize: 1},
+		},
+		{
+			name:     "batch size above capacity",
+			category: ratelimit.CategoryTraceMetric,
+			options:  BufferOptions{Capacity: 10, BatchSize: 50},
+			want:     BufferOptions{Capacity: 10, BatchSize: 10, FlushInterval: 5 * time.Second},
+		},
+		{
+			name:     "default batch size above capacity",
+			category: ratelimit.CategoryLog,
+			options:  BufferOptions{Capacity: 10},
+			want:     BufferOptions{Capacity: 10, BatchSize: 10, FlushInterval: 5 * time.Second},
+		},
+		{
+			name:     "batch size without flush interval",
+			category: ratelimit.CategoryTransaction,
+			options:  BufferOptions{BatchSize: 10},
+			want:     BufferOptions{Capacity: 1000, BatchSize: 10, FlushInterval: 5 * time.Second},
+		},
+		{
+			name:     "unknown overflow policy",
+			category: ratelimit.CategoryError,
+			options:  BufferOptions{OverflowPolicy: 42},
+			want:     BufferOptions{Capacity: 100, BatchSize: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.options.validate(tt.category))
+		})
+	}
+}
+
+func TestTelemetryBufferOptions_Buffers(t *testing.T) {
+	options := TelemetryBufferOptions{
+		Errors: BufferOptions{Capacity: 10, OverflowPolicy: OverflowDropNewest},
+		Logs:   BufferOptions{Capacity: 5000},
+	}
+	buffers := options.buffers(nil)
+
+	assert.Len(t, buffers, len(defaultBufferOptions))
+	assert.Equal(t, 10, buffers[ratelimit.CategoryError].Capacity())
+	assert.Equal(t, 5000, buffers[ratelimit.CategoryLog].Capacity())
+	assert.Equal(t, 1000, buffers[ratelimit.CategoryTransaction].Capacity())
+
+	// A full drop-newest buffer rejects new items.
+	errorBuffer := buffers[ratelimit.CategoryError]
+	for i := 0; i < 10; i++ {
+		assert.True(t, errorBuffer.Offer(NewEvent()))
+	}
+	assert.False(t, errorBuffer.Offer(NewEvent()))
+}
+
+func TestPriorityWeights_Internal(t *testing.T) {
+	weights := PriorityWeights{Critical: 10, Low: -1}.internal()
+
+	assert.Equal(t, 10, weights[ratelimit.PriorityCritical])
+	assert.Equal(t, 0, weights[ratelimit.PriorityHigh])
+	assert.Equal(t, -1, weights[ratelimit.PriorityLow])
+	assert.Len(t, weights, len(telemetry.DefaultPriorityWeights))
+}
+
+func TestTelemetryBufferOptions_BuffersGroupByTrace(t *testing.T) {
+	buffers := TelemetryBufferOptions{}.buffers(nil)
+
+	for category, buffer := range buffers {
+		_, bucketed := buffer.(*telemetry.BucketedBuffer[protocol.TelemetryItem])
+		want := category != ratelimit.CategoryError && category != ratelimit.CategoryMonitor &&
+			category != ratelimit.CategoryProfileChunk
+		assert.Equal(t, want, bucketed, category)
+	}
+}
+
+func TestGetTraceID(t *testing.T) {
+	traceID := TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03")
+
+	transaction := NewEvent()
+	transaction.Contexts["trace"] = Context{"trace_id": traceID}
+	event := NewEvent()
+	event.sdkMetaData.dsc = DynamicSamplingContext{Entries: map[string]string{"trace_id": traceID.String()}}
+
+	tests := []struct {
+		name   string
+		item   telemetry.TraceAware
+		want   string
+		wantOK bool
+	}{
+		{"event without trace", NewEvent(), "", false},
+		{"event with trace context", transaction, traceID.String(), true},
+		{"event with dynamic sampling context", event, traceID.String(), true},
+		{"log", &Log{TraceID: traceID}, traceID.String(), true},
+		{"log without trace", &Log{}, "", false},
+		{"metric", &Metric{TraceID: traceID}, traceID.String(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.item.GetTraceID()
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sentry
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
@@ -9,6 +10,7 @@ import (
 	"maps"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +25,7 @@ const (
 	SentryTraceHeader   = "sentry-trace"
 	SentryBaggageHeader = "baggage"
 	TraceparentHeader   = "traceparent"
+	TracestateHeader    = "tracestate"
 )
 
 // SpanOrigin indicates what created a trace or a span. See: https://develop.sentry.dev/sdk/performance/trace-origin/
@@ -60,11 +63,15 @@ type Span struct { //nolint: maligned // prefer readability over optimal memory
 	Sampled      Sampled                `json:"-"`
 	Source       TransactionSource      `json:"-"`
 	Origin       SpanOrigin             `json:"origin,omitempty"`
+	Links        []SpanLink             `json:"links,omitempty"`
 
 	// mu protects concurrent writes to map fields
 	mu sync.RWMutex
 	// sample rate the span was sampled with.
 	sampleRate float64
+	// sampleRand is the random value of the trace the sampling decision of a
+	// transaction is based on.
+	sampleRand float64
 	// ctx is the context where the span was started. Always non-nil.
 	ctx context.Context
 	// Dynamic Sampling context
@@ -74,15 +81,32 @@ type Span struct { //nolint: maligned // prefer readability over optimal memory
 	parent *Span
 	// recorder stores all spans in a transaction. Guaranteed to be non-nil.
 	recorder *spanRecorder
+	// streamed is set on child spans that are sent when they finish, instead
+	// of being recorded in the transaction. See ClientOptions.StreamSpans.
+	streamed bool
 	// span context, can only be set on transactions
 	contexts map[string]Context
+	// transaction measurements, set on the root span by SetMeasurement
+	measurements map[string]Measurement
+	// autoFinish finishes a transaction started WithIdleTimeout or
+	// WithDeadline. Nil for other spans.
+	autoFinish *autoFinisher
+	// profile is the profiling state of a profiled transaction.
+	profile *spanProfile
+	// tracestate is the W3C tracestate header received with the trace the
+	// span continues. ToTracestate reads it from the transaction.
+	tracestate string
 	// a Once instance to make sure that Finish() is only called once.
 	finishOnce sync.Once
 	// explicitSampled is a flag for configuring sampling by using `WithSpanSampled` option.
 	explicitSampled Sampled
+	// downsampled is set on transactions that would have been sampled at the
+	// configured rate, but not at the rate lowered under backpressure.
+	downsampled bool
 	// Pre-serialized copies of mutable fields, set by MakeSerializationSafe.
 	serializedTags    json.RawMessage
 	serializedData    json.RawMessage
+	serializedLinks   json.RawMessage
 	serializationSafe bool
 }
 
@@ -202,7 +226,24 @@ func StartSpan(ctx context.Context, operation string, options ...SpanOption) *Sp
 		span.recorder = parent.spanRecorder()
 	}
 
-	span.recorder.record(&span)
+	// Streamed spans are sent on their own when they finish, so the
+	// transaction does not need to hold on to them.
+	if client := hubFromContext(ctx).Client(); hasParent && client != nil && client.streamsSpans() {
+		span.streamed = true
+	} else {
+		span.recorder.record(&span)
+	}
+	if hasParent {
+		if root := span.recorder.root(); root != nil && root.autoFinish != nil {
+			root.autoFinish.childStarted()
+		}
+	}
+	if span.autoFinish != nil {
+		span.autoFinish.start()
+	}
+	if client := hubFromContext(ctx).Client(); !hasParent && span.Sampled.Bool() && client != nil {
+		span.startProfiling(client)
+	}
 
 	clientOptions := span.clientOptions()
 	if clientOptions.EnableTracing {
@@ -214,7 +255,8 @@ func StartSpan(ctx context.Context, operation string, options ...SpanOption) *Sp
 }
 
 // Finish sets the span's end time, unless already set. If the span is the root
-// of a span tree, Finish sends the span tree to Sentry as a transaction.
+// of a span tree, Finish sends the span tree to Sentry as a transaction. With
+// ClientOptions.StreamSpans, Finish sends child spans to Sentry on their own.
 //
 // The logic is executed at most once per span, so that (incorrectly) calling it twice
 // never double sends to Sentry.
@@ -222,6 +264,25 @@ func (s *Span) Finish() {
 	s.finishOnce.Do(s.doFinish)
 }
 
+// Discard ends a child span without sending it, for example when the
+// operation it describes is retried in another span. The span is removed from
+// its transaction and later calls to Finish have no effect.
+//
+// Discard has no effect on transactions and on finished spans.
+func (s *Span) Discard() {
+	if s.IsTransaction() {
+		return
+	}
+	s.finishOnce.Do(func() {
+		hub := hubFromContext(s.ctx)
+		hub.Scope().SetSpan(s.parent)
+		if root := s.recorder.root(); root != nil && root.autoFinish != nil {
+			root.autoFinish.childFinished()
+		}
+		s.recorder.remove(s)
+	})
+}
+
 // Context returns the context containing the span.
 func (s *Span) Context() context.Context { return s.ctx }
 
@@ -248,6 +309,11 @@ func (s *Span) makeSerializationSafe() {
 			s.serializedData = b
 		}
 	}
+	if len(s.Links) > 0 {
+		if b, err := json.Marshal(s.Links); err == nil {
+			s.serializedLinks = b
+		}
+	}
 
 	s.serializationSafe = true
 }
@@ -258,6 +324,7 @@ func (s *Span) MarshalJSON() ([]byte, error) {
 	serializationSafe := s.serializationSafe
 	serializedTags := s.serializedTags
 	serializedData := s.serializedData
+	serializedLinks := s.serializedLinks
 	s.mu.RUnlock()
 
 	if !serializationSafe {
@@ -268,13 +335,15 @@ func (s *Span) MarshalJSON() ([]byte, error) {
 	type span Span
 	type safeSpan struct {
 		*span
-		Tags json.RawMessage `json:"tags,omitempty"`
-		Data json.RawMessage `json:"data,omitempty"`
+		Tags  json.RawMessage `json:"tags,omitempty"`
+		Data  json.RawMessage `json:"data,omitempty"`
+		Links json.RawMessage `json:"links,omitempty"`
 	}
 	return json.Marshal(safeSpan{
-		span: (*span)(s),
-		Tags: serializedTags,
-		Data: serializedData,
+		span:  (*span)(s),
+		Tags:  serializedTags,
+		Data:  serializedData,
+		Links: serializedLinks,
 	})
 }
 
@@ -381,6 +450,18 @@ func (s *Span) ToTraceparent() string {
 	return fmt.Sprintf("00-%s-%s-%s", s.TraceID.String(), s.SpanID.String(), traceFlags)
 }
 
+// ToTracestate returns the W3C tracestate header value received with the
+// trace of the span, to be propagated along with ToTraceparent. It is empty
+// unless the transaction of the span continued a trace from a tracestate
+// header.
+func (s *Span) ToTracestate() string {
+	t := s.GetTransaction()
+	if t == nil {
+		return ""
+	}
+	return t.tracestate
+}
+
 // ToBaggage returns the serialized DynamicSamplingContext from a transaction.
 // Use this function to propagate the DynamicSamplingContext to a downstream SDK,
 // either as the value of the "baggage" HTTP header, or as an html "baggage" meta tag.
@@ -469,10 +550,21 @@ func (s *Span) doFinish() {
 	}
 
 	hub := hubFromContext(s.ctx)
+	var profileContext Context
+	var profile *transactionProfile
 	if !s.IsTransaction() {
 		if s.parent != nil {
 			hub.Scope().SetSpan(s.parent)
 		}
+		if root := s.recorder.root(); root != nil && root.autoFinish != nil {
+			root.autoFinish.childFinished()
+		}
+	} else {
+		if s.autoFinish != nil {
+			s.autoFinish.stop()
+		}
+		s.finishOpenChildren()
+		profileContext, profile = s.finishProfiling(hub.Client())
 	}
 
 	if s.shouldIgnoreStatusCode() {
@@ -482,14 +574,34 @@ func (s *Span) doFinish() {
 	if !s.Sampled.Bool() {
 		c := hub.Client()
 		if c != nil {
+			reason := report.ReasonSampleRate
+			if root := s.recorder.root(); root != nil && root.downsampled {
+				reason = report.ReasonBackpressure
+			}
+			if s.streamed {
+				// streamed spans are not recorded, so the transaction root cannot count them
+				c.reportRecorder.RecordOne(reason, ratelimit.CategorySpan)
+				return
+			}
 			if !s.IsTransaction() {
 				// we count the sampled spans from the transaction root. it is guaranteed that the whole transaction
 				// would be sampled
 				return
 			}
 			children := s.recorder.children()
-			c.reportRecorder.RecordOne(report.ReasonSampleRate, ratelimit.CategoryTransaction)
-			c.reportRecorder.Record(report.ReasonSampleRate, ratelimit.CategorySpan, int64(len(children)+1))
+			c.reportRecorder.RecordOne(reason, ratelimit.CategoryTransaction)
+			c.reportRecorder.Record(reason, ratelimit.CategorySpan, int64(len(children)+1))
+			if c.spotlightIgnoresSampling() {
+				if event := s.toEvent(); event != nil {
+					c.mirrorSampledOut(event, nil, hub.Scope())
+				}
+			}
+		}
+		return
+	}
+	if s.streamed {
+		if c := hub.Client(); c != nil {
+			c.captureSpan(s)
 		}
 		return
 	}
@@ -497,6 +609,10 @@ func (s *Span) doFinish() {
 	if event == nil {
 		return
 	}
+	if profileContext != nil {
+		event.Contexts["profile"] = profileContext
+		event.sdkMetaData.transactionProfile = profile
+	}
 
 	// TODO(tracing): add breadcrumbs
 	// (see https://github.com/getsentry/sentry-python/blob/f6f3525f8812f609/sentry_sdk/tracing.py#L372)
@@ -515,26 +631,77 @@ func (s *Span) doFinish() {
 //	[[:xdigit:]]{32}-[[:xdigit:]]{16}-[01]
 var sentryTracePattern = regexp.MustCompile(`^([[:xdigit:]]{32})-([[:xdigit:]]{16})(?:-([01]))?$`)
 
-// updateFromSentryTrace parses a sentry-trace HTTP header (as returned by
-// ToSentryTrace) and updates fields of the span. If the header cannot be
-// recognized as valid, the span is left unchanged. The returned value indicates
-// whether the span was updated.
-func (s *Span) updateFromSentryTrace(header []byte) (updated bool) {
+// w3cTraceparentPattern matches a W3C traceparent header
+//
+//	VERSION - TRACE_ID - PARENT_ID - FLAGS
+//	[[:xdigit:]]{2}-[[:xdigit:]]{32}-[[:xdigit:]]{16}-[[:xdigit:]]{2}
+//
+// Versions after 00 may append fields, which are ignored.
+var w3cTraceparentPattern = regexp.MustCompile(`^([[:xdigit:]]{2})-([[:xdigit:]]{32})-([[:xdigit:]]{16})-([[:xdigit:]]{2})(-.*)?$`)
+
+// parseW3CTraceparent parses a W3C traceparent header (as returned by
+// ToTraceparent). The sampled flag of the header maps to SampledTrue or
+// SampledFalse. The returned value indicates whether the header was valid.
+//
+// See https://www.w3.org/TR/trace-context/#traceparent-header.
+func parseW3CTraceparent(header []byte) (traceParentContext TraceParentContext, valid bool) {
+	m := w3cTraceparentPattern.FindSubmatch(bytes.ToLower(header))
+	if m == nil {
+		return TraceParentContext{}, false
+	}
+	version := string(m[1])
+	if version == "ff" || (version == "00" && len(m[5]) != 0) {
+		return TraceParentContext{}, false
+	}
+	_, _ = hex.Decode(traceParentContext.TraceID[:], m[2])
+	_, _ = hex.Decode(traceParentContext.ParentSpanID[:], m[3])
+	if traceParentContext.TraceID == zeroTraceID || traceParentContext.ParentSpanID == zeroSpanID {
+		return TraceParentContext{}, false
+	}
+	var flags [1]byte
+	_, _ = hex.Decode(flags[:], m[4])
+	traceParentContext.Sampled = SampledFalse
+	if flags[0]&0x01 != 0 {
+		traceParentContext.Sampled = SampledTrue
+	}
+	return traceParentContext, true
+}
+
+// parseSentryTrace parses a sentry-trace header (as returned by
+// ToSentryTrace). The returned value indicates whether the header was valid.
+func parseSentryTrace(header []byte) (traceParentContext TraceParentContext, valid bool) {
 	m := sentryTracePattern.FindSubmatch(header)
 	if m == nil {
 		// no match
-		return false
+		return TraceParentContext{}, false
 	}
-	_, _ = hex.Decode(s.TraceID[:], m[1])
-	_, _ = hex.Decode(s.ParentSpanID[:], m[2])
+	_, _ = hex.Decode(traceParentContext.TraceID[:], m[1])
+	_, _ = hex.Decode(traceParentContext.ParentSpanID[:], m[2])
 	if len(m[3]) != 0 {
 		switch m[3][0] {
 		case '0':
-			s.Sampled = SampledFalse
+			traceParentContext.Sampled = SampledFalse
 		case '1':
-			s.Sampled = SampledTrue
+			traceParentContext.Sampled = SampledTrue
 		}
 	}
+	return traceParentContext, true
+}
+
+// updateFromTraceHeader parses a sentry-trace or W3C traceparent header (see
+// ParseTraceParentContext) and updates fields of the span. If the header
+// cannot be recognized as valid, the span is left unchanged. The returned
+// value indicates whether the span was updated.
+func (s *Span) updateFromTraceHeader(header []byte) (updated bool) {
+	tpc, valid := ParseTraceParentContext(header)
+	if !valid {
+		return false
+	}
+	s.TraceID = tpc.TraceID
+	s.ParentSpanID = tpc.ParentSpanID
+	if tpc.Sampled != SampledUndefined {
+		s.Sampled = tpc.Sampled
+	}
 	return true
 }
 
@@ -558,6 +725,10 @@ func (s *Span) clientOptions() *ClientOptions {
 }
 
 func (s *Span) sample() Sampled {
+	if s.IsTransaction() {
+		s.sampleRand = s.resolveSampleRand()
+	}
+
 	clientOptions := s.clientOptions()
 	// https://develop.sentry.dev/sdk/performance/#sampling
 	// #1 tracing is not enabled.
@@ -609,7 +780,8 @@ func (s *Span) sample() Sampled {
 	}
 
 	if sampler != nil {
-		tracesSamplerSampleRate := sampler.Sample(samplingContext)
+		configuredSampleRate := sampler.Sample(samplingContext)
+		tracesSamplerSampleRate := s.downsample(configuredSampleRate)
 		s.sampleRate = tracesSamplerSampleRate
 		// tracesSampler can update the sample_rate on frozen DSC
 		if s.dynamicSamplingContext.HasEntries() {
@@ -624,12 +796,7 @@ func (s *Span) sample() Sampled {
 			return SampledFalse
 		}
 
-		if rng.Float64() < tracesSamplerSampleRate {
-			return SampledTrue
-		}
-		debuglog.Printf("Dropping transaction: TracesSampler returned rate: %f", tracesSamplerSampleRate)
-
-		return SampledFalse
+		return s.sampleAt(configuredSampleRate, tracesSamplerSampleRate)
 	}
 
 	// #4 inherit parent decision.
@@ -645,7 +812,7 @@ func (s *Span) sample() Sampled {
 	}
 
 	// #5 use TracesSampleRate from ClientOptions.
-	sampleRate := clientOptions.TracesSampleRate
+	sampleRate := s.downsample(clientOptions.TracesSampleRate)
 	s.sampleRate = sampleRate
 	// tracesSampleRate can update the sample_rate on frozen DSC
 	if s.dynamicSamplingContext.HasEntries() {
@@ -660,10 +827,61 @@ func (s *Span) sample() Sampled {
 		return SampledFalse
 	}
 
-	if rng.Float64() < sampleRate {
-		return SampledTrue
+	return s.sampleAt(clientOptions.TracesSampleRate, sampleRate)
+}
+
+// resolveSampleRand returns the sample_rand of the trace a transaction
+// belongs to: the one propagated in its baggage, the one of the scope's
+// propagation context for the same trace, or a new one, consistent with the
+// sampling decision of a remote parent. A propagated dynamic sampling context
+// that lacks a sample_rand is completed with it.
+func (s *Span) resolveSampleRand() float64 {
+	if sampleRand, ok := s.dynamicSamplingContext.sampleRand(); ok {
+		return sampleRand
+	}
+
+	var sampleRand float64
+	if scope := hubFromContext(s.ctx).Scope(); scope != nil && scope.propagationContextSnapshot().TraceID == s.TraceID {
+		sampleRand = scope.propagationContextSnapshot().SampleRand
+	} else if s.ParentSpanID != zeroSpanID {
+		sampleRate, ok := s.dynamicSamplingContext.sampleRate()
+		sampleRand = newSampleRand(s.Sampled, sampleRate, ok)
+	} else {
+		sampleRand = newSampleRand(SampledUndefined, 0, false)
+	}
+
+	if s.dynamicSamplingContext.HasEntries() {
+		s.dynamicSamplingContext.Entries["sample_rand"] = formatSampleRand(sampleRand)
+	}
+	return sampleRand
+}
+
+// downsample lowers a valid sample rate while the client is under
+// backpressure. See ClientOptions.DisableBackpressureHandling.
+func (s *Span) downsample(sampleRate float64) float64 {
+	if sampleRate <= 0.0 || sampleRate > 1.0 {
+		return sampleRate
+	}
+	client := hubFromContext(s.ctx).Client()
+	if client == nil {
+		return sampleRate
 	}
+	return client.backpressure.sampleRate(ratelimit.CategoryTransaction, sampleRate)
+}
 
+// sampleAt makes a sampling decision at the downsampled rate, and marks the
+// span as downsampled if it would have been sampled at the configured rate.
+func (s *Span) sampleAt(configuredSampleRate, sampleRate float64) Sampled {
+	r := s.sampleRand
+	if r < sampleRate {
+		return SampledTrue
+	}
+	if r < configuredSampleRate {
+		debuglog.Printf("Dropping transaction: sample rate lowered from %f to %f under backpressure", configuredSampleRate, sampleRate)
+		s.downsampled = true
+		return SampledFalse
+	}
+	debuglog.Printf("Dropping transaction: sampled out at rate %f", sampleRate)
 	return SampledFalse
 }
 
@@ -704,13 +922,17 @@ func (s *Span) toEvent() *Event {
 	}
 
 	return &Event{
-		Type:        transactionType,
-		Transaction: s.Name,
-		Contexts:    contexts,
-		Tags:        maps.Clone(s.Tags),
-		Timestamp:   s.EndTime,
-		StartTime:   s.StartTime,
-		Spans:       finished,
+		// The event ID is set here, so that it can be linked to from the
+		// transaction profile.
+		EventID:      EventID(uuid()),
+		Type:         transactionType,
+		Transaction:  s.Name,
+		Contexts:     contexts,
+		Tags:         maps.Clone(s.Tags),
+		Timestamp:    s.EndTime,
+		StartTime:    s.StartTime,
+		Spans:        finished,
+		Measurements: maps.Clone(s.measurements),
 		TransactionInfo: &TransactionInfo{
 			Source: transactionSource,
 		},
@@ -734,6 +956,7 @@ func (s *Span) traceContext() *TraceContext {
 		Data:         maps.Clone(s.Data),
 		Description:  s.Description,
 		Status:       s.Status,
+		Links:        slices.Clone(s.Links),
 	}
 }
 
@@ -749,6 +972,7 @@ func (s *Span) traceContextLockFree() *TraceContext {
 		Data:         s.Data,
 		Description:  s.Description,
 		Status:       s.Status,
+		Links:        s.Links,
 	}
 }
 
@@ -756,20 +980,15 @@ func (s *Span) traceContextLockFree() *TraceContext {
 func (s *Span) spanRecorder() *spanRecorder { return s.recorder }
 
 // ParseTraceParentContext parses a sentry-trace header and builds a TraceParentContext from the
-// parsed values. If the header was parsed correctly, the second returned argument
-// ("valid") will be set to true, otherwise (e.g., empty or malformed header) it will
-// be false.
+// parsed values. Headers that are not a valid sentry-trace value are parsed as a W3C traceparent
+// header, as sent by OpenTelemetry instrumented services. If the header was parsed correctly, the
+// second returned argument ("valid") will be set to true, otherwise (e.g., empty or malformed
+// header) it will be false.
 func ParseTraceParentContext(header []byte) (traceParentContext TraceParentContext, valid bool) {
-	s := Span{}
-	updated := s.updateFromSentryTrace(header)
-	if !updated {
-		return TraceParentContext{}, false
+	if traceParentContext, valid = parseSentryTrace(header); valid {
+		return traceParentContext, true
 	}
-	return TraceParentContext{
-		TraceID:      s.TraceID,
-		ParentSpanID: s.ParentSpanID,
-		Sampled:      s.Sampled,
-	}, true
+	return parseW3CTraceparent(header)
 }
 
 // TraceID identifies a trace.
@@ -929,6 +1148,7 @@ type TraceContext struct {
 	Description  string                 `json:"description,omitempty"`
 	Status       SpanStatus             `json:"status,omitempty"`
 	Data         map[string]interface{} `json:"data,omitempty"`
+	Links        []SpanLink             `json:"links,omitempty"`
 }
 
 func (tc TraceContext) Map() map[string]interface{} {
@@ -957,6 +1177,10 @@ func (tc TraceContext) Map() map[string]interface{} {
 		m["data"] = tc.Data
 	}
 
+	if len(tc.Links) > 0 {
+		m["links"] = tc.Links
+	}
+
 	return m
 }
 
@@ -1043,38 +1267,73 @@ func WithSpanOrigin(origin SpanOrigin) SpanOption {
 }
 
 // ContinueTrace continues a trace based on traceparent and baggage values.
+// The traceparent value is either a sentry-trace or a W3C traceparent header.
 // If the SDK is configured with tracing enabled,
 // this function returns populated SpanOption.
 // In any other cases, it populates the propagation context on the scope.
 func ContinueTrace(hub *Hub, traceparent, baggage string) SpanOption {
+	return continueTrace(hub, traceparent, baggage, "")
+}
+
+// ContinueTraceFromHeaders continues a trace like ContinueTrace, from the
+// headers of a received request or message, as returned by getHeader. The
+// sentry-trace header is preferred, the W3C traceparent header is used if it
+// is missing or invalid. The W3C tracestate header is kept, to be propagated
+// downstream along with the traceparent header.
+func ContinueTraceFromHeaders(hub *Hub, getHeader func(key string) string) SpanOption {
+	return continueTrace(hub, traceHeader(getHeader), getHeader(SentryBaggageHeader), getHeader(TracestateHeader))
+}
+
+func continueTrace(hub *Hub, trace, baggage, tracestate string) SpanOption {
 	scope := hub.Scope()
-	propagationContext, _ := PropagationContextFromHeaders(traceparent, baggage)
+	propagationContext, _ := PropagationContextFromHeaders(trace, baggage)
 	client := hub.Client()
 
 	if !shouldContinueTrace(client, propagationContext.DynamicSamplingContext) {
 		propagationContext = NewPropagationContext()
-		traceparent = ""
+		trace = ""
 		baggage = ""
+		tracestate = ""
+	}
+	if propagationContext.ParentSpanID != zeroSpanID {
+		propagationContext.Tracestate = tracestate
 	}
 
 	scope.SetPropagationContext(propagationContext)
-	return ContinueFromHeaders(traceparent, baggage)
+	return continueFromHeaders(trace, baggage, tracestate)
+}
+
+// traceHeader returns the sentry-trace header, or the W3C traceparent header
+// if the sentry-trace header is missing or invalid.
+func traceHeader(getHeader func(key string) string) string {
+	trace := getHeader(SentryTraceHeader)
+	if _, valid := parseSentryTrace([]byte(trace)); !valid {
+		if traceparent := getHeader(TraceparentHeader); traceparent != "" {
+			return traceparent
+		}
+	}
+	return trace
 }
 
 // ContinueFromRequest returns a span option that updates the span to continue
 // an existing trace. If it cannot detect an existing trace in the request, the
 // span will be left unchanged.
 //
-// ContinueFromRequest is an alias for:
-//
-// ContinueFromHeaders(r.Header.Get(SentryTraceHeader), r.Header.Get(SentryBaggageHeader)).
+// The trace is continued from the sentry-trace header, or from the W3C
+// traceparent header if it is missing, and the baggage header. The W3C
+// tracestate header is kept, to be propagated downstream.
 func ContinueFromRequest(r *http.Request) SpanOption {
-	return ContinueFromHeaders(r.Header.Get(SentryTraceHeader), r.Header.Get(SentryBaggageHeader))
+	return continueFromHeaders(traceHeader(r.Header.Get), r.Header.Get(SentryBaggageHeader), r.Header.Get(TracestateHeader))
 }
 
 // ContinueFromHeaders returns a span option that updates the span to continue
-// an existing TraceID and propagates the Dynamic Sampling context.
+// an existing TraceID and propagates the Dynamic Sampling context. The trace
+// value is either a sentry-trace or a W3C traceparent header.
 func ContinueFromHeaders(trace, baggage string) SpanOption {
+	return continueFromHeaders(trace, baggage, "")
+}
+
+func continueFromHeaders(trace, baggage, tracestate string) SpanOption {
 	return func(s *Span) {
 		if trace == "" {
 			return
@@ -1094,7 +1353,9 @@ func ContinueFromHeaders(trace, baggage string) SpanOption {
 			return // leave span unchanged → behaves as head of trace
 		}
 
-		s.updateFromSentryTrace([]byte(trace))
+		if s.updateFromTraceHeader([]byte(trace)) {
+			s.tracestate = tracestate
+		}
 
 		if baggage != "" {
 			s.updateFromBaggage([]byte(baggage))
@@ -1111,7 +1372,7 @@ func ContinueFromHeaders(trace, baggage string) SpanOption {
 }
 
 // ContinueFromTrace returns a span option that updates the span to continue
-// an existing TraceID.
+// an existing TraceID, from a sentry-trace or W3C traceparent header.
 func ContinueFromTrace(trace string) SpanOption {
 	return func(s *Span) {
 		if trace == "" {
@@ -1121,7 +1382,7 @@ func ContinueFromTrace(trace string) SpanOption {
 		if !shouldContinueTrace(client, DynamicSamplingContext{}) {
 			return
 		}
-		s.updateFromSentryTrace([]byte(trace))
+		s.updateFromTraceHeader([]byte(trace))
 	}
 }
 
@@ -508,6 +508,93 @@ func TestContinueSpanFromRequest(t *testing.T) {
 	}
 }
 
+func TestContinueSpanFromRequest_Traceparent(t *testing.T) {
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+	})
+	header := http.Header{}
+	header.Set(TraceparentHeader, "00-bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-01")
+	header.Set(TracestateHeader, "vendor=value,other=1")
+
+	transaction := StartTransaction(ctx, "transaction", ContinueFromRequest(&http.Request{Header: header}))
+	defer transaction.Finish()
+	child := transaction.StartChild("child")
+	defer child.Finish()
+
+	assert.Equal(t, TraceIDFromHex("bc6d53f15eb88f4320054569b8c553d4"), transaction.TraceID)
+	assert.Equal(t, SpanIDFromHex("b72fa28504b07285"), transaction.ParentSpanID)
+	assert.Equal(t, SampledTrue, transaction.Sampled)
+	assert.Equal(t, "vendor=value,other=1", transaction.ToTracestate())
+	assert.Equal(t, "vendor=value,other=1", child.ToTracestate())
+	assert.Equal(t, "00-bc6d53f15eb88f4320054569b8c553d4-"+child.SpanID.String()+"-01", child.ToTraceparent())
+}
+
+func TestContinueTraceFromHeaders(t *testing.T) {
+	tests := map[string]struct {
+		header          http.Header
+		wantParentSpan  SpanID
+		wantSampled     Sampled
+		wantTracestate  string
+		wantTraceparent string
+	}{
+		"sentry-trace preferred": {
+			header: http.Header{
+				"Sentry-Trace": {"bc6d53f15eb88f4320054569b8c553d4-b72fa28504b07285-0"},
+				"Traceparent":  {"00-bc6d53f15eb88f4320054569b8c553d4-1cc4b26ab9094ef0-01"},
+				"Tracestate":   {"vendor=value"},
+			},
+			wantParentSpan: SpanIDFromHex("b72fa28504b07285"),
+			wantSampled:    SampledFalse,
+			wantTracestate: "vendor=value",
+		},
+		"invalid sentry-trace": {
+			header: http.Header{
+				"Sentry-Trace": {"invalid"},
+				"Traceparent":  {"00-bc6d53f15eb88f4320054569b8c553d4-1cc4b26ab9094ef0-01"},
+			},
+			wantParentSpan: SpanIDFromHex("1cc4b26ab9094ef0"),
+			wantSampled:    SampledTrue,
+		},
+		"traceparent only": {
+			header: http.Header{
+				"Traceparent": {"00-bc6d53f15eb88f4320054569b8c553d4-1cc4b26ab9094ef0-01"},
+				"Tracestate":  {"vendor=value"},
+			},
+			wantParentSpan: SpanIDFromHex("1cc4b26ab9094ef0"),
+			wantSampled:    SampledTrue,
+			wantTracestate: "vendor=value",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Without tracing, the trace is continued by the propagation
+			// context of the scope.
+			ctx := NewTestContext(ClientOptions{})
+			hub := GetHubFromContext(ctx)
+			ContinueTraceFromHeaders(hub, tt.header.Get)
+
+			traceID := TraceIDFromHex("bc6d53f15eb88f4320054569b8c553d4")
+			p := hub.Scope().propagationContextSnapshot()
+			assert.Equal(t, traceID, p.TraceID)
+			assert.Equal(t, tt.wantParentSpan, p.ParentSpanID)
+			assert.Equal(t, tt.wantSampled, p.Sampled)
+			assert.Equal(t, tt.wantTracestate, hub.GetTracestate())
+
+			// Both trace headers identify the same trace, span and sampling
+			// decision.
+			flags := "00"
+			if tt.wantSampled == SampledTrue {
+				flags = "01"
+			}
+			assert.Equal(t, "00-"+traceID.String()+"-"+p.SpanID.String()+"-"+flags, hub.GetTraceparentW3C())
+			tpc, valid := ParseTraceParentContext([]byte(hub.GetTraceparent()))
+			assert.True(t, valid)
+			assert.Equal(t, TraceParentContext{TraceID: traceID, ParentSpanID: p.SpanID, Sampled: tt.wantSampled}, tpc)
+		})
+	}
+}
+
 func TestContinueTransactionFromHeaders(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -926,6 +1013,69 @@ func TestSampleRatePropagation(t *testing.T) {
 	}
 }
 
+func TestSampleRand(t *testing.T) {
+	const traceID = "423d7a0fb16128c8503f067d8447caba"
+
+	t.Run("Propagated sample_rand decides", func(t *testing.T) {
+		for _, tt := range []struct {
+			sampleRand string
+			want       Sampled
+		}{
+			{"0.300000", SampledTrue},
+			{"0.700000", SampledFalse},
+		} {
+			ctx := NewTestContext(ClientOptions{EnableTracing: true, TracesSampleRate: 0.5})
+			transaction := StartTransaction(ctx, "name", ContinueFromHeaders(
+				traceID+"-d9246d56c61fc963",
+				"sentry-trace_id="+traceID+",sentry-sample_rand="+tt.sampleRand,
+			))
+			assertEqual(t, transaction.Sampled, tt.want)
+			assertBaggageStringsEqual(t, transaction.ToBaggage(), "sentry-sample_rand="+tt.sampleRand+",sentry-sample_rate=0.5,sentry-trace_id="+traceID)
+		}
+	})
+
+	t.Run("Missing sample_rand is consistent with the parent", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			ctx := NewTestContext(ClientOptions{EnableTracing: true})
+			sampled := StartTransaction(ctx, "name", ContinueFromHeaders(
+				traceID+"-d9246d56c61fc963-1",
+				"sentry-trace_id="+traceID+",sentry-sample_rate=0.25",
+			))
+			if sampled.sampleRand >= 0.25 {
+				t.Fatalf("sample_rand = %f, want < 0.25 for a sampled parent", sampled.sampleRand)
+			}
+			assertEqual(t, sampled.dynamicSamplingContext.Entries["sample_rand"], formatSampleRand(sampled.sampleRand))
+
+			unsampled := StartTransaction(ctx, "name", ContinueFromHeaders(
+				traceID+"-d9246d56c61fc963-0",
+				"sentry-trace_id="+traceID+",sentry-sample_rate=0.25",
+			))
+			if unsampled.sampleRand < 0.25 || unsampled.sampleRand >= 1 {
+				t.Fatalf("sample_rand = %f, want in [0.25, 1) for an unsampled parent", unsampled.sampleRand)
+			}
+		}
+	})
+
+	t.Run("Head of trace", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			ctx := NewTestContext(ClientOptions{EnableTracing: true, TracesSampleRate: 0.5})
+			transaction := StartTransaction(ctx, "name")
+			if transaction.sampleRand < 0 || transaction.sampleRand >= 1 {
+				t.Fatalf("sample_rand = %f, want in [0, 1)", transaction.sampleRand)
+			}
+			assertEqual(t, transaction.Sampled.Bool(), transaction.sampleRand < 0.5)
+			assertEqual(t, DynamicSamplingContextFromTransaction(transaction).Entries["sample_rand"], formatSampleRand(transaction.sampleRand))
+		}
+	})
+
+	t.Run("Continued trace shares the propagation context", func(t *testing.T) {
+		ctx := NewTestContext(ClientOptions{EnableTracing: true, TracesSampleRate: 1.0})
+		hub := GetHubFromContext(ctx)
+		transaction := StartTransaction(ctx, "name", ContinueTrace(hub, traceID+"-d9246d56c61fc963", ""))
+		assertEqual(t, transaction.sampleRand, hub.Scope().propagationContextSnapshot().SampleRand)
+	})
+}
+
 func TestTracesSamplerReceivesRemoteParent(t *testing.T) {
 	t.Parallel()
 
@@ -1069,6 +1219,32 @@ func TestParseTraceParentContext(t *testing.T) {
 			},
 			wantValid: true,
 		},
+		{
+			name:        "W3C traceparent, sampled",
+			sentryTrace: "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-01",
+			wantContext: TraceParentContext{
+				TraceID:      TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				ParentSpanID: SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled:      SampledTrue,
+			},
+			wantValid: true,
+		},
+		{
+			name:        "W3C traceparent, unsampled",
+			sentryTrace: "00-d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-00",
+			wantContext: TraceParentContext{
+				TraceID:      TraceIDFromHex("d49d9bf66f13450b81f65bc51cf49c03"),
+				ParentSpanID: SpanIDFromHex("1cc4b26ab9094ef0"),
+				Sampled:      SampledFalse,
+			},
+			wantValid: true,
+		},
+		{
+			name:        "W3C traceparent, invalid trace ID",
+			sentryTrace: "00-00000000000000000000000000000000-1cc4b26ab9094ef0-01",
+			wantContext: TraceParentContext{},
+			wantValid:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1127,11 +1303,12 @@ func TestToBaggage(t *testing.T) {
 	})
 	transaction := StartTransaction(ctx, "transaction-name")
 	transaction.TraceID = TraceIDFromHex("f1a4c5c9071eca1cdf04e4132527ed16")
+	transaction.sampleRand = 0.5
 
 	assertBaggageStringsEqual(
 		t,
 		transaction.ToBaggage(),
-		"sentry-trace_id=f1a4c5c9071eca1cdf04e4132527ed16,sentry-release=test-release,sentry-transaction=transaction-name,sentry-sample_rate=1,sentry-sampled=true",
+		"sentry-trace_id=f1a4c5c9071eca1cdf04e4132527ed16,sentry-release=test-release,sentry-transaction=transaction-name,sentry-sample_rate=1,sentry-sample_rand=0.500000,sentry-sampled=true",
 	)
 
 	// Calling ToBaggage() on a child span should return the same result
@@ -1139,7 +1316,7 @@ func TestToBaggage(t *testing.T) {
 	assertBaggageStringsEqual(
 		t,
 		child.ToBaggage(),
-		"sentry-trace_id=f1a4c5c9071eca1cdf04e4132527ed16,sentry-release=test-release,sentry-transaction=transaction-name,sentry-sample_rate=1,sentry-sampled=true",
+		"sentry-trace_id=f1a4c5c9071eca1cdf04e4132527ed16,sentry-release=test-release,sentry-transaction=transaction-name,sentry-sample_rate=1,sentry-sample_rand=0.500000,sentry-sampled=true",
 	)
 }
 
@@ -1422,3 +1599,33 @@ func TestStrictTraceContinuation(t *testing.T) {
 		})
 	}
 }
+
+func TestSpan_Discard(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:    true,
+		TracesSampleRate: 1.0,
+		Transport:        transport,
+	})
+
+	transaction := StartTransaction(ctx, "transaction", WithIdleTimeout(time.Hour))
+	discarded := transaction.StartChild("discarded")
+	assert.Same(t, discarded, GetHubFromContext(ctx).Scope().GetSpan())
+	discarded.Discard()
+	assert.Same(t, transaction, GetHubFromContext(ctx).Scope().GetSpan())
+	assert.Zero(t, transaction.autoFinish.openChildren)
+	discarded.Finish()
+	kept := transaction.StartChild("kept")
+	kept.Finish()
+	transaction.Discard()
+	transaction.Finish()
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if len(events[0].Spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(events[0].Spans))
+	}
+	assert.Equal(t, "kept", events[0].Spans[0].Op)
+}
@@ -0,0 +1,282 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/debuglog"
+	"github.com/getsentry/sentry-go/internal/profile"
+)
+
+const (
+	// transactionProfileInterval is the interval between the goroutine stack
+	// samples of transaction profiles, about 100 Hz.
+	transactionProfileInterval = 10 * time.Millisecond
+	// transactionProfileMaxDuration caps the duration of transaction
+	// profiles. Samples are no longer taken after it, the profile is still
+	// sent when the transaction finishes.
+	transactionProfileMaxDuration = 30 * time.Second
+	// transactionProfileMinSamples is the number of samples a transaction
+	// profile needs to be accepted by Sentry.
+	transactionProfileMinSamples = 2
+)
+
+// spanProfile is the profiling state of a sampled transaction, whose
+// goroutine is labeled with the IDs of the transaction.
+type spanProfile struct {
+	// labels is the context with the pprof labels of the goroutine before the
+	// transaction started.
+	labels context.Context
+	// continuous indicates whether the CPU samples of the transaction are
+	// part of the continuous profile.
+	continuous bool
+	// sampler samples the goroutine stacks of the transaction if it was
+	// sampled by ClientOptions.ProfilesSampleRate, nil otherwise.
+	sampler *goroutineSampler
+}
+
+// startProfiling labels the goroutine that starts a sampled transaction, so
+// that the goroutines of the transaction can be told apart by the profilers.
+// The labels are inherited by the goroutines started after this point.
+func (s *Span) startProfiling(client *Client) {
+	continuous := client.profiler != nil
+	sampled := client.options.ProfilesSampleRate > 0 && sample(client.options.ProfilesSampleRate)
+	if !continuous && !sampled {
+		return
+	}
+
+	spanID := s.SpanID.String()
+	s.profile = &spanProfile{labels: s.ctx, continuous: continuous}
+	if sampled {
+		// Started before the goroutine is labeled, so that the sampler does
+		// not sample itself.
+		s.profile.sampler = startGoroutineSampler(s.SpanID, s.StartTime)
+	}
+	if continuous {
+		client.profiler.startTransaction(s)
+		// The Sentry UI shows the samples of the thread of a transaction.
+		s.SetData("thread.id", spanID)
+	}
+
+	s.ctx = pprof.WithLabels(s.ctx, pprof.Labels(
+		profileLabelTraceID, s.TraceID.String(),
+		profileLabelSpanID, spanID,
+	))
+	pprof.SetGoroutineLabels(s.ctx)
+}
+
+// finishProfiling restores the pprof labels of the goroutine that finishes a
+// transaction, and stops sampling it. It returns the profile context of the
+// transaction event and the transaction profile, both nil if the transaction
+// was not profiled.
+func (s *Span) finishProfiling(client *Client) (Context, *transactionProfile) {
+	if s.profile == nil {
+		return nil, nil
+	}
+	pprof.SetGoroutineLabels(s.profile.labels)
+
+	profileContext := Context{}
+	if s.profile.continuous && client != nil && client.profiler != nil {
+		client.profiler.finishTransaction(s)
+		profileContext["profiler_id"] = client.profiler.id
+	}
+	var tp *transactionProfile
+	if s.profile.sampler != nil {
+		tp = s.profile.sampler.Stop(s)
+		if tp != nil {
+			profileContext["profile_id"] = tp.EventID
+		}
+	}
+	if len(profileContext) == 0 {
+		return nil, nil
+	}
+	return profileContext, tp
+}
+
+// A goroutineSampler samples the stacks of the goroutines of a transaction,
+// found by their pprof labels, until it is stopped.
+type goroutineSampler struct {
+	// spanID is the value of the profileLabelSpanID label of the goroutines
+	// of the transaction.
+	spanID string
+	// threadID is the thread of the samples in the profile.
+	threadID string
+	start    time.Time
+	stop     chan struct{}
+	done     chan struct{}
+
+	// stacks and samples are only accessed by run until done is closed.
+	stacks  *profileStacks
+	samples []transactionProfileSample
+}
+
+func startGoroutineSampler(spanID SpanID, start time.Time) *goroutineSampler {
+	g := &goroutineSampler{
+		spanID: spanID.String(),
+		// Transaction profiles need numeric thread IDs, the span ID is used
+		// as a number.
+		threadID: strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10),
+		start:    start,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		stacks:   newProfileStacks(),
+	}
+	go g.run()
+	return g
+}
+
+func (g *goroutineSampler) run() {
+	defer close(g.done)
+
+	ticker := time.NewTicker(transactionProfileInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(transactionProfileMaxDuration)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-deadline.C:
+			debuglog.Printf("Profiler: transaction profile reached %s, sampling stopped", transactionProfileMaxDuration)
+			return
+		case <-ticker.C:
+			if err := g.sample(); err != nil {
+				debuglog.Printf("Profiler: cannot sample goroutines, sampling stopped: %v", err)
+				return
+			}
+		}
+	}
+}
+
+// sample takes a sample of the stacks of the goroutines of the transaction.
+func (g *goroutineSampler) sample() error {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 0); err != nil {
+		return err
+	}
+	elapsed := time.Since(g.start)
+	prof, err := profile.Parse(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	for _, sample := range prof.Samples {
+		if sample.Labels[profileLabelSpanID] != g.spanID || len(sample.Values) == 0 {
+			continue
+		}
+		stackID := g.stacks.stackID(sample.Stack)
+		// Goroutines with the same stack are aggregated in a sample, with
+		// their number as value.
+		for range sample.Values[0] {
+			g.samples = append(g.samples, transactionProfileSample{
+				ElapsedSinceStartNS: uint64(elapsed),
+				ThreadID:            g.threadID,
+				StackID:             stackID,
+			})
+		}
+	}
+	return nil
+}
+
+// Stop stops sampling and returns the profile of the transaction, or nil if
+// too few samples were taken.
+func (g *goroutineSampler) Stop(s *Span) *transactionProfile {
+	close(g.stop)
+	<-g.done
+
+	if len(g.samples) < transactionProfileMinSamples {
+		debuglog.Printf("Profiler: dropping transaction profile with %d samples", len(g.samples))
+		return nil
+	}
+	return &transactionProfile{
+		EventID:   uuid(),
+		Platform:  "go",
+		Version:   "1",
+		Timestamp: s.StartTime,
+		OS:        transactionProfileOS{Name: runtime.GOOS},
+		Device:    transactionProfileDevice{Architecture: runtime.GOARCH},
+		Runtime:   transactionProfileRuntime{Name: "go", Version: runtime.Version()},
+		Transaction: transactionProfileTransaction{
+			ActiveThreadID: g.threadID,
+			Name:           s.Name,
+			TraceID:        s.TraceID.String(),
+		},
+		Profile: transactionProfileData{
+			Samples: g.samples,
+			Stacks:  g.stacks.stacks,
+			Frames:  g.stacks.frames,
+			ThreadMetadata: map[string]profileThread{
+				g.threadID: {Name: s.Name},
+			},
+		},
+	}
+}
+
+// transactionProfile is the profile of a transaction, in the format of the
+// profile envelope item. It is sent in the envelope of the transaction.
+type transactionProfile struct {
+	EventID     string                        `json:"event_id"`
+	Platform    string                        `json:"platform"`
+	Version     string                        `json:"version"`
+	Timestamp   time.Time                     `json:"timestamp"`
+	Release     string                        `json:"release,omitempty"`
+	Environment string                        `json:"environment,omitempty"`
+	OS          transactionProfileOS          `json:"os"`
+	Device      transactionProfileDevice      `json:"device"`
+	Runtime     transactionProfileRuntime     `json:"runtime"`
+	Transaction transactionProfileTransaction `json:"transaction"`
+	Profile     transactionProfileData        `json:"profile"`
+}
+
+type transactionProfileOS struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type transactionProfileDevice struct {
+	Architecture string `json:"architecture"`
+}
+
+type transactionProfileRuntime struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type transactionProfileTransaction struct {
+	// ID is the event ID of the transaction.
+	ID             EventID `json:"id"`
+	Name           string  `json:"name"`
+	TraceID        string  `json:"trace_id"`
+	ActiveThreadID string  `json:"active_thread_id"`
+}
+
+// transactionProfileData is the sampled stacks of a transaction profile.
+type transactionProfileData struct {
+	Samples        []transactionProfileSample `json:"samples"`
+	Stacks         [][]int                    `json:"stacks"`
+	Frames         []Frame                    `json:"frames"`
+	ThreadMetadata map[string]profileThread   `json:"thread_metadata"`
+}
+
+type transactionProfileSample struct {
+	ElapsedSinceStartNS uint64 `json:"elapsed_since_start_ns"`
+	ThreadID            string `json:"thread_id"`
+	StackID             int    `json:"stack_id"`
+}
+
+// marshalProfile serializes the transaction profile of a transaction event,
+// linked to the event and with the release and environment it was sent with.
+func (e *Event) marshalProfile() ([]byte, error) {
+	p := *e.sdkMetaData.transactionProfile
+	p.Release = e.Release
+	p.Environment = e.Environment
+	p.Transaction.ID = e.EventID
+	return json.Marshal(p)
+}
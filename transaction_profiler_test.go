@@ -0,0 +1,184 @@
+package sentry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockInTransaction blocks until release is closed, so that it is sampled by
+// the transaction profiler.
+func blockInTransaction(release <-chan struct{}) {
+	<-release
+}
+
+func TestTransactionProfile(t *testing.T) {
+	forEachPipeline(t, func(t *testing.T, disableTelemetryBuffer bool) {
+		var mu sync.Mutex
+		var items map[protocol.EnvelopeItemType][]byte
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			envelope, err := protocol.ParseEnvelope(body)
+			if err != nil {
+				t.Errorf("invalid envelope: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, item := range envelope.Items {
+				if item.Header.Type == protocol.EnvelopeItemTypeTransaction || item.Header.Type == protocol.EnvelopeItemTypeProfile {
+					if items == nil {
+						items = make(map[protocol.EnvelopeItemType][]byte)
+					}
+					items[item.Header.Type] = item.Payload
+				}
+			}
+		}))
+		defer srv.Close()
+
+		client, err := NewClient(ClientOptions{
+			Dsn:                    strings.Replace(srv.URL, "//", "//public@", 1) + "/1",
+			EnableTracing:          true,
+			TracesSampleRate:       1.0,
+			ProfilesSampleRate:     1.0,
+			Release:                "1.0.0",
+			DisableTelemetryBuffer: disableTelemetryBuffer,
+		})
+		require.NoError(t, err)
+		defer client.Close()
+
+		ctx := SetHubOnContext(context.Background(), NewHub(client, NewScope()))
+		transaction := StartTransaction(ctx, "profiled")
+		require.NotNil(t, transaction.profile)
+		require.NotNil(t, transaction.profile.sampler)
+		// Goroutines started by the transaction are part of its profile.
+		release := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			blockInTransaction(release)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		transaction.Finish()
+		require.True(t, client.Flush(time.Second))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Contains(t, items, protocol.EnvelopeItemTypeTransaction)
+		require.Contains(t, items, protocol.EnvelopeItemTypeProfile)
+
+		var event struct {
+			EventID  string                            `json:"event_id"`
+			Contexts map[string]map[string]interface{} `json:"contexts"`
+		}
+		require.NoError(t, json.Unmarshal(items[protocol.EnvelopeItemTypeTransaction], &event))
+		var p transactionProfile
+		require.NoError(t, json.Unmarshal(items[protocol.EnvelopeItemTypeProfile], &p))
+
+		assert.Equal(t, p.EventID, event.Contexts["profile"]["profile_id"])
+		assert.NotContains(t, event.Contexts["profile"], "profiler_id")
+		assert.Equal(t, EventID(event.EventID), p.Transaction.ID)
+		assert.Equal(t, "profiled", p.Transaction.Name)
+		assert.Equal(t, transaction.TraceID.String(), p.Transaction.TraceID)
+		assert.Equal(t, "go", p.Platform)
+		assert.Equal(t, "1", p.Version)
+		assert.Equal(t, "1.0.0", p.Release)
+
+		threadID := strconv.FormatUint(binary.BigEndian.Uint64(transaction.SpanID[:]), 10)
+		assert.Equal(t, threadID, p.Transaction.ActiveThreadID)
+		assert.Equal(t, "profiled", p.Profile.ThreadMetadata[threadID].Name)
+		require.GreaterOrEqual(t, len(p.Profile.Samples), transactionProfileMinSamples)
+
+		var blocked bool
+		for _, sample := range p.Profile.Samples {
+			assert.Equal(t, threadID, sample.ThreadID)
+			assert.LessOrEqual(t, sample.ElapsedSinceStartNS, uint64(transaction.EndTime.Sub(transaction.StartTime)))
+			for _, frameID := range p.Profile.Stacks[sample.StackID] {
+				if p.Profile.Frames[frameID].Function == "blockInTransaction" {
+					blocked = true
+				}
+			}
+		}
+		assert.True(t, blocked, "no sample of the goroutine started by the transaction")
+	})
+}
+
+func TestTransactionProfile_TooFewSamples(t *testing.T) {
+	transport := &MockTransport{}
+	ctx := NewTestContext(ClientOptions{
+		EnableTracing:      true,
+		TracesSampleRate:   1.0,
+		ProfilesSampleRate: 1.0,
+		Transport:          transport,
+	})
+
+	transaction := StartTransaction(ctx, "short")
+	transaction.Finish()
+
+	require.Len(t, transport.Events(), 1)
+	event := transport.Events()[0]
+	assert.NotContains(t, event.Contexts, "profile")
+	assert.Nil(t, event.sdkMetaData.transactionProfile)
+}
+
+func TestTransactionProfile_SampleRate(t *testing.T) {
+	tests := map[string]ClientOptions{
+		"profiles not sampled": {
+			EnableTracing:    true,
+			TracesSampleRate: 1.0,
+		},
+		"transaction not sampled": {
+			EnableTracing:      true,
+			TracesSampleRate:   0.0,
+			ProfilesSampleRate: 1.0,
+		},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := NewTestContext(options)
+			transaction := StartTransaction(ctx, "transaction")
+			defer transaction.Finish()
+			assert.Nil(t, transaction.profile)
+		})
+	}
+}
+
+func TestEnvelopeFromBody_TransactionProfile(t *testing.T) {
+	event := NewEvent()
+	event.EventID = "b81c5be4d31e48959103a1f878a1efcb"
+	event.Type = transactionType
+	event.Release = "1.0.0"
+	event.sdkMetaData.transactionProfile = &transactionProfile{EventID: "0cdb2b0ad7d94fa6874afc0dc5c8ef92"}
+	body, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	b, err := envelopeFromBody(event, newTestDSN(t), time.Now(), body, report.NoopRecorder())
+	require.NoError(t, err)
+	envelope, err := protocol.ParseEnvelope(b.Bytes())
+	require.NoError(t, err)
+	require.Len(t, envelope.Items, 2)
+	assert.Equal(t, protocol.EnvelopeItemTypeTransaction, envelope.Items[0].Header.Type)
+	assert.Equal(t, protocol.EnvelopeItemTypeProfile, envelope.Items[1].Header.Type)
+
+	var p transactionProfile
+	require.NoError(t, json.Unmarshal(envelope.Items[1].Payload, &p))
+	assert.Equal(t, "0cdb2b0ad7d94fa6874afc0dc5c8ef92", p.EventID)
+	assert.Equal(t, event.EventID, p.Transaction.ID)
+	assert.Equal(t, "1.0.0", p.Release)
+}
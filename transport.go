@@ -2,9 +2,11 @@ package sentry
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/getsentry/sentry-go/internal/debuglog"
 	httpinternal "github.com/getsentry/sentry-go/internal/http"
+	"github.com/getsentry/sentry-go/internal/offline"
 	"github.com/getsentry/sentry-go/internal/protocol"
 	"github.com/getsentry/sentry-go/internal/ratelimit"
 	"github.com/getsentry/sentry-go/internal/util"
@@ -26,6 +29,122 @@ const (
 	defaultClientReportsTick = time.Second * 30
 )
 
+// OfflineCacheOptions configures the on-disk store that HTTPTransport and the
+// telemetry buffer transport use to keep envelopes that could not be delivered,
+// for example because Sentry is unreachable or the transport queue is full.
+//
+// Stored envelopes are replayed when the transport starts and once Sentry is
+// reachable again. Envelopes evicted from the store are reported in client
+// reports with the "cache_overflow" reason.
+type OfflineCacheOptions struct {
+	// Dir is the directory envelopes are written to. The offline cache is
+	// disabled when Dir is empty.
+	Dir string
+	// MaxBytes is the maximum total size of the stored envelopes. When it is
+	// exceeded, the oldest envelopes are evicted first. Defaults to 16 MiB.
+	MaxBytes int64
+	// MaxAge is the maximum time an envelope is kept on disk before it is
+	// evicted instead of replayed. Defaults to 24 hours.
+	MaxAge time.Duration
+}
+
+func (o OfflineCacheOptions) internal() offline.Options {
+	return offline.Options{
+		Dir:      o.Dir,
+		MaxBytes: o.MaxBytes,
+		MaxAge:   o.MaxAge,
+	}
+}
+
+// CompressionAlgorithm is the content encoding used to compress request bodies
+// sent to Sentry.
+type CompressionAlgorithm string
+
+const (
+	// CompressionNone sends request bodies uncompressed.
+	CompressionNone CompressionAlgorithm = ""
+	// CompressionGzip compresses request bodies with gzip.
+	CompressionGzip CompressionAlgorithm = httpinternal.EncodingGzip
+	// CompressionZstd compresses request bodies with zstd. The SDK does not
+	// ship a zstd encoder, so CompressionOptions.NewWriter must be set, for
+	// example to a function returning a github.com/klauspost/compress/zstd
+	// encoder.
+	CompressionZstd CompressionAlgorithm = httpinternal.EncodingZstd
+)
+
+// CompressionOptions configures compression of the request bodies that the
+// HTTP transports send to Sentry. Compressed requests carry a matching
+// Content-Encoding header. If a body cannot be compressed, it is sent
+// uncompressed.
+type CompressionOptions struct {
+	// Algorithm selects the compression algorithm. Request bodies are sent
+	// uncompressed when it is CompressionNone.
+	Algorithm CompressionAlgorithm
+	// Threshold is the size in bytes below which request bodies are sent
+	// uncompressed, since compressing small envelopes rarely pays off.
+	Threshold int
+	// Level is the gzip compression level, see compress/gzip. Zero uses
+	// gzip.DefaultCompression.
+	Level int
+	// NewWriter returns an encoder that writes compressed data to w. It is
+	// required for CompressionZstd and replaces the built-in gzip encoder for
+	// CompressionGzip.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// internal validates the options once, so that invalid options disable
+// compression instead of failing every request.
+func (o CompressionOptions) internal() httpinternal.Compression {
+	if o.Algorithm != CompressionNone && o.Algorithm != CompressionGzip && o.NewWriter == nil {
+		debuglog.Printf("Disabling compression: %s requires CompressionOptions.NewWriter", o.Algorithm)
+		return httpinternal.Compression{}
+	}
+	if o.Algorithm == CompressionGzip && o.NewWriter == nil && (o.Level < gzip.HuffmanOnly || o.Level > gzip.BestCompression) {
+		debuglog.Printf("Ignoring invalid gzip compression level: %d", o.Level)
+		o.Level = 0
+	}
+	if o.Threshold < 0 {
+		o.Threshold = 0
+	}
+	return httpinternal.Compression{
+		Encoding:  string(o.Algorithm),
+		Threshold: o.Threshold,
+		Level:     o.Level,
+		NewWriter: o.NewWriter,
+	}
+}
+
+// RetryOptions configures how HTTPTransport and the telemetry buffer transport
+// retry envelopes that failed with a transient error: a network error, a 5xx
+// response or a 408 Request Timeout.
+//
+// Retries are delayed with jittered exponential backoff. The delay honors the
+// Retry-After response header and active rate limits; if either requires a
+// longer wait than MaxBackoff, the envelope is not retried. Other envelopes
+// are sent while an envelope waits for its retry. Flush waits for pending
+// retries, Close gives up on them. Envelopes that still fail once the retry
+// budget is used up are kept in the offline cache if one is configured, or
+// reported in client reports with the "retries_exhausted" reason.
+type RetryOptions struct {
+	// MaxRetries is the number of times an envelope is retried after the
+	// first attempt failed. Retries are disabled when it is zero.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry, doubling with every
+	// further retry. Defaults to 500 milliseconds.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts. Defaults to 30
+	// seconds.
+	MaxBackoff time.Duration
+}
+
+func (o RetryOptions) internal() httpinternal.Retry {
+	return httpinternal.Retry{
+		MaxRetries:     o.MaxRetries,
+		InitialBackoff: o.InitialBackoff,
+		MaxBackoff:     o.MaxBackoff,
+	}
+}
+
 // Transport is used by the Client to deliver events to remote server.
 type Transport interface {
 	Flush(timeout time.Duration) bool
@@ -76,13 +195,24 @@ func eventDebugContext(event *Event) string {
 	)
 }
 
-func getRequestBodyFromEvent(event *Event) []byte {
-	body, err := json.Marshal(event)
+// getRequestBodyFromEvent serializes the event, trimming it to the event size
+// limit if needed.
+func getRequestBodyFromEvent(event *Event) ([]byte, error) {
+	body, err := event.marshalWithinLimit(protocol.DefaultLimits.MaxEventSize)
 	if err != nil {
 		debuglog.Printf("Could not encode event as JSON, skipping delivery. %s: %v", eventDebugContext(event), err)
-		return nil
+		return nil, err
 	}
-	return body
+	return body, nil
+}
+
+// discardReasonForError returns the client report reason for an event that
+// could not be serialized.
+func discardReasonForError(err error) report.DiscardReason {
+	if errors.Is(err, protocol.ErrTooLarge) {
+		return report.ReasonTooLarge
+	}
+	return report.ReasonInternalError
 }
 
 func encodeAttachment(enc *json.Encoder, b io.Writer, attachment *Attachment) error {
@@ -228,7 +358,10 @@ func encodeEnvelopeHeader(enc *json.Encoder, header *envelopeHeader) error {
 	return enc.Encode(header)
 }
 
-func envelopeFromBody(event *Event, dsn *Dsn, sentAt time.Time, body json.RawMessage) (*bytes.Buffer, error) {
+// envelopeFromBody builds an envelope from a serialized event and its
+// attachments. Attachments over the size limits are dropped and recorded with
+// recorder.
+func envelopeFromBody(event *Event, dsn *Dsn, sentAt time.Time, body json.RawMessage, recorder report.ClientReportRecorder) (*bytes.Buffer, error) {
 	var b bytes.Buffer
 	enc := json.NewEncoder(&b)
 
@@ -270,8 +403,25 @@ func envelopeFromBody(event *Event, dsn *Dsn, sentAt time.Time, body json.RawMes
 		return nil, err
 	}
 
+	if event.sdkMetaData.transactionProfile != nil {
+		profile, err := event.marshalProfile()
+		if err != nil {
+			return nil, err
+		}
+		if err := encodeEnvelopeItem(enc, string(protocol.EnvelopeItemTypeProfile), profile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Attachments
+	limits := protocol.DefaultLimits
 	for _, attachment := range event.Attachments {
+		size := len(attachment.Payload)
+		if size > limits.MaxAttachmentSize || b.Len()+size > limits.MaxEnvelopeSize {
+			debuglog.Printf("Dropping attachment %q of %d bytes, over the size limit", attachment.Filename, size)
+			recorder.Record(report.ReasonTooLarge, ratelimit.CategoryAttachment, int64(size))
+			continue
+		}
 		if err := encodeAttachment(enc, &b, attachment); err != nil {
 			return nil, err
 		}
@@ -282,16 +432,17 @@ func envelopeFromBody(event *Event, dsn *Dsn, sentAt time.Time, body json.RawMes
 
 // getRequestFromEnvelope creates an HTTP request from a pre-built envelope.
 // sdkName and sdkVersion are used for User-Agent and authentication headers.
-func getRequestFromEnvelope(ctx context.Context, dsn *Dsn, envelope *bytes.Buffer, sdkName, sdkVersion string) (*http.Request, error) {
+func getRequestFromEnvelope(ctx context.Context, dsn *Dsn, envelope *bytes.Buffer, sdkName, sdkVersion string, compression httpinternal.Compression) (*http.Request, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
+	body, encoding := compression.Compress(envelope.Bytes())
 	request, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPost,
 		dsn.GetAPIURL().String(),
-		envelope,
+		bytes.NewReader(body),
 	)
 	if err != nil {
 		return nil, err
@@ -299,18 +450,11 @@ func getRequestFromEnvelope(ctx context.Context, dsn *Dsn, envelope *bytes.Buffe
 
 	request.Header.Set("User-Agent", fmt.Sprintf("%s/%s", sdkName, sdkVersion))
 	request.Header.Set("Content-Type", "application/x-sentry-envelope")
-
-	auth := fmt.Sprintf("Sentry sentry_version=%s, "+
-		"sentry_client=%s/%s, sentry_key=%s", apiVersion, sdkName, sdkVersion, dsn.GetPublicKey())
-
-	// The key sentry_secret is effectively deprecated and no longer needs to be set.
-	// However, since it was required in older self-hosted versions,
-	// it should still be passed through to Sentry if set.
-	if dsn.GetSecretKey() != "" {
-		auth = fmt.Sprintf("%s, sentry_secret=%s", auth, dsn.GetSecretKey())
+	if encoding != "" {
+		request.Header.Set("Content-Encoding", encoding)
 	}
 
-	request.Header.Set("X-Sentry-Auth", auth)
+	request.Header.Set("X-Sentry-Auth", dsn.AuthHeader(sdkName, sdkVersion))
 
 	return request, nil
 }
@@ -350,10 +494,17 @@ type HTTPTransport struct {
 	recorder  report.ClientReportRecorder
 	provider  report.ClientReportProvider
 
+	compression httpinternal.Compression
+	retry       httpinternal.Retry
+
 	// buffer is a channel of batches. Calling Flush terminates work on the
 	// current in-flight items and starts a new batch for subsequent events.
 	buffer chan batch
 
+	// retries holds items waiting for another delivery attempt. It belongs to
+	// the worker.
+	retries *httpinternal.RetryQueue[retryItem]
+
 	startOnce sync.Once
 	closeOnce sync.Once
 
@@ -365,6 +516,9 @@ type HTTPTransport struct {
 	mu     sync.RWMutex
 	limits ratelimit.Map
 
+	// cache persists envelopes that could not be delivered.
+	cache *offline.Store
+
 	// receiving signal will terminate worker.
 	done chan struct{}
 }
@@ -395,11 +549,24 @@ func (t *HTTPTransport) Configure(options ClientOptions) {
 	if t.provider == nil {
 		t.provider = report.NoopProvider()
 	}
+	t.compression = options.Compression.internal()
+	t.retry = options.Retry.internal()
+
+	if t.cache == nil {
+		cache, err := offline.NewStore(options.OfflineCache.internal(), t.recorder)
+		if err != nil {
+			debuglog.Printf("Offline cache is disabled: %v", err)
+		}
+		t.cache = cache
+	}
 
 	// A buffered channel with capacity 1 works like a mutex, ensuring only one
 	// goroutine can access the current batch at a given time. Access is
 	// synchronized by reading from and writing to the channel.
 	t.buffer = make(chan batch, 1)
+	if t.retries == nil {
+		t.retries = httpinternal.NewRetryQueue[retryItem](t.BufferSize)
+	}
 	t.buffer <- batch{
 		items:   make(chan batchItem, t.BufferSize),
 		started: make(chan struct{}),
@@ -447,12 +614,12 @@ func (t *HTTPTransport) SendEventWithContext(ctx context.Context, event *Event)
 		return
 	}
 
-	body := getRequestBodyFromEvent(event)
-	if body == nil {
-		recordForEvent(t.recorder, report.ReasonInternalError, event)
+	body, err := getRequestBodyFromEvent(event)
+	if err != nil {
+		recordForEvent(t.recorder, discardReasonForError(err), event)
 		return
 	}
-	envelope, err := envelopeFromBody(event, t.dsn, time.Now(), body)
+	envelope, err := envelopeFromBody(event, t.dsn, time.Now(), body, t.recorder)
 	if err != nil {
 		debuglog.Printf("Failed to build envelope, skipping delivery. %s: %v", eventDebugContext(event), err)
 		recordForEvent(t.recorder, report.ReasonInternalError, event)
@@ -493,6 +660,10 @@ func (t *HTTPTransport) SendEventWithContext(ctx context.Context, event *Event)
 			t.dsn.GetProjectID(),
 		)
 	default:
+		if t.cache.Keep(envelope.Bytes()) {
+			debuglog.Printf("Transport buffer full, stored %s in offline cache", identifier)
+			break
+		}
 		debuglog.Printf("Event dropped due to transport buffer being full. %s", eventDebugContext(event))
 		recordForEvent(t.recorder, report.ReasonQueueOverflow, event)
 	}
@@ -583,6 +754,9 @@ func (t *HTTPTransport) Close() {
 func (t *HTTPTransport) worker() {
 	crTicker := time.NewTicker(defaultClientReportsTick)
 	defer crTicker.Stop()
+
+	t.cache.ReplayPending(t.replayEnvelope)
+
 	for b := range t.buffer {
 		// Signal that processing of the current batch has started.
 		close(b.started)
@@ -596,8 +770,11 @@ func (t *HTTPTransport) worker() {
 		for {
 			select {
 			case <-t.done:
+				t.persistBatch(b)
+				t.persistRetries()
 				return
 			case <-crTicker.C:
+				t.cache.ReplayPending(t.replayEnvelope)
 				r := t.provider.TakeReport()
 				if r != nil {
 					var buf bytes.Buffer
@@ -615,7 +792,7 @@ func (t *HTTPTransport) worker() {
 					if err := encodeClientReport(enc, r); err != nil {
 						continue
 					}
-					req, err := getRequestFromEnvelope(context.Background(), t.dsn, &buf, sdkIdentifier, SDKVersion)
+					req, err := getRequestFromEnvelope(context.Background(), t.dsn, &buf, sdkIdentifier, SDKVersion, t.compression)
 					if err != nil {
 						debuglog.Printf("There was an issue when creating the request: %v", err)
 						continue
@@ -638,28 +815,21 @@ func (t *HTTPTransport) worker() {
 					continue
 				}
 
-				// Attach accumulated client report inside the worker to avoid background queue overflows.
-				t.attachClientReport(item.envelope)
-				request, err := getRequestFromEnvelope(item.ctx, t.dsn, item.envelope, item.sdkName, item.sdkVersion)
-				if err != nil {
-					debuglog.Printf("There was an issue when creating the request: %v", err)
-					recordForBatchItem(t.recorder, report.ReasonInternalError, &item)
-					continue
-				}
-
-				result, err := util.DoSendRequest(t.client, request, item.eventIdentifier)
-				if err != nil {
-					debuglog.Printf("There was an issue with sending an event: %v", err)
-					recordForBatchItem(t.recorder, report.ReasonNetworkError, &item)
-					continue
-				}
-				if result.IsSendError() {
-					recordForBatchItem(t.recorder, report.ReasonSendError, &item)
-				}
+				t.sendItem(&item)
+			case <-t.retries.C():
+				t.retryDue()
+			}
+		}
 
-				t.mu.Lock()
-				t.limits.Merge(result.Limits)
-				t.mu.Unlock()
+		// Items of the batch that are waiting for a retry must be delivered
+		// or given up before the batch is done.
+		for t.retries.Len() > 0 {
+			select {
+			case <-t.done:
+				t.persistRetries()
+				return
+			case <-t.retries.C():
+				t.retryDue()
 			}
 		}
 
@@ -668,6 +838,91 @@ func (t *HTTPTransport) worker() {
 	}
 }
 
+// sendItem delivers a batch item to Sentry. Items that failed with a transient
+// error are queued for a retry when retries are enabled, and otherwise kept in
+// the offline cache or recorded as discarded.
+func (t *HTTPTransport) sendItem(item *batchItem) {
+	// Attach accumulated client report inside the worker to avoid background queue overflows.
+	t.attachClientReport(item.envelope)
+	payload := item.envelope.Bytes()
+	request, err := getRequestFromEnvelope(item.ctx, t.dsn, item.envelope, item.sdkName, item.sdkVersion, t.compression)
+	if err != nil {
+		debuglog.Printf("There was an issue when creating the request: %v", err)
+		recordForBatchItem(t.recorder, report.ReasonInternalError, item)
+		return
+	}
+
+	t.attempt(retryItem{item: item, request: request, payload: payload}, 1)
+}
+
+// retryItem is a batch item waiting for another delivery attempt.
+type retryItem struct {
+	item    *batchItem
+	request *http.Request
+	payload []byte
+}
+
+// attempt makes one attempt to deliver a batch item, and queues it for a
+// retry if it failed with a transient error.
+func (t *HTTPTransport) attempt(r retryItem, attempt int) {
+	result, err := util.DoSendRequest(t.client, r.request, r.item.eventIdentifier)
+	if err != nil {
+		debuglog.Printf("There was an issue with sending an event: %v", err)
+	} else {
+		t.mu.Lock()
+		t.limits.Merge(result.Limits)
+		t.mu.Unlock()
+	}
+
+	outcome, delay, reason := t.retry.Next(attempt, result, err, t.rateLimitWait(r.item.category))
+	switch outcome {
+	case httpinternal.Delivered:
+		// Sentry is reachable again, deliver what was stored while it was not.
+		t.cache.ReplayPending(t.replayEnvelope)
+	case httpinternal.Rejected:
+		if reason != "" {
+			recordForBatchItem(t.recorder, reason, r.item)
+		}
+	case httpinternal.RetryLater:
+		if t.retries.Push(r, attempt+1, delay) {
+			debuglog.Printf("Retrying %s in %s", r.item.eventIdentifier, delay)
+			return
+		}
+		t.storeOrRecord(report.ReasonQueueOverflow, r.payload, r.item)
+	case httpinternal.Undelivered:
+		if reason == report.ReasonRetriesExhausted {
+			debuglog.Printf("Giving up on %s after %d attempts", r.item.eventIdentifier, attempt)
+		}
+		t.storeOrRecord(reason, r.payload, r.item)
+	}
+}
+
+// retryDue sends the items whose retry is due.
+func (t *HTTPTransport) retryDue() {
+	for _, pending := range t.retries.Due(time.Now()) {
+		r := pending.Item
+		request, err := httpinternal.RewindRequest(r.request.Context(), r.request)
+		if err != nil {
+			debuglog.Printf("There was an issue when creating the request: %v", err)
+			recordForBatchItem(t.recorder, report.ReasonInternalError, r.item)
+			continue
+		}
+		if request.Context().Err() != nil {
+			t.storeOrRecord(report.ReasonRetriesExhausted, r.payload, r.item)
+			continue
+		}
+		r.request = request
+		t.attempt(r, pending.Attempt)
+	}
+}
+
+// rateLimitWait returns how long the category is still rate limited.
+func (t *HTTPTransport) rateLimitWait(category ratelimit.Category) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return time.Until(time.Time(t.limits.Deadline(category)))
+}
+
 // attachClientReport takes any pending client report from the provider and
 // appends it to the envelope buffer.
 func (t *HTTPTransport) attachClientReport(buf *bytes.Buffer) {
@@ -681,6 +936,95 @@ func (t *HTTPTransport) attachClientReport(buf *bytes.Buffer) {
 	}
 }
 
+// storeOrRecord keeps an undelivered envelope in the offline cache, or records
+// the item as discarded with the given reason if there is no cache.
+func (t *HTTPTransport) storeOrRecord(reason report.DiscardReason, payload []byte, item *batchItem) {
+	if t.cache.Keep(payload) {
+		debuglog.Printf("Stored %s in offline cache", item.eventIdentifier)
+		return
+	}
+	recordForBatchItem(t.recorder, reason, item)
+}
+
+// persistBatch moves items still waiting in the batch to the offline cache so
+// they survive a restart.
+func (t *HTTPTransport) persistBatch(b batch) {
+	if t.cache == nil {
+		return
+	}
+	for {
+		select {
+		case item, open := <-b.items:
+			if !open {
+				return
+			}
+			t.storeOrRecord(report.ReasonQueueOverflow, item.envelope.Bytes(), &item)
+		default:
+			return
+		}
+	}
+}
+
+// persistRetries moves items still waiting for a retry to the offline cache,
+// or records them as discarded.
+func (t *HTTPTransport) persistRetries() {
+	for _, pending := range t.retries.Drain() {
+		t.storeOrRecord(report.ReasonRetriesExhausted, pending.Item.payload, pending.Item.item)
+	}
+}
+
+// replayEnvelope sends an envelope read from the offline cache. It returns
+// false if the envelope should be kept for a later attempt.
+func (t *HTTPTransport) replayEnvelope(envelope *protocol.Envelope) bool {
+	if t.disabled(util.EnvelopeCategory(envelope)) {
+		// Keep it until the rate limit is over.
+		return false
+	}
+	envelope.Header.SentAt = time.Now()
+
+	var buf bytes.Buffer
+	if _, err := envelope.WriteTo(&buf); err != nil {
+		debuglog.Printf("Failed to serialize cached envelope: %v", err)
+		t.recorder.RecordForEnvelope(report.ReasonInternalError, envelope)
+		return true
+	}
+
+	sdkName, sdkVersion := sdkIdentifier, SDKVersion
+	if sdk := envelope.Header.Sdk; sdk != nil && sdk.Name != "" {
+		sdkName, sdkVersion = sdk.Name, sdk.Version
+	}
+	request, err := getRequestFromEnvelope(context.Background(), t.dsn, &buf, sdkName, sdkVersion, t.compression)
+	if err != nil {
+		debuglog.Printf("There was an issue when creating the request: %v", err)
+		t.recorder.RecordForEnvelope(report.ReasonInternalError, envelope)
+		return true
+	}
+
+	result, err := util.DoSendRequest(t.client, request, util.EnvelopeIdentifier(envelope))
+	if err == nil {
+		t.mu.Lock()
+		t.limits.Merge(result.Limits)
+		t.mu.Unlock()
+	}
+	return httpinternal.ReplayHandled(t.recorder, envelope, result, err)
+}
+
+// stats returns the number of events in the current batch and the active
+// rate limits.
+func (t *HTTPTransport) stats() protocol.TransportStats {
+	stats := protocol.TransportStats{QueueCapacity: t.BufferSize}
+	if t.buffer != nil {
+		// See SendEventWithContext for how t.buffer is used as a lock.
+		b := <-t.buffer
+		stats.QueueSize = len(b.items)
+		t.buffer <- b
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	stats.RateLimits = t.limits.Active()
+	return stats
+}
+
 func (t *HTTPTransport) disabled(c ratelimit.Category) bool {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -713,6 +1057,8 @@ type HTTPSyncTransport struct {
 	recorder  report.ClientReportRecorder
 	provider  report.ClientReportProvider
 
+	compression httpinternal.Compression
+
 	mu     sync.Mutex
 	limits ratelimit.Map
 
@@ -745,6 +1091,7 @@ func (t *HTTPSyncTransport) Configure(options ClientOptions) {
 	if t.provider == nil {
 		t.provider = report.NoopProvider()
 	}
+	t.compression = options.Compression.internal()
 
 	if options.HTTPTransport != nil {
 		t.transport = options.HTTPTransport
@@ -784,13 +1131,13 @@ func (t *HTTPSyncTransport) SendEventWithContext(ctx context.Context, event *Eve
 		return
 	}
 
-	body := getRequestBodyFromEvent(event)
-	if body == nil {
-		recordForEvent(t.recorder, report.ReasonInternalError, event)
+	body, err := getRequestBodyFromEvent(event)
+	if err != nil {
+		recordForEvent(t.recorder, discardReasonForError(err), event)
 		return
 	}
 
-	envelope, err := envelopeFromBody(event, t.dsn, time.Now(), body)
+	envelope, err := envelopeFromBody(event, t.dsn, time.Now(), body, t.recorder)
 	if err != nil {
 		debuglog.Printf("Failed to build envelope, skipping delivery. %s: %v", eventDebugContext(event), err)
 		recordForEvent(t.recorder, report.ReasonInternalError, event)
@@ -804,7 +1151,7 @@ func (t *HTTPSyncTransport) SendEventWithContext(ctx context.Context, event *Eve
 		}
 	}
 
-	request, err := getRequestFromEnvelope(ctx, t.dsn, envelope, event.Sdk.Name, event.Sdk.Version)
+	request, err := getRequestFromEnvelope(ctx, t.dsn, envelope, event.Sdk.Name, event.Sdk.Version, t.compression)
 	if err != nil {
 		recordForEvent(t.recorder, report.ReasonInternalError, event)
 		return
@@ -843,6 +1190,13 @@ func (t *HTTPSyncTransport) FlushWithContext(_ context.Context) bool {
 	return true
 }
 
+// stats returns the active rate limits. HTTPSyncTransport has no queue.
+func (t *HTTPSyncTransport) stats() protocol.TransportStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return protocol.TransportStats{RateLimits: t.limits.Active()}
+}
+
 func (t *HTTPSyncTransport) disabled(c ratelimit.Category) bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -912,6 +1266,9 @@ func (a *internalAsyncTransportAdapter) Configure(options ClientOptions) {
 		CaCerts:       options.CaCerts,
 		Recorder:      a.recorder,
 		Provider:      a.provider,
+		OfflineCache:  options.OfflineCache.internal(),
+		Compression:   options.Compression.internal(),
+		Retry:         options.Retry.internal(),
 		SdkInfo: func() *protocol.SdkInfo {
 			return &protocol.SdkInfo{
 				Name:    sdkIdentifier,
@@ -941,7 +1298,7 @@ func (a *internalAsyncTransportAdapter) SendEvent(event *Event) {
 	if err != nil {
 		debuglog.Printf("Failed to convert event to envelope, skipping delivery. %s: %v", eventDebugContext(event), err)
 		if a.recorder != nil {
-			recordForEvent(a.recorder, report.ReasonInternalError, event)
+			recordForEvent(a.recorder, discardReasonForError(err), event)
 		}
 		return
 	}
@@ -959,6 +1316,10 @@ func (a *internalAsyncTransportAdapter) FlushWithContext(ctx context.Context) bo
 	return a.transport.FlushWithContext(ctx)
 }
 
+func (a *internalAsyncTransportAdapter) stats() protocol.TransportStats {
+	return httpinternal.Stats(a.transport)
+}
+
 func (a *internalAsyncTransportAdapter) Close() {
 	a.transport.Close()
 }
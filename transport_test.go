@@ -2,6 +2,7 @@ package sentry
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -17,9 +18,14 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go/attribute"
+	httpinternal "github.com/getsentry/sentry-go/internal/http"
+	"github.com/getsentry/sentry-go/internal/protocol"
+	"github.com/getsentry/sentry-go/internal/ratelimit"
 	"github.com/getsentry/sentry-go/internal/testutils"
 	"github.com/getsentry/sentry-go/internal/util"
+	"github.com/getsentry/sentry-go/report"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/goleak"
 )
 
@@ -32,7 +38,7 @@ const (
 )
 
 func TestGetRequestBodyFromEventValid(t *testing.T) {
-	body := getRequestBodyFromEvent(&Event{
+	body, _ := getRequestBodyFromEvent(&Event{
 		Message: "mkey",
 	})
 
@@ -45,7 +51,7 @@ func TestGetRequestBodyFromEventValid(t *testing.T) {
 }
 
 func TestGetRequestBodyFromEventUnserializable(t *testing.T) {
-	body := getRequestBodyFromEvent(&Event{
+	body, _ := getRequestBodyFromEvent(&Event{
 		Exception: []Exception{{
 			Stacktrace: &Stacktrace{
 				Frames: []Frame{{
@@ -87,7 +93,7 @@ func TestEnvelopeFromErrorBody(t *testing.T) {
 
 	body := json.RawMessage(`{"type":"event","fields":"omitted"}`)
 
-	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body)
+	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body, report.NoopRecorder())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -107,7 +113,7 @@ func TestEnvelopeFromTransactionBody(t *testing.T) {
 
 	body := json.RawMessage(`{"type":"transaction","fields":"omitted"}`)
 
-	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body)
+	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body, report.NoopRecorder())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -139,7 +145,7 @@ func TestEnvelopeFromEventWithAttachments(t *testing.T) {
 
 	body := json.RawMessage(`{"type":"event","fields":"omitted"}`)
 
-	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body)
+	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body, report.NoopRecorder())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -172,8 +178,8 @@ func TestEnvelopeFromCheckInEvent(t *testing.T) {
 	}
 	sentAt := time.Unix(0, 0).UTC()
 
-	body := getRequestBodyFromEvent(event)
-	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body)
+	body, _ := getRequestBodyFromEvent(event)
+	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body, report.NoopRecorder())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -210,8 +216,8 @@ func TestEnvelopeFromLogEvent(t *testing.T) {
 	}
 	sentAt := time.Unix(0, 0).UTC()
 
-	body := getRequestBodyFromEvent(event)
-	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body)
+	body, _ := getRequestBodyFromEvent(event)
+	b, err := envelopeFromBody(event, newTestDSN(t), sentAt, body, report.NoopRecorder())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -253,15 +259,15 @@ func TestGetRequestFromEvent(t *testing.T) {
 		}
 
 		t.Run(test.testName, func(t *testing.T) {
-			body := getRequestBodyFromEvent(test.event)
+			body, _ := getRequestBodyFromEvent(test.event)
 			if body == nil {
 				t.Fatal("failed to marshal event")
 			}
-			envelope, err := envelopeFromBody(test.event, dsn, time.Now(), body)
+			envelope, err := envelopeFromBody(test.event, dsn, time.Now(), body, report.NoopRecorder())
 			if err != nil {
 				t.Fatal(err)
 			}
-			req, err := getRequestFromEnvelope(context.TODO(), dsn, envelope, test.event.Sdk.Name, test.event.Sdk.Version)
+			req, err := getRequestFromEnvelope(context.TODO(), dsn, envelope, test.event.Sdk.Name, test.event.Sdk.Version, httpinternal.Compression{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -424,6 +430,323 @@ func TestHTTPTransport(t *testing.T) {
 		wg.Wait()
 	})
 }
+func TestHTTPTransport_OfflineCache(t *testing.T) {
+	var online atomic.Bool
+	var delivered []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		header, _, _ := bytes.Cut(body, []byte("\n"))
+		var envelope struct {
+			EventID string `json:"event_id"`
+		}
+		_ = json.Unmarshal(header, &envelope)
+		mu.Lock()
+		delivered = append(delivered, envelope.EventID)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport()
+	transport.Configure(ClientOptions{
+		Dsn:          fmt.Sprintf("http://test@%s/1", server.Listener.Addr()),
+		OfflineCache: OfflineCacheOptions{Dir: t.TempDir()},
+	})
+	defer transport.Close()
+
+	sendEvent := func(id string) {
+		e := NewEvent()
+		e.EventID = EventID(id)
+		transport.SendEvent(e)
+		if !transport.Flush(testutils.FlushTimeout()) {
+			t.Fatal("Flush timed out")
+		}
+	}
+
+	sendEvent("offline")
+	if got := transport.cache.Len(); got != 1 {
+		t.Fatalf("expected 1 cached envelope, got %d", got)
+	}
+
+	online.Store(true)
+	sendEvent("online")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff([]string{"online", "offline"}, delivered); diff != "" {
+		t.Errorf("delivered envelopes mismatch (-want +got):\n%s", diff)
+	}
+	if got := transport.cache.Len(); got != 0 {
+		t.Errorf("expected empty cache, got %d envelopes", got)
+	}
+}
+
+func TestHTTPTransport_OfflineCacheRateLimited(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	aggregator := report.NewAggregator()
+	transport := NewHTTPTransport()
+	transport.recorder = aggregator
+	transport.Configure(ClientOptions{
+		Dsn:          fmt.Sprintf("http://test@%s/1", server.Listener.Addr()),
+		OfflineCache: OfflineCacheOptions{Dir: t.TempDir()},
+	})
+	defer transport.Close()
+
+	envelope := protocol.NewEnvelope(&protocol.EnvelopeHeader{EventID: "cached"})
+	envelope.AddItem(protocol.NewEnvelopeItem(protocol.EnvelopeItemTypeEvent, []byte(`{}`)))
+	transport.mu.Lock()
+	transport.limits[ratelimit.CategoryError] = ratelimit.Deadline(time.Now().Add(time.Minute))
+	transport.mu.Unlock()
+
+	if transport.replayEnvelope(envelope) {
+		t.Error("expected the envelope to be kept while rate limited")
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("expected no requests, got %d", got)
+	}
+	if r := aggregator.TakeReport(); r != nil {
+		t.Errorf("expected no discarded envelopes, got %v", r.DiscardedEvents)
+	}
+}
+
+func TestHTTPTransport_Compression(t *testing.T) {
+	type request struct {
+		encoding string
+		eventID  string
+	}
+
+	newServer := func(t *testing.T) (*httptest.Server, func() []request) {
+		var mu sync.Mutex
+		var requests []request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body io.Reader = r.Body
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				gz, err := gzip.NewReader(r.Body)
+				if err != nil {
+					t.Errorf("invalid gzip body: %v", err)
+					return
+				}
+				defer gz.Close()
+				body = gz
+			}
+			data, err := io.ReadAll(body)
+			if err != nil {
+				t.Errorf("failed to read body: %v", err)
+				return
+			}
+			header, _, _ := bytes.Cut(data, []byte("\n"))
+			var envelope struct {
+				EventID string `json:"event_id"`
+			}
+			if err := json.Unmarshal(header, &envelope); err != nil {
+				t.Errorf("failed to decode envelope header: %v", err)
+			}
+			mu.Lock()
+			requests = append(requests, request{r.Header.Get("Content-Encoding"), envelope.EventID})
+			mu.Unlock()
+		}))
+		t.Cleanup(server.Close)
+		return server, func() []request {
+			mu.Lock()
+			defer mu.Unlock()
+			return requests
+		}
+	}
+
+	tests := []struct {
+		name      string
+		threshold int
+		want      string
+	}{
+		{"compressed", 0, "gzip"},
+		{"below threshold", 1 << 20, ""},
+	}
+	transports := map[string]func() Transport{
+		"HTTPTransport":     func() Transport { return NewHTTPTransport() },
+		"HTTPSyncTransport": func() Transport { return NewHTTPSyncTransport() },
+	}
+
+	for transportName, newTransport := range transports {
+		for _, tt := range tests {
+			t.Run(transportName+"/"+tt.name, func(t *testing.T) {
+				server, requests := newServer(t)
+				transport := newTransport()
+				transport.Configure(ClientOptions{
+					Dsn: fmt.Sprintf("http://test@%s/1", server.Listener.Addr()),
+					Compression: CompressionOptions{
+						Algorithm: CompressionGzip,
+						Threshold: tt.threshold,
+					},
+				})
+				defer transport.Close()
+
+				e := NewEvent()
+				e.EventID = "compressed"
+				e.Message = strings.Repeat("compressible ", 100)
+				transport.SendEvent(e)
+				if !transport.Flush(testutils.FlushTimeout()) {
+					t.Fatal("Flush timed out")
+				}
+
+				want := []request{{tt.want, "compressed"}}
+				if diff := cmp.Diff(want, requests(), cmp.AllowUnexported(request{})); diff != "" {
+					t.Errorf("requests mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestCompressionOptions_Internal(t *testing.T) {
+	tests := []struct {
+		name    string
+		options CompressionOptions
+		want    httpinternal.Compression
+	}{
+		{"none", CompressionOptions{}, httpinternal.Compression{}},
+		{"gzip", CompressionOptions{Algorithm: CompressionGzip, Threshold: 10, Level: 9}, httpinternal.Compression{Encoding: "gzip", Threshold: 10, Level: 9}},
+		{"invalid gzip level", CompressionOptions{Algorithm: CompressionGzip, Level: 42}, httpinternal.Compression{Encoding: "gzip"}},
+		{"negative threshold", CompressionOptions{Algorithm: CompressionGzip, Threshold: -1}, httpinternal.Compression{Encoding: "gzip"}},
+		{"zstd without encoder", CompressionOptions{Algorithm: CompressionZstd, Threshold: 10}, httpinternal.Compression{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.options.internal(), cmpopts.IgnoreFields(httpinternal.Compression{}, "NewWriter")); diff != "" {
+				t.Errorf("internal() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTTPTransport_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []int
+		wantRequests int64
+		wantReason   report.DiscardReason
+	}{
+		{"succeeds after transient errors", []int{503, 408, 200}, 3, ""},
+		{"gives up after retry budget", []int{500, 502, 503, 200}, 3, report.ReasonRetriesExhausted},
+		{"does not retry client errors", []int{400, 200}, 1, report.ReasonSendError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				n := atomic.AddInt64(&requests, 1)
+				w.WriteHeader(tt.responses[n-1])
+			}))
+			defer server.Close()
+
+			aggregator := report.NewAggregator()
+			transport := NewHTTPTransport()
+			transport.recorder = aggregator
+			transport.Configure(ClientOptions{
+				Dsn: fmt.Sprintf("http://test@%s/1", server.Listener.Addr()),
+				Retry: RetryOptions{
+					MaxRetries:     2,
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     10 * time.Millisecond,
+				},
+			})
+			defer transport.Close()
+
+			transport.SendEvent(NewEvent())
+			if !transport.Flush(testutils.FlushTimeout()) {
+				t.Fatal("Flush timed out")
+			}
+
+			if got := atomic.LoadInt64(&requests); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			var want []report.DiscardedEvent
+			if tt.wantReason != "" {
+				want = []report.DiscardedEvent{{Reason: tt.wantReason, Category: ratelimit.CategoryError, Quantity: 1}}
+			}
+			var got []report.DiscardedEvent
+			if r := aggregator.TakeReport(); r != nil {
+				got = r.DiscardedEvents
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("discarded events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTTPTransport_RetryDoesNotBlock(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	var failed atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte(`"type":"transaction"`)) {
+			failed.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+
+	aggregator := report.NewAggregator()
+	transport := NewHTTPTransport()
+	transport.recorder = aggregator
+	transport.Configure(ClientOptions{
+		Dsn: fmt.Sprintf("http://test@%s/1", server.Listener.Addr()),
+		Retry: RetryOptions{
+			MaxRetries:     2,
+			InitialBackoff: time.Minute,
+			MaxBackoff:     time.Minute,
+		},
+	})
+
+	transaction := NewEvent()
+	transaction.Type = transactionType
+	transport.SendEvent(transaction)
+	transport.SendEvent(NewEvent())
+
+	// The error event is sent while the transaction waits for its retry.
+	select {
+	case <-delivered:
+	case <-time.After(testutils.FlushTimeout()):
+		t.Fatal("event was blocked by the retry of another event")
+	}
+	if got := failed.Load(); got != 1 {
+		t.Errorf("failed requests = %d, want 1", got)
+	}
+
+	// Flushing waits for the retry, closing gives up on it.
+	if transport.Flush(100 * time.Millisecond) {
+		t.Error("Flush() = true, want false while an event waits for a retry")
+	}
+	transport.Close()
+	for i := 0; i < 100; i++ {
+		if r := aggregator.TakeReport(); r != nil {
+			want := []report.DiscardedEvent{
+				{Reason: report.ReasonRetriesExhausted, Category: ratelimit.CategoryTransaction, Quantity: 1},
+				{Reason: report.ReasonRetriesExhausted, Category: ratelimit.CategorySpan, Quantity: 1},
+			}
+			sortDiscarded := cmpopts.SortSlices(func(a, b report.DiscardedEvent) bool { return a.Category < b.Category })
+			if diff := cmp.Diff(want, r.DiscardedEvents, sortDiscarded); diff != "" {
+				t.Errorf("discarded events mismatch (-want +got):\n%s", diff)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected the transaction to be discarded once the transport was closed")
+}
+
 func TestHTTPTransport_CloseMultipleTimes(t *testing.T) {
 	server := newTestHTTPServer(t)
 	defer server.Close()